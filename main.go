@@ -17,8 +17,10 @@ limitations under the License.
 package main
 
 import (
+	"bufio"
 	"compress/gzip"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -26,6 +28,7 @@ import (
 	"net/http"
 	"net/http/pprof"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -33,18 +36,25 @@ import (
 	"github.com/openshift/origin/pkg/util/proc"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"k8s.io/apimachinery/pkg/labels"
 	clientset "k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 
 	kcollectors "k8s.io/kube-state-metrics/pkg/collectors"
+	"k8s.io/kube-state-metrics/pkg/customresource"
 	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/sharding"
 	"k8s.io/kube-state-metrics/pkg/version"
+	"k8s.io/kube-state-metrics/pkg/whiteblacklist"
 )
 
 const (
 	metricsPath = "/metrics"
 	healthzPath = "/healthz"
+	readyzPath  = "/readyz"
+	statusPath  = "/status"
 )
 
 // promLogger implements promhttp.Logger
@@ -95,17 +105,68 @@ func main() {
 		collectorBuilder.WithNamespaces(opts.Namespaces)
 	}
 
+	if opts.NamespaceSelector != "" {
+		if len(opts.Namespaces) != 0 && !opts.Namespaces.IsAllNamespaces() {
+			glog.Fatal("--namespace and --namespace-selector are mutually exclusive")
+		}
+		selector, err := labels.Parse(opts.NamespaceSelector)
+		if err != nil {
+			glog.Fatalf("Failed to parse --namespace-selector: %v", err)
+		}
+		glog.Infof("Using namespaces matching %s", selector)
+		collectorBuilder.WithNamespaceSelector(selector)
+	}
+
+	if len(opts.NamespaceDenylist) != 0 {
+		glog.Infof("Excluding %s namespaces", &opts.NamespaceDenylist)
+		collectorBuilder.WithNamespaceDenylist(opts.NamespaceDenylist)
+	}
+
+	whiteBlackList, err := whiteblacklist.New(opts.MetricWhitelist, opts.MetricBlacklist)
+	if err != nil {
+		glog.Fatal(err)
+	}
+
 	if opts.MetricWhitelist.IsEmpty() && opts.MetricBlacklist.IsEmpty() {
 		glog.Info("No metric whitelist or blacklist set. No filtering of metrics will be done.")
+	} else {
+		glog.Infof("A metric white- or blacklist has been configured, %s.", whiteBlackList.Status())
 	}
-	if !opts.MetricWhitelist.IsEmpty() && !opts.MetricBlacklist.IsEmpty() {
-		glog.Fatal("Whitelist and blacklist are both set. They are mutually exclusive, only one of them can be set.")
+
+	collectorBuilder.WithWhiteBlackList(whiteBlackList)
+
+	if len(opts.LabelsAllowList) != 0 {
+		glog.Infof("Using labels allowlist %s", &opts.LabelsAllowList)
+	}
+	collectorBuilder.WithLabelsAllowList(opts.LabelsAllowList)
+
+	if len(opts.LabelSelectors) != 0 {
+		glog.Infof("Using label selectors %s", &opts.LabelSelectors)
+	}
+	if len(opts.FieldSelectors) != 0 {
+		glog.Infof("Using field selectors %s", &opts.FieldSelectors)
 	}
-	if !opts.MetricWhitelist.IsEmpty() {
-		glog.Infof("A metric whitelist has been configured. Only the following metrics will be exposed: %s.", opts.MetricWhitelist.String())
+	collectorBuilder.WithSelectors(opts.LabelSelectors, opts.FieldSelectors)
+
+	if opts.TotalShards < 1 {
+		glog.Fatal("--total-shards must be at least 1")
+	}
+	if opts.Shard < 0 || int(opts.Shard) >= opts.TotalShards {
+		glog.Fatalf("--shard must be between 0 and %d", opts.TotalShards-1)
 	}
-	if !opts.MetricBlacklist.IsEmpty() {
-		glog.Infof("A metric blacklist has been configured. The following metrics will not be exposed: %s.", opts.MetricBlacklist.String())
+	autoSharding := opts.Pod != "" || opts.PodNamespace != ""
+	if autoSharding {
+		if opts.Pod == "" || opts.PodNamespace == "" {
+			glog.Fatal("--pod and --pod-namespace must be set together")
+		}
+		if opts.Shard != 0 || opts.TotalShards != 1 {
+			glog.Fatal("--shard and --total-shards can not be combined with --pod and --pod-namespace")
+		}
+	} else {
+		if opts.TotalShards > 1 {
+			glog.Infof("Using shard %d of %d total shards", opts.Shard, opts.TotalShards)
+		}
+		collectorBuilder.WithSharding(opts.Shard, opts.TotalShards)
 	}
 
 	proc.StartReaper()
@@ -116,17 +177,53 @@ func main() {
 	}
 	collectorBuilder.WithKubeClient(kubeClient)
 
+	var statefulSetSharding *sharding.StatefulSetSharding
+	var totalShards int
+	if autoSharding {
+		statefulSetSharding, err = sharding.NewStatefulSetSharding(kubeClient, opts.PodNamespace, opts.Pod)
+		if err != nil {
+			glog.Fatalf("Failed to determine shard: %v", err)
+		}
+
+		totalShards, err = statefulSetSharding.TotalShards()
+		if err != nil {
+			glog.Fatalf("Failed to determine total shards: %v", err)
+		}
+
+		glog.Infof("Using shard %d of %d total shards, derived from the StatefulSet", statefulSetSharding.Shard(), totalShards)
+		collectorBuilder.WithSharding(statefulSetSharding.Shard(), totalShards)
+	}
+
+	if opts.CustomResourceConfig != "" {
+		crConfig, err := customresource.LoadConfig(opts.CustomResourceConfig)
+		if err != nil {
+			glog.Fatalf("Failed to load custom resource config: %v", err)
+		}
+
+		crClient, err := createCustomResourceClient(opts.Apiserver, opts.Kubeconfig)
+		if err != nil {
+			glog.Fatalf("Failed to create custom resource client: %v", err)
+		}
+
+		glog.Infof("Exposing metrics of %d custom resources", len(crConfig.Resources))
+		collectorBuilder.WithCustomResources(crConfig, crClient)
+	}
+
 	ksmMetricsRegistry := prometheus.NewRegistry()
 	ksmMetricsRegistry.Register(kcollectors.ResourcesPerScrapeMetric)
 	ksmMetricsRegistry.Register(kcollectors.ScrapeErrorTotalMetric)
+	ksmMetricsRegistry.Register(kcollectors.LabelConflictsMetric)
+	ksmMetricsRegistry.Register(scrapesAbortedTotal)
 	ksmMetricsRegistry.Register(prometheus.NewProcessCollector(os.Getpid(), ""))
 	ksmMetricsRegistry.Register(prometheus.NewGoCollector())
 	go telemetryServer(ksmMetricsRegistry, opts.TelemetryHost, opts.TelemetryPort)
 
 	collectors := collectorBuilder.Build()
 
-	// TODO: Reenable white and blacklisting
-	// metricsServer(metrics.FilteredGatherer(registry, opts.MetricWhitelist, opts.MetricBlacklist), opts.Host, opts.Port)
+	if statefulSetSharding != nil {
+		go statefulSetSharding.Watch(context.TODO(), totalShards, collectorBuilder.Reshard)
+	}
+
 	serveMetrics(collectors, opts.Host, opts.Port)
 }
 
@@ -160,6 +257,17 @@ func createKubeClient(apiserver string, kubeconfig string) (clientset.Interface,
 	return kubeClient, nil
 }
 
+func createCustomResourceClient(apiserver string, kubeconfig string) (rest.Interface, error) {
+	config, err := clientcmd.BuildConfigFromFlags(apiserver, kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	config.UserAgent = version.GetVersion().String()
+
+	return customresource.NewClient(config)
+}
+
 func telemetryServer(registry prometheus.Gatherer, host string, port int) {
 	// Address to listen on for web interface and telemetry
 	listenAddress := net.JoinHostPort(host, strconv.Itoa(port))
@@ -208,6 +316,10 @@ func serveMetrics(collectors []*kcollectors.Collector, host string, port int) {
 		w.WriteHeader(200)
 		w.Write([]byte("ok"))
 	})
+	// Add readyzPath
+	mux.Handle(readyzPath, &readyzHandler{collectors})
+	// Add statusPath
+	mux.Handle(statusPath, &statusHandler{collectors})
 	// Add index
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(`<html>
@@ -217,6 +329,8 @@ func serveMetrics(collectors []*kcollectors.Collector, host string, port int) {
 			 <ul>
              <li><a href='` + metricsPath + `'>metrics</a></li>
              <li><a href='` + healthzPath + `'>healthz</a></li>
+             <li><a href='` + readyzPath + `'>readyz</a></li>
+             <li><a href='` + statusPath + `'>status</a></li>
 			 </ul>
              </body>
              </html>`))
@@ -224,6 +338,69 @@ func serveMetrics(collectors []*kcollectors.Collector, host string, port int) {
 	log.Fatal(http.ListenAndServe(listenAddress, mux))
 }
 
+// readyzHandler reports ready once all collectors completed their initial
+// list, so that no incomplete metrics are scraped right after a start.
+type readyzHandler struct {
+	c []*kcollectors.Collector
+}
+
+func (h *readyzHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	notSynced := []string{}
+	for _, c := range h.c {
+		status := c.Status()
+		if status.Synced {
+			continue
+		}
+
+		// A list failing e.g. because of missing RBAC permissions keeps the
+		// collector from syncing, so make the reason visible.
+		if status.LastError != "" {
+			notSynced = append(notSynced, fmt.Sprintf("%s (last error: %s)", status.Name, status.LastError))
+		} else {
+			notSynced = append(notSynced, status.Name)
+		}
+	}
+	sort.Strings(notSynced)
+
+	if len(notSynced) != 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintf(w, "collectors not synced: %s", strings.Join(notSynced, ","))
+		return
+	}
+
+	w.WriteHeader(200)
+	w.Write([]byte("ok"))
+}
+
+// statusHandler writes the status of all collectors as JSON, sorted by name.
+type statusHandler struct {
+	c []*kcollectors.Collector
+}
+
+func (h *statusHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	statuses := make([]kcollectors.CollectorStatus, len(h.c))
+	for i, c := range h.c {
+		statuses[i] = c.Status()
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(statuses); err != nil {
+		glog.Errorf("Failed to write collector status: %v", err)
+	}
+}
+
+// scrapesAbortedTotal counts scrapes of the metrics endpoint which were
+// aborted before the full response was written.
+var scrapesAbortedTotal = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "ksm_scrapes_aborted_total",
+		Help: "Total number of scrapes aborted due to write errors or the client going away.",
+	},
+)
+
 type metricHandler struct {
 	c []*kcollectors.Collector
 }
@@ -246,18 +423,46 @@ func (m *metricHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Buffer the many small writes of the collectors. Once a write of the
+	// buffer failed, all subsequent writes fail with the same error.
+	bufWriter := bufio.NewWriter(&contextWriter{ctx: r.Context(), w: writer})
+
 	for _, c := range m.c {
-		for _, m := range c.Collect() {
-			_, err := fmt.Fprint(writer, *m)
-			if err != nil {
-				// TODO: Handle panic
-				panic(err)
-			}
+		if err := c.Collect(bufWriter); err != nil {
+			abortScrape(err)
+			return
 		}
 	}
 
+	if err := bufWriter.Flush(); err != nil {
+		abortScrape(err)
+		return
+	}
+
 	// In case we gziped the response, we have to close the writer.
 	if closer, ok := writer.(io.Closer); ok {
-		closer.Close()
+		if err := closer.Close(); err != nil {
+			abortScrape(err)
+		}
 	}
 }
+
+func abortScrape(err error) {
+	scrapesAbortedTotal.Inc()
+	glog.V(2).Infof("Aborted scrape: %v", err)
+}
+
+// contextWriter fails all writes once its context is done, e.g. because the
+// client of a request went away.
+type contextWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (w *contextWriter) Write(p []byte) (int, error) {
+	if err := w.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return w.w.Write(p)
+}
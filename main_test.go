@@ -17,19 +17,31 @@ limitations under the License.
 package main
 
 import (
-	// "fmt"
 	// "io/ioutil"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
 	"k8s.io/kube-state-metrics/pkg/options"
 
 	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 	kcollectors "k8s.io/kube-state-metrics/pkg/collectors"
 )
 
@@ -79,6 +91,249 @@ func BenchmarkKubeStateMetrics(t *testing.B) {
 	// fmt.Println(string(body))
 }
 
+func TestMetricHandlerAbortsOnWriteError(t *testing.T) {
+	handler := newTestMetricHandler(t)
+	req := httptest.NewRequest("GET", "http://localhost:8080/metrics", nil)
+	w := &failingResponseWriter{failAfter: 8192}
+
+	before := counterValue(t, scrapesAbortedTotal)
+	handler.ServeHTTP(w, req)
+
+	if w.writesAfterFailure != 0 {
+		t.Errorf("expected no writes after the client hung up, got %d", w.writesAfterFailure)
+	}
+	if got := counterValue(t, scrapesAbortedTotal) - before; got != 1 {
+		t.Errorf("expected aborted scrapes to increase by 1, got %v", got)
+	}
+}
+
+func TestMetricHandlerAbortsOnContextCancel(t *testing.T) {
+	handler := newTestMetricHandler(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest("GET", "http://localhost:8080/metrics", nil).WithContext(ctx)
+	// Simulate the client going away after the first chunk was received.
+	w := &failingResponseWriter{failAfter: -1, onWrite: cancel}
+
+	before := counterValue(t, scrapesAbortedTotal)
+	handler.ServeHTTP(w, req)
+
+	if w.writes != 1 {
+		t.Errorf("expected a single write before the scrape was aborted, got %d", w.writes)
+	}
+	if got := counterValue(t, scrapesAbortedTotal) - before; got != 1 {
+		t.Errorf("expected aborted scrapes to increase by 1, got %v", got)
+	}
+}
+
+func TestMetricHandlerCompleteScrape(t *testing.T) {
+	handler := newTestMetricHandler(t)
+	req := httptest.NewRequest("GET", "http://localhost:8080/metrics", nil)
+	w := httptest.NewRecorder()
+
+	before := counterValue(t, scrapesAbortedTotal)
+	handler.ServeHTTP(w, req)
+
+	if got := counterValue(t, scrapesAbortedTotal) - before; got != 0 {
+		t.Errorf("expected no aborted scrapes, got %v", got)
+	}
+	if !strings.HasSuffix(w.Body.String(), "\n") {
+		t.Error("expected complete response ending with a new line")
+	}
+}
+
+// TestReadyzHandler ensures that readiness is only reported once all
+// collectors completed their initial list, and that the status of each
+// collector is exposed.
+func TestReadyzHandler(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	if err := pod(kubeClient, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	listPods := make(chan struct{})
+	kubeClient.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		<-listPods
+		return false, nil, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	builder := kcollectors.NewBuilder(ctx, options.NewOptions())
+	builder.WithEnabledCollectors(options.CollectorSet{"pods": struct{}{}, "configmaps": struct{}{}})
+	builder.WithKubeClient(kubeClient)
+	builder.WithNamespaces(options.DefaultNamespaces)
+	collectors := builder.Build()
+
+	readyz := &readyzHandler{collectors}
+	status := &statusHandler{collectors}
+
+	w := httptest.NewRecorder()
+	readyz.ServeHTTP(w, httptest.NewRequest("GET", "http://localhost:8080/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable || !strings.Contains(w.Body.String(), "pods") {
+		t.Errorf("expected not ready while pods are listed, got %d: %s", w.Code, w.Body.String())
+	}
+
+	close(listPods)
+	for i := 0; i < 100; i++ {
+		w = httptest.NewRecorder()
+		readyz.ServeHTTP(w, httptest.NewRequest("GET", "http://localhost:8080/readyz", nil))
+		if w.Code == http.StatusOK {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if w.Code != http.StatusOK || w.Body.String() != "ok" {
+		t.Fatalf("expected ready once all collectors synced, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	status.ServeHTTP(w, httptest.NewRequest("GET", "http://localhost:8080/status", nil))
+
+	var statuses []kcollectors.CollectorStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &statuses); err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 2 || statuses[0].Name != "configmaps" || statuses[1].Name != "pods" {
+		t.Fatalf("expected the status of configmaps and pods, got %+v", statuses)
+	}
+	for _, s := range statuses {
+		if !s.Synced || s.LastEventTime == nil || s.LastError != "" {
+			t.Errorf("expected %s to be synced without errors, got %+v", s.Name, s)
+		}
+	}
+}
+
+// TestReadyzHandlerListError ensures that a collector whose list keeps
+// failing, e.g. because of missing RBAC permissions, keeps kube-state-metrics
+// from becoming ready and that the error is reported.
+func TestReadyzHandlerListError(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	kubeClient.PrependReactor("list", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "", errors.New("access denied"))
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	builder := kcollectors.NewBuilder(ctx, options.NewOptions())
+	builder.WithEnabledCollectors(options.CollectorSet{"pods": struct{}{}, "secrets": struct{}{}})
+	builder.WithKubeClient(kubeClient)
+	builder.WithNamespaces(options.DefaultNamespaces)
+	collectors := builder.Build()
+
+	readyz := &readyzHandler{collectors}
+	status := &statusHandler{collectors}
+
+	var statuses []kcollectors.CollectorStatus
+	for i := 0; i < 100; i++ {
+		w := httptest.NewRecorder()
+		status.ServeHTTP(w, httptest.NewRequest("GET", "http://localhost:8080/status", nil))
+		if err := json.Unmarshal(w.Body.Bytes(), &statuses); err != nil {
+			t.Fatal(err)
+		}
+		if statuses[0].Synced && statuses[1].LastError != "" {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if !statuses[0].Synced {
+		t.Fatalf("expected pods to be synced, got %+v", statuses[0])
+	}
+	if statuses[1].Synced || !strings.Contains(statuses[1].LastError, "forbidden") {
+		t.Fatalf("expected secrets not to be synced due to the forbidden list, got %+v", statuses[1])
+	}
+
+	w := httptest.NewRecorder()
+	readyz.ServeHTTP(w, httptest.NewRequest("GET", "http://localhost:8080/readyz", nil))
+	want := `collectors not synced: secrets (last error: secrets is forbidden: access denied)`
+	if w.Code != http.StatusServiceUnavailable || w.Body.String() != want {
+		t.Errorf("expected not ready with %q, got %d: %s", want, w.Code, w.Body.String())
+	}
+}
+
+// newTestMetricHandler returns a metricHandler exposing a few hundred pods,
+// enough for a response spanning multiple buffered writes.
+func newTestMetricHandler(t *testing.T) *metricHandler {
+	kubeClient := fake.NewSimpleClientset()
+	podCount := 500
+	for i := 0; i < podCount; i++ {
+		if err := pod(kubeClient, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := options.NewOptions()
+
+	builder := kcollectors.NewBuilder(context.TODO(), opts)
+	builder.WithEnabledCollectors(options.CollectorSet{"pods": struct{}{}})
+	builder.WithKubeClient(kubeClient)
+	builder.WithNamespaces(options.DefaultNamespaces)
+
+	handler := &metricHandler{builder.Build()}
+
+	// Wait for informers to sync.
+	lastPod := fmt.Sprintf(`pod="pod%d"`, podCount-1)
+	for i := 0; i < 100; i++ {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "http://localhost:8080/metrics", nil))
+		if strings.Contains(w.Body.String(), lastPod) {
+			return handler
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	t.Fatal("timed out waiting for pods to be synced")
+	return nil
+}
+
+// failingResponseWriter simulates a client hanging up after failAfter bytes
+// were written. A negative failAfter never fails.
+type failingResponseWriter struct {
+	failAfter          int
+	onWrite            func()
+	written            int
+	writes             int
+	writesAfterFailure int
+	failed             bool
+}
+
+func (w *failingResponseWriter) Header() http.Header {
+	return http.Header{}
+}
+
+func (w *failingResponseWriter) WriteHeader(int) {}
+
+func (w *failingResponseWriter) Write(p []byte) (int, error) {
+	if w.failed {
+		w.writesAfterFailure++
+		return 0, errors.New("connection reset by peer")
+	}
+
+	w.writes++
+	if w.onWrite != nil {
+		w.onWrite()
+	}
+
+	if w.failAfter >= 0 && w.written+len(p) > w.failAfter {
+		w.failed = true
+		return 0, errors.New("connection reset by peer")
+	}
+
+	w.written += len(p)
+
+	return len(p), nil
+}
+
+func counterValue(t *testing.T, c prometheus.Counter) float64 {
+	m := &dto.Metric{}
+	if err := c.Write(m); err != nil {
+		t.Fatal(err)
+	}
+
+	return m.GetCounter().GetValue()
+}
+
 func injectFixtures(client *fake.Clientset, multiplier int) error {
 	creators := []func(*fake.Clientset, int) error{
 		configMap,
@@ -105,6 +360,7 @@ func configMap(client *fake.Clientset, index int) error {
 		ObjectMeta: metav1.ObjectMeta{
 			Name:            "configmap" + i,
 			ResourceVersion: "123456",
+			UID:             types.UID("configmap" + i),
 		},
 	}
 	_, err := client.CoreV1().ConfigMaps(metav1.NamespaceDefault).Create(&configMap)
@@ -117,6 +373,7 @@ func pod(client *fake.Clientset, index int) error {
 	pod := v1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "pod" + i,
+			UID:  types.UID("pod" + i),
 		},
 		Status: v1.PodStatus{
 			ContainerStatuses: []v1.ContainerStatus{
@@ -19,21 +19,39 @@ package collectors
 
 import (
 	"strings"
+	"sync"
 
+	admissionregistration "k8s.io/api/admissionregistration/v1beta1"
 	apps "k8s.io/api/apps/v1beta1"
 	autoscaling "k8s.io/api/autoscaling/v2beta1"
 	batchv1 "k8s.io/api/batch/v1"
 	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	certv1beta1 "k8s.io/api/certificates/v1beta1"
+	coordinationv1beta1 "k8s.io/api/coordination/v1beta1"
 	extensions "k8s.io/api/extensions/v1beta1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policy "k8s.io/api/policy/v1beta1"
+	rbac "k8s.io/api/rbac/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	storagev1beta1 "k8s.io/api/storage/v1beta1"
 
 	"github.com/golang/glog"
 	"golang.org/x/net/context"
 	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
 	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
-	"k8s.io/kube-state-metrics/pkg/metrics"
+	"k8s.io/kube-state-metrics/pkg/customresource"
 	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
 	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/whiteblacklist"
 )
 
 // Builder helps to build collectors. It follows the builder pattern
@@ -44,6 +62,21 @@ type Builder struct {
 	opts              *options.Options
 	ctx               context.Context
 	enabledCollectors options.CollectorSet
+	whiteBlackList    whiteBlackLister
+	shard             int32
+	totalShards       int
+	labelsAllowList   map[string][]string
+	labelSelectors    map[string]string
+	fieldSelectors    map[string]string
+
+	reflectorSets      []*reflectorSet
+	reflectorSetsMutex sync.Mutex
+
+	customResources      *customresource.Config
+	customResourceClient rest.Interface
+
+	namespaceSelector labels.Selector
+	namespaceDenylist map[string]struct{}
 }
 
 // NewBuilder returns a new builder.
@@ -51,9 +84,14 @@ func NewBuilder(
 	ctx context.Context,
 	opts *options.Options,
 ) *Builder {
+	// An empty white- and blacklist can not fail and filters nothing.
+	whiteBlackList, _ := whiteblacklist.New(map[string]struct{}{}, map[string]struct{}{})
+
 	return &Builder{
-		opts: opts,
-		ctx:  ctx,
+		opts:           opts,
+		ctx:            ctx,
+		whiteBlackList: whiteBlackList,
+		totalShards:    1,
 	}
 }
 
@@ -67,6 +105,57 @@ func (b *Builder) WithNamespaces(n options.NamespaceList) {
 	b.namespaces = n
 }
 
+// WithNamespaceSelector configures the Builder to collect objects of
+// namespaced kinds only from namespaces whose labels match the given selector,
+// instead of the namespaces set by WithNamespaces.
+func (b *Builder) WithNamespaceSelector(s labels.Selector) {
+	b.namespaceSelector = s
+}
+
+// WithNamespaceDenylist configures the Builder to drop objects of namespaced
+// kinds in the given namespaces, regardless of the namespaces watched.
+func (b *Builder) WithNamespaceDenylist(n options.NamespaceList) {
+	b.namespaceDenylist = map[string]struct{}{}
+	for _, ns := range n {
+		b.namespaceDenylist[ns] = struct{}{}
+	}
+}
+
+// WithWhiteBlackList configures the white or blacklisted metric families to
+// be exposed by the collectors built by the Builder.
+func (b *Builder) WithWhiteBlackList(l whiteBlackLister) {
+	b.whiteBlackList = l
+}
+
+// WithCustomResources configures the custom resources to expose metrics for
+// and the client used to list and watch them.
+func (b *Builder) WithCustomResources(c *customresource.Config, client rest.Interface) {
+	b.customResources = c
+	b.customResourceClient = client
+}
+
+// WithSharding configures the shard of this instance, out of totalShards.
+// The collectors only expose objects whose UID hashes to the shard.
+func (b *Builder) WithSharding(shard int32, totalShards int) {
+	b.shard = shard
+	b.totalShards = totalShards
+}
+
+// WithLabelsAllowList configures, per resource, the Kubernetes label and
+// annotation keys exported by the kube_<resource>_labels and
+// kube_<resource>_annotations metric families. The key "*" allows all keys.
+func (b *Builder) WithLabelsAllowList(l map[string][]string) {
+	b.labelsAllowList = l
+}
+
+// WithSelectors configures, per resource, the label and field selectors the
+// objects are listed and watched with. Objects not matching the selectors of
+// their resource are never fetched from the API server.
+func (b *Builder) WithSelectors(labelSelectors, fieldSelectors map[string]string) {
+	b.labelSelectors = labelSelectors
+	b.fieldSelectors = fieldSelectors
+}
+
 // WithKubeClient sets the kubeClient property of a Builder.
 func (b *Builder) WithKubeClient(c clientset.Interface) {
 	b.kubeClient = c
@@ -81,190 +170,929 @@ func (b *Builder) Build() []*Collector {
 	for c := range b.enabledCollectors {
 		constructor, ok := availableCollectors[c]
 		if ok {
-			collector := constructor(b)
+			collector := b.buildCollector(c, func() *Collector { return constructor(b) })
 			activeCollectorNames = append(activeCollectorNames, c)
 			collectors = append(collectors, collector)
 		}
 		// TODO: What if not ok?
 	}
 
+	if b.customResources != nil {
+		for i := range b.customResources.Resources {
+			r := &b.customResources.Resources[i]
+			activeCollectorNames = append(activeCollectorNames, r.String())
+			collectors = append(collectors, b.buildCollector(r.String(), func() *Collector { return b.buildCustomResourceCollector(r) }))
+		}
+	}
+
 	glog.Infof("Active collectors: %s", strings.Join(activeCollectorNames, ","))
 
+	if b.namespaceSelector != nil {
+		b.watchSelectedNamespaces()
+	}
+
 	return collectors
 }
 
+// buildCollector builds a collector of the given name, keeping track of the
+// reflectors started for it to report its status.
+func (b *Builder) buildCollector(name string, build func() *Collector) *Collector {
+	b.reflectorSetsMutex.Lock()
+	started := len(b.reflectorSets)
+	b.reflectorSetsMutex.Unlock()
+
+	c := build()
+
+	b.reflectorSetsMutex.Lock()
+	defer b.reflectorSetsMutex.Unlock()
+
+	c.name = name
+	c.reflectorSets = append([]*reflectorSet{}, b.reflectorSets[started:]...)
+
+	return c
+}
+
 var availableCollectors = map[string]func(f *Builder) *Collector{
-	"configmaps":               func(b *Builder) *Collector { return b.buildConfigMapCollector() },
-	"cronjobs":                 func(b *Builder) *Collector { return b.buildCronJobCollector() },
-	"daemonsets":               func(b *Builder) *Collector { return b.buildDaemonSetCollector() },
-	"deployments":              func(b *Builder) *Collector { return b.buildDeploymentCollector() },
-	"endpoints":                func(b *Builder) *Collector { return b.buildEndpointsCollector() },
-	"horizontalpodautoscalers": func(b *Builder) *Collector { return b.buildHPACollector() },
-	"jobs":                   func(b *Builder) *Collector { return b.buildJobCollector() },
-	"limitranges":            func(b *Builder) *Collector { return b.buildLimitRangeCollector() },
-	"namespaces":             func(b *Builder) *Collector { return b.buildNamespaceCollector() },
-	"nodes":                  func(b *Builder) *Collector { return b.buildNodeCollector() },
-	"persistentvolumeclaims": func(b *Builder) *Collector { return b.buildPersistentVolumeClaimCollector() },
-	"persistentvolumes":      func(b *Builder) *Collector { return b.buildPersistentVolumeCollector() },
-	"pods":                   func(b *Builder) *Collector { return b.buildPodCollector() },
-	"replicasets":            func(b *Builder) *Collector { return b.buildReplicaSetCollector() },
-	"replicationcontrollers": func(b *Builder) *Collector { return b.buildReplicationControllerCollector() },
-	"resourcequotas":         func(b *Builder) *Collector { return b.buildResourceQuotaCollector() },
-	"secrets":                func(b *Builder) *Collector { return b.buildSecretCollector() },
-	"services":               func(b *Builder) *Collector { return b.buildServiceCollector() },
-	"statefulsets":           func(b *Builder) *Collector { return b.buildStatefulSetCollector() },
+	"certificatesigningrequests":      func(b *Builder) *Collector { return b.buildCSRCollector() },
+	"clusterrolebindings":             func(b *Builder) *Collector { return b.buildClusterRoleBindingCollector() },
+	"clusterroles":                    func(b *Builder) *Collector { return b.buildClusterRoleCollector() },
+	"configmaps":                      func(b *Builder) *Collector { return b.buildConfigMapCollector() },
+	"cronjobs":                        func(b *Builder) *Collector { return b.buildCronJobCollector() },
+	"daemonsets":                      func(b *Builder) *Collector { return b.buildDaemonSetCollector() },
+	"deployments":                     func(b *Builder) *Collector { return b.buildDeploymentCollector() },
+	"endpoints":                       func(b *Builder) *Collector { return b.buildEndpointsCollector() },
+	"events":                          func(b *Builder) *Collector { return b.buildEventCollector() },
+	"horizontalpodautoscalers":        func(b *Builder) *Collector { return b.buildHPACollector() },
+	"ingresses":                       func(b *Builder) *Collector { return b.buildIngressCollector() },
+	"jobs":                            func(b *Builder) *Collector { return b.buildJobCollector() },
+	"leases":                          func(b *Builder) *Collector { return b.buildLeaseCollector() },
+	"limitranges":                     func(b *Builder) *Collector { return b.buildLimitRangeCollector() },
+	"mutatingwebhookconfigurations":   func(b *Builder) *Collector { return b.buildMutatingWebhookConfigurationCollector() },
+	"namespaces":                      func(b *Builder) *Collector { return b.buildNamespaceCollector() },
+	"networkpolicies":                 func(b *Builder) *Collector { return b.buildNetworkPolicyCollector() },
+	"nodes":                           func(b *Builder) *Collector { return b.buildNodeCollector() },
+	"persistentvolumeclaims":          func(b *Builder) *Collector { return b.buildPersistentVolumeClaimCollector() },
+	"persistentvolumes":               func(b *Builder) *Collector { return b.buildPersistentVolumeCollector() },
+	"pods":                            func(b *Builder) *Collector { return b.buildPodCollector() },
+	"poddisruptionbudgets":            func(b *Builder) *Collector { return b.buildPodDisruptionBudgetCollector() },
+	"replicasets":                     func(b *Builder) *Collector { return b.buildReplicaSetCollector() },
+	"replicationcontrollers":          func(b *Builder) *Collector { return b.buildReplicationControllerCollector() },
+	"resourcequotas":                  func(b *Builder) *Collector { return b.buildResourceQuotaCollector() },
+	"rolebindings":                    func(b *Builder) *Collector { return b.buildRoleBindingCollector() },
+	"roles":                           func(b *Builder) *Collector { return b.buildRoleCollector() },
+	"secrets":                         func(b *Builder) *Collector { return b.buildSecretCollector() },
+	"serviceaccounts":                 func(b *Builder) *Collector { return b.buildServiceAccountCollector() },
+	"services":                        func(b *Builder) *Collector { return b.buildServiceCollector() },
+	"statefulsets":                    func(b *Builder) *Collector { return b.buildStatefulSetCollector() },
+	"storageclasses":                  func(b *Builder) *Collector { return b.buildStorageClassCollector() },
+	"validatingwebhookconfigurations": func(b *Builder) *Collector { return b.buildValidatingWebhookConfigurationCollector() },
+	"volumeattachments":               func(b *Builder) *Collector { return b.buildVolumeAttachmentCollector() },
 }
 
 func (b *Builder) buildPodCollector() *Collector {
-	genFunc := func(obj interface{}) []*metrics.Metric {
-		return generatePodMetrics(b.opts.DisablePodNonGenericResourceMetrics, obj)
+	families := podMetricFamilies(b.allowedKeys("pods"))
+	if !b.opts.DisablePodNonGenericResourceMetrics {
+		families = append(families, podNonGenericResourceMetricFamilies...)
 	}
-	store := metricsstore.NewMetricsStore(genFunc)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &v1.Pod{}, store, b.namespaces, createPodListWatch)
+	store := b.newMetricsStore(families)
+	b.reflectorPerNamespace("pods", &v1.Pod{}, store, b.namespaces, createPodListWatch)
+
+	return newCollector(store)
+}
+
+func (b *Builder) buildPodDisruptionBudgetCollector() *Collector {
+	store := b.newMetricsStore(podDisruptionBudgetMetricFamilies)
+	b.reflectorPerNamespace("poddisruptionbudgets", &policy.PodDisruptionBudget{}, store, b.namespaces, createPodDisruptionBudgetListWatch)
 
 	return newCollector(store)
 }
 
 func (b *Builder) buildCronJobCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generateCronJobMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &batchv1beta1.CronJob{}, store, b.namespaces, createCronJobListWatch)
+	store := b.newMetricsStore(cronJobMetricFamilies(b.allowedKeys("cronjobs")))
+	b.reflectorPerNamespace("cronjobs", &batchv1beta1.CronJob{}, store, b.namespaces, createCronJobListWatch)
 
 	return newCollector(store)
 }
 
 func (b *Builder) buildConfigMapCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generateConfigMapMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &v1.ConfigMap{}, store, b.namespaces, createConfigMapListWatch)
+	store := b.newMetricsStore(configMapMetricFamilies)
+	b.reflectorPerNamespace("configmaps", &v1.ConfigMap{}, store, b.namespaces, createConfigMapListWatch)
 
 	return newCollector(store)
 }
 
 func (b *Builder) buildDaemonSetCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generateDaemonSetMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &extensions.DaemonSet{}, store, b.namespaces, createDaemonSetListWatch)
+	store := b.newMetricsStore(daemonSetMetricFamilies(b.allowedKeys("daemonsets")))
+	b.reflectorPerNamespace("daemonsets", &extensions.DaemonSet{}, store, b.namespaces, createDaemonSetListWatch)
 
 	return newCollector(store)
 }
 
 func (b *Builder) buildDeploymentCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generateDeploymentMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &extensions.Deployment{}, store, b.namespaces, createDeploymentListWatch)
+	store := b.newMetricsStore(deploymentMetricFamilies(b.allowedKeys("deployments")))
+	b.reflectorPerNamespace("deployments", &extensions.Deployment{}, store, b.namespaces, createDeploymentListWatch)
 
 	return newCollector(store)
 }
 
 func (b *Builder) buildEndpointsCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generateEndpointsMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &v1.Endpoints{}, store, b.namespaces, createEndpointsListWatch)
+	store := b.newMetricsStore(endpointMetricFamilies(b.allowedKeys("endpoints")))
+	b.reflectorPerNamespace("endpoints", &v1.Endpoints{}, store, b.namespaces, createEndpointsListWatch)
+
+	return newCollector(store)
+}
+
+// buildEventCollector builds the opt-in events collector. Unlike the other
+// collectors, it aggregates the events it observes, see eventStore.
+func (b *Builder) buildEventCollector() *Collector {
+	if !b.whiteBlackList.IsIncluded(descEventTotalName) {
+		return newCollector(b.newMetricsStore(nil))
+	}
+
+	store := newEventStore(b.opts.EventRetention)
+	store.WithSharding(b.shard, b.totalShards)
+	b.reflectorPerNamespace("events", &v1.Event{}, store, b.namespaces, createEventListWatch)
 
 	return newCollector(store)
 }
 
 func (b *Builder) buildHPACollector() *Collector {
-	store := metricsstore.NewMetricsStore(generateHPAMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &autoscaling.HorizontalPodAutoscaler{}, store, b.namespaces, createHPAListWatch)
+	store := b.newMetricsStore(hpaMetricFamilies(b.allowedKeys("horizontalpodautoscalers")))
+	b.reflectorPerNamespace("horizontalpodautoscalers", &autoscaling.HorizontalPodAutoscaler{}, store, b.namespaces, createHPAListWatch)
+
+	return newCollector(store)
+}
+
+func (b *Builder) buildIngressCollector() *Collector {
+	store := b.newMetricsStore(ingressMetricFamilies(b.allowedKeys("ingresses")))
+	b.reflectorPerNamespace("ingresses", &extensions.Ingress{}, store, b.namespaces, createIngressListWatch)
 
 	return newCollector(store)
 }
 
 func (b *Builder) buildJobCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generateJobMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &batchv1.Job{}, store, b.namespaces, createJobListWatch)
+	store := b.newMetricsStore(jobMetricFamilies(b.allowedKeys("jobs")))
+	b.reflectorPerNamespace("jobs", &batchv1.Job{}, store, b.namespaces, createJobListWatch)
+
+	return newCollector(store)
+}
+
+func (b *Builder) buildLeaseCollector() *Collector {
+	store := b.newMetricsStore(leaseMetricFamilies(b.allowedKeys("leases")))
+	b.reflectorPerNamespace("leases", &coordinationv1beta1.Lease{}, store, b.namespaces, createLeaseListWatch)
 
 	return newCollector(store)
 }
 
 func (b *Builder) buildLimitRangeCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generateLimitRangeMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &v1.LimitRange{}, store, b.namespaces, createLimitRangeListWatch)
+	store := b.newMetricsStore(limitRangeMetricFamilies)
+	b.reflectorPerNamespace("limitranges", &v1.LimitRange{}, store, b.namespaces, createLimitRangeListWatch)
 
 	return newCollector(store)
 }
 
 func (b *Builder) buildNamespaceCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generateNamespaceMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &v1.Namespace{}, store, b.namespaces, createNamespaceListWatch)
+	store := b.newMetricsStore(namespaceMetricFamilies(b.allowedKeys("namespaces")))
+
+	if !b.opts.RestrictNamespacesCollector || (b.namespaceSelector == nil && b.namespaces.IsAllNamespaces() && len(b.namespaceDenylist) == 0) {
+		b.clusterScopedReflector("namespaces", &v1.Namespace{}, store, createNamespaceListWatch)
+		return newCollector(store)
+	}
+
+	var keep func(ns *v1.Namespace) bool
+	switch {
+	case b.namespaceSelector != nil:
+		keep = func(ns *v1.Namespace) bool {
+			return b.namespaceSelector.Matches(labels.Set(ns.Labels))
+		}
+	case !b.namespaces.IsAllNamespaces():
+		namespaces := map[string]struct{}{}
+		for _, ns := range b.namespaces {
+			namespaces[ns] = struct{}{}
+		}
+		keep = func(ns *v1.Namespace) bool {
+			_, ok := namespaces[ns.Name]
+			return ok
+		}
+	default:
+		keep = func(ns *v1.Namespace) bool { return true }
+	}
+
+	filtered := newFilteredStore(store, func(obj interface{}) bool {
+		ns := obj.(*v1.Namespace)
+		return !b.namespaceDenied(ns.Name) && keep(ns)
+	})
+	b.clusterScopedReflector("namespaces", &v1.Namespace{}, filtered, createNamespaceListWatch)
 
 	return newCollector(store)
 }
 
 func (b *Builder) buildNodeCollector() *Collector {
-	genFunc := func(obj interface{}) []*metrics.Metric {
-		return generateNodeMetrics(b.opts.DisableNodeNonGenericResourceMetrics, obj)
+	families := nodeMetricFamilies(b.allowedKeys("nodes"))
+	if !b.opts.DisableNodeNonGenericResourceMetrics {
+		families = append(families, nodeNonGenericResourceMetricFamilies...)
 	}
-	store := metricsstore.NewMetricsStore(genFunc)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &v1.Node{}, store, b.namespaces, createNodeListWatch)
+	store := b.newMetricsStore(families)
+	b.clusterScopedReflector("nodes", &v1.Node{}, store, createNodeListWatch)
 
 	return newCollector(store)
 }
 
 func (b *Builder) buildPersistentVolumeCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generatePersistentVolumeMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &v1.PersistentVolume{}, store, b.namespaces, createPersistentVolumeListWatch)
+	store := b.newMetricsStore(persistentVolumeMetricFamilies(b.allowedKeys("persistentvolumes")))
+	b.clusterScopedReflector("persistentvolumes", &v1.PersistentVolume{}, store, createPersistentVolumeListWatch)
 
 	return newCollector(store)
 }
 
 func (b *Builder) buildPersistentVolumeClaimCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generatePersistentVolumeClaimMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &v1.PersistentVolumeClaim{}, store, b.namespaces, createPersistentVolumeClaimListWatch)
+	store := b.newMetricsStore(persistentVolumeClaimMetricFamilies(b.allowedKeys("persistentvolumeclaims")))
+	b.reflectorPerNamespace("persistentvolumeclaims", &v1.PersistentVolumeClaim{}, store, b.namespaces, createPersistentVolumeClaimListWatch)
 
 	return newCollector(store)
 }
 
 func (b *Builder) buildReplicaSetCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generateReplicaSetMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &extensions.ReplicaSet{}, store, b.namespaces, createReplicaSetListWatch)
+	store := b.newMetricsStore(replicaSetMetricFamilies)
+	b.reflectorPerNamespace("replicasets", &extensions.ReplicaSet{}, store, b.namespaces, createReplicaSetListWatch)
 
 	return newCollector(store)
 }
 
 func (b *Builder) buildReplicationControllerCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generateReplicationControllerMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &v1.ReplicationController{}, store, b.namespaces, createReplicationControllerListWatch)
+	store := b.newMetricsStore(replicationControllerMetricFamilies)
+	b.reflectorPerNamespace("replicationcontrollers", &v1.ReplicationController{}, store, b.namespaces, createReplicationControllerListWatch)
 
 	return newCollector(store)
 }
 
 func (b *Builder) buildResourceQuotaCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generateResourceQuotaMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &v1.ResourceQuota{}, store, b.namespaces, createResourceQuotaListWatch)
+	store := b.newMetricsStore(resourceQuotaMetricFamilies)
+	b.reflectorPerNamespace("resourcequotas", &v1.ResourceQuota{}, store, b.namespaces, createResourceQuotaListWatch)
 
 	return newCollector(store)
 }
 
 func (b *Builder) buildSecretCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generateSecretMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &v1.Secret{}, store, b.namespaces, createSecretListWatch)
+	store := b.newMetricsStore(secretMetricFamilies(b.allowedKeys("secrets")))
+	b.reflectorPerNamespace("secrets", &v1.Secret{}, store, b.namespaces, createSecretListWatch)
 
 	return newCollector(store)
 }
 
 func (b *Builder) buildServiceCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generateServiceMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &v1.Service{}, store, b.namespaces, createServiceListWatch)
+	store := b.newMetricsStore(serviceMetricFamilies(b.allowedKeys("services")))
+	b.reflectorPerNamespace("services", &v1.Service{}, store, b.namespaces, createServiceListWatch)
 
 	return newCollector(store)
 }
 
 func (b *Builder) buildStatefulSetCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generateStatefulSetMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &apps.StatefulSet{}, store, b.namespaces, createStatefulSetListWatch)
+	store := b.newMetricsStore(statefulSetMetricFamilies(b.allowedKeys("statefulsets")))
+	b.reflectorPerNamespace("statefulsets", &apps.StatefulSet{}, store, b.namespaces, createStatefulSetListWatch)
 
 	return newCollector(store)
 }
 
-func reflectorPerNamespace(
-	ctx context.Context,
-	kubeClient clientset.Interface,
+func (b *Builder) buildStorageClassCollector() *Collector {
+	store := b.newMetricsStore(storageClassMetricFamilies(b.allowedKeys("storageclasses")))
+	b.clusterScopedReflector("storageclasses", &storagev1.StorageClass{}, store, createStorageClassListWatch)
+
+	return newCollector(store)
+}
+
+func (b *Builder) buildVolumeAttachmentCollector() *Collector {
+	store := b.newMetricsStore(volumeAttachmentMetricFamilies(b.allowedKeys("volumeattachments")))
+	b.clusterScopedReflector("volumeattachments", &storagev1beta1.VolumeAttachment{}, store, createVolumeAttachmentListWatch)
+
+	return newCollector(store)
+}
+
+func (b *Builder) buildCSRCollector() *Collector {
+	store := b.newMetricsStore(csrMetricFamilies(b.allowedKeys("certificatesigningrequests")))
+	b.clusterScopedReflector("certificatesigningrequests", &certv1beta1.CertificateSigningRequest{}, store, createCSRListWatch)
+
+	return newCollector(store)
+}
+
+func (b *Builder) buildNetworkPolicyCollector() *Collector {
+	store := b.newMetricsStore(networkPolicyMetricFamilies(b.allowedKeys("networkpolicies")))
+	b.reflectorPerNamespace("networkpolicies", &networkingv1.NetworkPolicy{}, store, b.namespaces, createNetworkPolicyListWatch)
+
+	return newCollector(store)
+}
+
+func (b *Builder) buildValidatingWebhookConfigurationCollector() *Collector {
+	store := b.newMetricsStore(validatingWebhookConfigurationMetricFamilies(b.allowedKeys("validatingwebhookconfigurations")))
+	b.clusterScopedReflector("validatingwebhookconfigurations", &admissionregistration.ValidatingWebhookConfiguration{}, store, createValidatingWebhookConfigurationListWatch)
+
+	return newCollector(store)
+}
+
+func (b *Builder) buildMutatingWebhookConfigurationCollector() *Collector {
+	store := b.newMetricsStore(mutatingWebhookConfigurationMetricFamilies(b.allowedKeys("mutatingwebhookconfigurations")))
+	b.clusterScopedReflector("mutatingwebhookconfigurations", &admissionregistration.MutatingWebhookConfiguration{}, store, createMutatingWebhookConfigurationListWatch)
+
+	return newCollector(store)
+}
+
+func (b *Builder) buildRoleCollector() *Collector {
+	store := b.newMetricsStore(roleMetricFamilies(b.allowedKeys("roles")))
+	b.reflectorPerNamespace("roles", &rbac.Role{}, store, b.namespaces, createRoleListWatch)
+
+	return newCollector(store)
+}
+
+func (b *Builder) buildClusterRoleCollector() *Collector {
+	store := b.newMetricsStore(clusterRoleMetricFamilies(b.allowedKeys("clusterroles")))
+	b.clusterScopedReflector("clusterroles", &rbac.ClusterRole{}, store, createClusterRoleListWatch)
+
+	return newCollector(store)
+}
+
+func (b *Builder) buildRoleBindingCollector() *Collector {
+	store := b.newMetricsStore(roleBindingMetricFamilies(b.allowedKeys("rolebindings")))
+	b.reflectorPerNamespace("rolebindings", &rbac.RoleBinding{}, store, b.namespaces, createRoleBindingListWatch)
+
+	return newCollector(store)
+}
+
+func (b *Builder) buildClusterRoleBindingCollector() *Collector {
+	store := b.newMetricsStore(clusterRoleBindingMetricFamilies(b.allowedKeys("clusterrolebindings")))
+	b.clusterScopedReflector("clusterrolebindings", &rbac.ClusterRoleBinding{}, store, createClusterRoleBindingListWatch)
+
+	return newCollector(store)
+}
+
+func (b *Builder) buildServiceAccountCollector() *Collector {
+	store := b.newMetricsStore(serviceAccountMetricFamilies(b.allowedKeys("serviceaccounts")))
+	b.reflectorPerNamespace("serviceaccounts", &v1.ServiceAccount{}, store, b.namespaces, createServiceAccountListWatch)
+
+	return newCollector(store)
+}
+
+func (b *Builder) buildCustomResourceCollector(r *customresource.Resource) *Collector {
+	store := b.newMetricsStore(customResourceMetricFamilies(r))
+
+	listWatchFunc := func(kubeClient clientset.Interface, ns string) cache.ListWatch {
+		return createCustomResourceListWatch(b.customResourceClient, r, ns)
+	}
+	if r.Namespaced {
+		b.reflectorPerNamespace(r.String(), &unstructured.Unstructured{}, store, b.namespaces, listWatchFunc)
+	} else {
+		b.clusterScopedReflector(r.String(), &unstructured.Unstructured{}, store, listWatchFunc)
+	}
+
+	return newCollector(store)
+}
+
+// allowedKeys returns the Kubernetes label and annotation keys exported for
+// the given resource. Resources missing in the allowlist export all labels
+// and, except for namespaces, no annotations.
+func (b *Builder) allowedKeys(resource string) (labels, annotations keyAllowList) {
+	if keys, ok := b.labelsAllowList[resource]; ok {
+		l := newKeyAllowList(keys)
+		return l, l
+	}
+
+	if resource == "namespaces" {
+		return allKeys, allKeys
+	}
+
+	return allKeys, keyAllowList{}
+}
+
+// newMetricsStore returns a store generating the given metric families,
+// omitting the families excluded by the white- or blacklist. The store only
+// keeps the objects of the shard of this instance.
+func (b *Builder) newMetricsStore(families []metricFamilyDef) *metricsstore.MetricsStore {
+	filteredMetricFamilies := filterMetricFamilies(b.whiteBlackList, families)
+
+	store := metricsstore.NewMetricsStore(
+		extractMetricFamilyHeaders(filteredMetricFamilies),
+		composeMetricGenFuncs(filteredMetricFamilies),
+	)
+	store.WithSharding(b.shard, b.totalShards)
+
+	return store
+}
+
+// reflectorPerNamespace starts a reflector filling the given store for each
+// of the given namespaces. If a namespace selector is configured, the given
+// namespaces are ignored and the reflectors follow the namespaces matching the
+// selector instead. Objects in denylisted namespaces are never added to the
+// store. The reflectors are restarted on Reshard.
+func (b *Builder) reflectorPerNamespace(
+	resource string,
+	expectedType interface{},
+	store shardedStore,
+	namespaces []string,
+	listWatchFunc func(kubeClient clientset.Interface, ns string) cache.ListWatch,
+) {
+	selected := b.namespaceSelector != nil
+	if selected {
+		namespaces = nil
+	}
+
+	if len(b.namespaceDenylist) != 0 {
+		allowed := []string{}
+		for _, ns := range namespaces {
+			if !b.namespaceDenied(ns) {
+				allowed = append(allowed, ns)
+			}
+		}
+		namespaces = allowed
+
+		store = newFilteredStore(store, b.notInDeniedNamespace)
+	}
+
+	b.startReflectorSet(resource, expectedType, store, namespaces, listWatchFunc, selected)
+}
+
+// namespaceDenied returns whether the given namespace is in the namespace
+// denylist.
+func (b *Builder) namespaceDenied(ns string) bool {
+	_, ok := b.namespaceDenylist[ns]
+	return ok
+}
+
+// notInDeniedNamespace returns whether the given object is not in a
+// denylisted namespace.
+func (b *Builder) notInDeniedNamespace(obj interface{}) bool {
+	o, err := meta.Accessor(obj)
+	return err != nil || !b.namespaceDenied(o.GetNamespace())
+}
+
+// clusterScopedReflector starts a single reflector filling the given store
+// with objects of a cluster-scoped kind, independent of the namespaces
+// configured for the Builder.
+func (b *Builder) clusterScopedReflector(
+	resource string,
+	expectedType interface{},
+	store shardedStore,
+	listWatchFunc func(kubeClient clientset.Interface, ns string) cache.ListWatch,
+) {
+	b.startReflectorSet(resource, expectedType, store, options.DefaultNamespaces, listWatchFunc, false)
+}
+
+// startReflectorSet starts the reflectors of the given resource, listing and
+// watching only the objects matching the label and field selectors configured
+// for it.
+func (b *Builder) startReflectorSet(
+	resource string,
 	expectedType interface{},
-	store cache.Store,
+	store shardedStore,
 	namespaces []string,
 	listWatchFunc func(kubeClient clientset.Interface, ns string) cache.ListWatch,
+	selected bool,
 ) {
+	labelSelector, fieldSelector := b.labelSelectors[resource], b.fieldSelectors[resource]
+	r := newReflectorSet(expectedType, store, namespaces, func(ns string) cache.ListWatch {
+		return withSelectors(listWatchFunc(b.kubeClient, ns), labelSelector, fieldSelector)
+	})
+	r.selected = selected
+	r.run(b.ctx)
+
+	b.reflectorSetsMutex.Lock()
+	defer b.reflectorSetsMutex.Unlock()
+	b.reflectorSets = append(b.reflectorSets, r)
+}
+
+// withSelectors returns the given ListWatch, restricted to the objects
+// matching the given label and field selectors. Empty selectors match all
+// objects.
+func withSelectors(lw cache.ListWatch, labelSelector, fieldSelector string) cache.ListWatch {
+	if labelSelector == "" && fieldSelector == "" {
+		return lw
+	}
+
+	listFunc, watchFunc := lw.ListFunc, lw.WatchFunc
+	lw.ListFunc = func(opts metav1.ListOptions) (runtime.Object, error) {
+		opts.LabelSelector, opts.FieldSelector = labelSelector, fieldSelector
+		return listFunc(opts)
+	}
+	lw.WatchFunc = func(opts metav1.ListOptions) (watch.Interface, error) {
+		opts.LabelSelector, opts.FieldSelector = labelSelector, fieldSelector
+		return watchFunc(opts)
+	}
+	return lw
+}
+
+// watchSelectedNamespaces starts and stops the reflectors of namespaced kinds
+// as namespaces start or stop matching the namespace selector, or are created
+// and deleted.
+func (b *Builder) watchSelectedNamespaces() {
+	lw := cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			opts.LabelSelector = b.namespaceSelector.String()
+			return b.kubeClient.CoreV1().Namespaces().List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			opts.LabelSelector = b.namespaceSelector.String()
+			return b.kubeClient.CoreV1().Namespaces().Watch(opts)
+		},
+	}
+
+	// The selector is checked again on every event, as the watch does not
+	// necessarily report namespaces which stopped matching as deleted.
+	_, controller := cache.NewInformer(&lw, &v1.Namespace{}, 0, cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			ns := obj.(*v1.Namespace)
+			b.selectNamespace(ns.Name, b.namespaceSelector.Matches(labels.Set(ns.Labels)))
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			ns := obj.(*v1.Namespace)
+			b.selectNamespace(ns.Name, b.namespaceSelector.Matches(labels.Set(ns.Labels)))
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			b.selectNamespace(obj.(*v1.Namespace).Name, false)
+		},
+	})
+
+	b.reflectorSetsMutex.Lock()
+	for _, r := range b.reflectorSets {
+		if r.selected {
+			r.waitForNamespaces(controller.HasSynced)
+		}
+	}
+	b.reflectorSetsMutex.Unlock()
+
+	go controller.Run(b.ctx.Done())
+}
+
+// selectNamespace starts or stops the reflectors of the given namespace for
+// all collectors of namespaced kinds. The metrics of the objects of stopped
+// reflectors are removed.
+func (b *Builder) selectNamespace(ns string, selected bool) {
+	b.reflectorSetsMutex.Lock()
+	defer b.reflectorSetsMutex.Unlock()
+
+	for _, r := range b.reflectorSets {
+		if !r.selected {
+			continue
+		}
+		if selected {
+			r.addNamespace(ns)
+		} else {
+			r.removeNamespace(ns)
+		}
+	}
+}
+
+// Reshard changes the shard of all collectors built by the Builder. Metrics of
+// objects no longer in the shard are removed right away. Objects which newly
+// belong to the shard are picked up by restarting the reflectors of the
+// collectors, which relist all objects.
+func (b *Builder) Reshard(shard int32, totalShards int) {
+	b.reflectorSetsMutex.Lock()
+	defer b.reflectorSetsMutex.Unlock()
+
+	b.shard = shard
+	b.totalShards = totalShards
+
+	for _, r := range b.reflectorSets {
+		r.stop()
+		r.store.WithSharding(shard, totalShards)
+		r.run(b.ctx)
+	}
+}
+
+// shardedStore is a cache.Store only keeping the objects of a shard.
+type shardedStore interface {
+	cache.Store
+	WithSharding(shard int32, totalShards int)
+}
+
+// reflectorSet is the set of reflectors filling a single store, one per
+// namespace. Each reflector fills its own share of the store, see
+// reflectorStore.
+type reflectorSet struct {
+	store         shardedStore
+	expectedType  interface{}
+	listWatchFunc func(ns string) cache.ListWatch
+	// selected is true if the namespaces of the set follow the namespace
+	// selector of the Builder.
+	selected bool
+	status   *reflectorStatus
+
+	mutex      sync.Mutex
+	ctx        context.Context
+	cancel     context.CancelFunc
+	reflectors map[string]*namespaceReflector
+	// namespacesSynced returns whether the initial namespaces of a selected
+	// set were added.
+	namespacesSynced func() bool
+}
+
+// namespaceReflector is the reflector of a single namespace of a
+// reflectorSet. cancel is nil while the reflector is not running.
+type namespaceReflector struct {
+	store  *reflectorStore
+	cancel context.CancelFunc
+}
+
+func newReflectorSet(expectedType interface{}, store shardedStore, namespaces []string, listWatchFunc func(ns string) cache.ListWatch) *reflectorSet {
+	r := &reflectorSet{
+		store:         store,
+		expectedType:  expectedType,
+		listWatchFunc: listWatchFunc,
+		status:        newReflectorStatus(),
+		reflectors:    map[string]*namespaceReflector{},
+	}
 	for _, ns := range namespaces {
-		lw := listWatchFunc(kubeClient, ns)
-		reflector := cache.NewReflector(&lw, expectedType, store, 0)
-		go reflector.Run(ctx.Done())
+		r.reflectors[ns] = &namespaceReflector{store: newReflectorStore(store, r.status)}
+	}
+
+	return r
+}
+
+// run starts the reflectors, which stop once the given context or stop is
+// called.
+func (r *reflectorSet) run(ctx context.Context) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.ctx, r.cancel = context.WithCancel(ctx)
+	for ns, nr := range r.reflectors {
+		r.startReflector(ns, nr)
+	}
+}
+
+func (r *reflectorSet) stop() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.cancel()
+	r.ctx = nil
+	for _, nr := range r.reflectors {
+		nr.cancel = nil
+	}
+}
+
+// startReflector starts the reflector of the given namespace. The caller has
+// to hold the mutex.
+func (r *reflectorSet) startReflector(ns string, nr *namespaceReflector) {
+	var ctx context.Context
+	ctx, nr.cancel = context.WithCancel(r.ctx)
+
+	lw := r.listWatchFunc(ns)
+	listFunc, watchFunc := lw.ListFunc, lw.WatchFunc
+	lw.ListFunc = func(opts metav1.ListOptions) (runtime.Object, error) {
+		obj, err := listFunc(opts)
+		return obj, r.status.observeError(err)
+	}
+	lw.WatchFunc = func(opts metav1.ListOptions) (watch.Interface, error) {
+		w, err := watchFunc(opts)
+		return w, r.status.observeError(err)
+	}
+
+	reflector := cache.NewReflector(&lw, r.expectedType, nr.store, 0)
+	go reflector.Run(ctx.Done())
+}
+
+// waitForNamespaces makes the set report itself as not synced until the given
+// function returns true, see synced.
+func (r *reflectorSet) waitForNamespaces(namespacesSynced func() bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.namespacesSynced = namespacesSynced
+}
+
+// synced returns whether each reflector of the set completed its initial list.
+// A selected set is not synced before its initial namespaces were added.
+func (r *reflectorSet) synced() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.selected && (r.namespacesSynced == nil || !r.namespacesSynced()) {
+		return false
+	}
+	for _, nr := range r.reflectors {
+		if !nr.store.hasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+// addNamespace adds a reflector for the given namespace, started right away if
+// the set is running.
+func (r *reflectorSet) addNamespace(ns string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.reflectors[ns]; ok {
+		return
+	}
+
+	nr := &namespaceReflector{store: newReflectorStore(r.store, r.status)}
+	r.reflectors[ns] = nr
+	if r.ctx != nil {
+		r.startReflector(ns, nr)
+	}
+}
+
+// removeNamespace stops the reflector of the given namespace and removes the
+// metrics of its objects from the store.
+func (r *reflectorSet) removeNamespace(ns string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	nr, ok := r.reflectors[ns]
+	if !ok {
+		return
+	}
+
+	delete(r.reflectors, ns)
+	if nr.cancel != nil {
+		nr.cancel()
+	}
+	nr.store.purge()
+}
+
+// reflectorStore is the share of a store filled by a single reflector. It
+// tracks the objects added by the reflector, so that a relist only replaces
+// these objects instead of all objects of the store, and so that they can be
+// removed once the reflector is stopped.
+type reflectorStore struct {
+	shardedStore
+
+	mutex sync.Mutex
+	uids  map[types.UID]struct{}
+	// purged is set once the objects were removed, after which late events of
+	// the stopped reflector are ignored.
+	purged bool
+	// synced is set once the reflector completed its initial list.
+	synced bool
+	status *reflectorStatus
+}
+
+func newReflectorStore(store shardedStore, status *reflectorStatus) *reflectorStore {
+	return &reflectorStore{
+		shardedStore: store,
+		uids:         map[types.UID]struct{}{},
+		status:       status,
+	}
+}
+
+func (s *reflectorStore) hasSynced() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.synced
+}
+
+func (s *reflectorStore) Add(obj interface{}) error {
+	o, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.purged {
+		return nil
+	}
+
+	s.uids[o.GetUID()] = struct{}{}
+	return s.status.observeEvent(s.shardedStore.Add(obj))
+}
+
+func (s *reflectorStore) Update(obj interface{}) error {
+	o, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.purged {
+		return nil
+	}
+
+	s.uids[o.GetUID()] = struct{}{}
+	return s.status.observeEvent(s.shardedStore.Update(obj))
+}
+
+func (s *reflectorStore) Delete(obj interface{}) error {
+	deleted := obj
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		deleted = tombstone.Obj
+	}
+
+	o, err := meta.Accessor(deleted)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.purged {
+		return nil
+	}
+
+	delete(s.uids, o.GetUID())
+	return s.status.observeEvent(s.shardedStore.Delete(obj))
+}
+
+// Replace removes the objects previously added by the reflector which are not
+// in the given list, and adds the given objects.
+func (s *reflectorStore) Replace(list []interface{}, _ string) error {
+	uids := make(map[types.UID]struct{}, len(list))
+	for _, obj := range list {
+		o, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+		uids[o.GetUID()] = struct{}{}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.purged {
+		return nil
+	}
+
+	s.synced = true
+	return s.status.observeEvent(s.replace(list, uids))
+}
+
+// replace replaces the objects of the reflector by the given list of objects
+// with the given UIDs. The caller has to hold the mutex.
+func (s *reflectorStore) replace(list []interface{}, uids map[types.UID]struct{}) error {
+	for uid := range s.uids {
+		if _, ok := uids[uid]; !ok {
+			if err := s.shardedStore.Delete(&metav1.ObjectMeta{UID: uid}); err != nil {
+				return err
+			}
+		}
+	}
+	s.uids = uids
+
+	for _, obj := range list {
+		if err := s.shardedStore.Add(obj); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// purge removes all objects added by the reflector from the store.
+func (s *reflectorStore) purge() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for uid := range s.uids {
+		// Deleting by UID can not fail.
+		s.shardedStore.Delete(&metav1.ObjectMeta{UID: uid})
+	}
+	s.uids = nil
+	s.purged = true
+}
+
+// filteredStore is a shardedStore only keeping the objects for which keep
+// returns true. Objects which no longer match on update are removed.
+type filteredStore struct {
+	shardedStore
+	keep func(obj interface{}) bool
+}
+
+func newFilteredStore(store shardedStore, keep func(obj interface{}) bool) *filteredStore {
+	return &filteredStore{
+		shardedStore: store,
+		keep:         keep,
+	}
+}
+
+func (s *filteredStore) Add(obj interface{}) error {
+	if !s.keep(obj) {
+		return s.shardedStore.Delete(obj)
+	}
+	return s.shardedStore.Add(obj)
+}
+
+func (s *filteredStore) Update(obj interface{}) error {
+	if !s.keep(obj) {
+		return s.shardedStore.Delete(obj)
+	}
+	return s.shardedStore.Update(obj)
+}
+
+func (s *filteredStore) Replace(list []interface{}, resourceVersion string) error {
+	kept := make([]interface{}, 0, len(list))
+	for _, obj := range list {
+		if s.keep(obj) {
+			kept = append(kept, obj)
+		}
 	}
+	return s.shardedStore.Replace(kept, resourceVersion)
 }
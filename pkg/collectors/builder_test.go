@@ -0,0 +1,463 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/sharding"
+)
+
+// TestStatefulSetResharding ensures that scaling the StatefulSet of
+// kube-state-metrics from 2 to 3 replicas reshards the exposed pods.
+func TestStatefulSetResharding(t *testing.T) {
+	replicas := int32(2)
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "kube-state-metrics", Namespace: "monitoring"},
+		Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+	}
+	kubeClient := fake.NewSimpleClientset(statefulSet)
+
+	pods := []*v1.Pod{}
+	for i := 0; i < 30; i++ {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("pod%d", i),
+				Namespace: "default",
+				UID:       types.UID(fmt.Sprintf("uid-%d", i)),
+			},
+		}
+		if _, err := kubeClient.CoreV1().Pods(pod.Namespace).Create(pod); err != nil {
+			t.Fatal(err)
+		}
+		pods = append(pods, pod)
+	}
+
+	s, err := sharding.NewStatefulSetSharding(kubeClient, "monitoring", "kube-state-metrics-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	totalShards, err := s.TotalShards()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := NewBuilder(ctx, options.NewOptions())
+	b.WithEnabledCollectors(options.CollectorSet{"pods": struct{}{}})
+	b.WithNamespaces(options.DefaultNamespaces)
+	b.WithKubeClient(kubeClient)
+	b.WithSharding(s.Shard(), totalShards)
+	collectors := b.Build()
+
+	waitForOutput(t, collectors[0], expectedPodShard(t, pods, 1, 2))
+
+	go s.Watch(ctx, totalShards, b.Reshard)
+
+	replicas = 3
+	if _, err := kubeClient.AppsV1().StatefulSets("monitoring").Update(statefulSet); err != nil {
+		t.Fatal(err)
+	}
+
+	waitForOutput(t, collectors[0], expectedPodShard(t, pods, 1, 3))
+}
+
+// expectedPodShard returns the output of the pod collector for the given pods
+// of the given shard.
+func expectedPodShard(t *testing.T, pods []*v1.Pod, shard int32, totalShards int) string {
+	s := metricsstore.NewMetricsStore(extractMetricFamilyHeaders(podMetricFamilies(allKeys, allKeys)), composeMetricGenFuncs(podMetricFamilies(allKeys, allKeys)))
+	s.WithSharding(shard, totalShards)
+	for _, p := range pods {
+		if err := s.Add(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return strings.Join(seriesLines(s), "\n")
+}
+
+func waitForOutput(t *testing.T, c *Collector, want string) {
+	var got string
+	for i := 0; i < 100; i++ {
+		got = strings.Join(seriesLines(c.store), "\n")
+		if got == want {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	t.Fatalf("timed out waiting for output\nwant: %s\ngot:  %s", want, got)
+}
+
+// clusterScopedCollectors are the collectors of cluster-scoped kinds.
+var clusterScopedCollectors = map[string]struct{}{
+	"certificatesigningrequests":      {},
+	"clusterrolebindings":             {},
+	"clusterroles":                    {},
+	"mutatingwebhookconfigurations":   {},
+	"namespaces":                      {},
+	"nodes":                           {},
+	"persistentvolumes":               {},
+	"storageclasses":                  {},
+	"validatingwebhookconfigurations": {},
+	"volumeattachments":               {},
+}
+
+// TestReflectorNamespaces ensures that collectors of namespaced kinds watch
+// each configured namespace, while collectors of cluster-scoped kinds start a
+// single watch across all namespaces.
+func TestReflectorNamespaces(t *testing.T) {
+	collectors := options.CollectorSet{}
+	for c := range availableCollectors {
+		collectors[c] = struct{}{}
+	}
+
+	want := map[string][]string{}
+	for c := range collectors {
+		if _, ok := clusterScopedCollectors[c]; ok {
+			want[c] = []string{metav1.NamespaceAll}
+		} else {
+			want[c] = []string{"ns1", "ns2", "ns3"}
+		}
+	}
+
+	got := watchedNamespaces(t, options.NewOptions(), collectors, options.NamespaceList{"ns1", "ns2", "ns3"}, len(want))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected watches per resource\nwant: %v\ngot:  %v", want, got)
+	}
+}
+
+// TestRestrictNamespacesCollector ensures that the namespaces collector only
+// exposes the configured namespaces not in the denylist if restricted, using a
+// single watch.
+func TestRestrictNamespacesCollector(t *testing.T) {
+	tests := []struct {
+		restrict bool
+		denylist options.NamespaceList
+		want     []string
+	}{
+		{false, nil, []string{"ns1", "ns2", "ns3"}},
+		{true, nil, []string{"ns1", "ns3"}},
+		{true, options.NamespaceList{"ns3"}, []string{"ns1"}},
+	}
+
+	for _, test := range tests {
+		kubeClient := fake.NewSimpleClientset(
+			&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1", UID: "uid-1"}},
+			&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns2", UID: "uid-2"}},
+			&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns3", UID: "uid-3"}},
+		)
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		opts := options.NewOptions()
+		opts.RestrictNamespacesCollector = test.restrict
+		b := NewBuilder(ctx, opts)
+		b.WithEnabledCollectors(options.CollectorSet{"namespaces": struct{}{}})
+		b.WithNamespaces(options.NamespaceList{"ns1", "ns3"})
+		b.WithNamespaceDenylist(test.denylist)
+		b.WithKubeClient(kubeClient)
+		collectors := b.Build()
+
+		s := metricsstore.NewMetricsStore(extractMetricFamilyHeaders(namespaceMetricFamilies(allKeys, allKeys)), composeMetricGenFuncs(namespaceMetricFamilies(allKeys, allKeys)))
+		for _, ns := range test.want {
+			obj, err := kubeClient.CoreV1().Namespaces().Get(ns, metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Add(obj); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		waitForOutput(t, collectors[0], strings.Join(seriesLines(s), "\n"))
+		cancel()
+	}
+}
+
+// watchedNamespaces builds the given collectors and returns the namespaces
+// watched per resource, once watches for the given number of resources were
+// started.
+func watchedNamespaces(t *testing.T, opts *options.Options, collectors options.CollectorSet, namespaces options.NamespaceList, resources int) map[string][]string {
+	var mutex sync.Mutex
+	watches := map[string][]string{}
+
+	kubeClient := fake.NewSimpleClientset()
+	kubeClient.PrependWatchReactor("*", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		r := action.GetResource().Resource
+		watches[r] = append(watches[r], action.GetNamespace())
+
+		return false, nil, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := NewBuilder(ctx, opts)
+	b.WithEnabledCollectors(collectors)
+	b.WithNamespaces(namespaces)
+	b.WithKubeClient(kubeClient)
+	b.Build()
+
+	for i := 0; i < 100; i++ {
+		mutex.Lock()
+		started := len(watches)
+		mutex.Unlock()
+		if started >= resources {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	// Give reflectors started in excess a chance to show up.
+	time.Sleep(100 * time.Millisecond)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	got := map[string][]string{}
+	for r, ns := range watches {
+		sorted := append([]string{}, ns...)
+		sort.Strings(sorted)
+		got[r] = sorted
+	}
+
+	return got
+}
+
+// TestNamespaceSelector ensures that pods are collected from the namespaces
+// matching the namespace selector, as namespaces are created, deleted and
+// relabeled.
+func TestNamespaceSelector(t *testing.T) {
+	selected := map[string]string{"monitoring": "enabled"}
+	kubeClient := fake.NewSimpleClientset(
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1", Labels: selected}},
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns2"}},
+	)
+
+	pods := map[string]*v1.Pod{}
+	createPod := func(ns, name string) {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: ns,
+				UID:       types.UID(ns + "-" + name),
+			},
+		}
+		if _, err := kubeClient.CoreV1().Pods(ns).Create(pod); err != nil {
+			t.Fatal(err)
+		}
+		pods[ns+"/"+name] = pod
+	}
+	podsOf := func(namespaces ...string) []*v1.Pod {
+		ps := []*v1.Pod{}
+		for _, ns := range namespaces {
+			for _, p := range pods {
+				if p.Namespace == ns {
+					ps = append(ps, p)
+				}
+			}
+		}
+		return ps
+	}
+	labelNamespace := func(name string, l map[string]string) {
+		ns, err := kubeClient.CoreV1().Namespaces().Get(name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ns.Labels = l
+		if _, err := kubeClient.CoreV1().Namespaces().Update(ns); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	createPod("ns1", "pod1")
+	createPod("ns2", "pod2")
+	createPod("ns3", "pod3")
+
+	selector, err := labels.Parse("monitoring=enabled")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := NewBuilder(ctx, options.NewOptions())
+	b.WithEnabledCollectors(options.CollectorSet{"pods": struct{}{}})
+	b.WithNamespaces(options.DefaultNamespaces)
+	b.WithNamespaceSelector(selector)
+	b.WithKubeClient(kubeClient)
+	collectors := b.Build()
+
+	waitForOutput(t, collectors[0], expectedPodShard(t, podsOf("ns1"), 0, 1))
+
+	// Labeling a namespace picks up its pods.
+	labelNamespace("ns2", selected)
+	waitForOutput(t, collectors[0], expectedPodShard(t, podsOf("ns1", "ns2"), 0, 1))
+
+	// Creating a matching namespace picks up its pods.
+	if _, err := kubeClient.CoreV1().Namespaces().Create(&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns3", Labels: selected}}); err != nil {
+		t.Fatal(err)
+	}
+	waitForOutput(t, collectors[0], expectedPodShard(t, podsOf("ns1", "ns2", "ns3"), 0, 1))
+
+	// Unlabeling a namespace removes its pods.
+	labelNamespace("ns1", nil)
+	waitForOutput(t, collectors[0], expectedPodShard(t, podsOf("ns2", "ns3"), 0, 1))
+
+	// Deleting a namespace removes its pods.
+	if err := kubeClient.CoreV1().Namespaces().Delete("ns3", &metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitForOutput(t, collectors[0], expectedPodShard(t, podsOf("ns2"), 0, 1))
+
+	// Pods of selected namespaces are still watched, others are not.
+	createPod("ns1", "pod4")
+	createPod("ns2", "pod5")
+	waitForOutput(t, collectors[0], expectedPodShard(t, podsOf("ns2"), 0, 1))
+}
+
+// TestReflectorStore ensures that reflectors sharing a store only replace and
+// purge their own objects.
+func TestReflectorStore(t *testing.T) {
+	pod := func(ns, name string) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns, UID: types.UID(ns + "-" + name)}}
+	}
+
+	store := metricsstore.NewMetricsStore(extractMetricFamilyHeaders(podMetricFamilies(allKeys, allKeys)), composeMetricGenFuncs(podMetricFamilies(allKeys, allKeys)))
+	ns1 := newReflectorStore(store, newReflectorStatus())
+	ns2 := newReflectorStore(store, newReflectorStatus())
+
+	a, b, c := pod("ns1", "a"), pod("ns1", "b"), pod("ns2", "c")
+	for _, add := range []struct {
+		s   *reflectorStore
+		pod *v1.Pod
+	}{{ns1, a}, {ns1, b}, {ns2, c}} {
+		if err := add.s.Add(add.pod); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := ns1.Replace([]interface{}{b}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := expectedPodShard(t, []*v1.Pod{b, c}, 0, 1), strings.Join(seriesLines(store), "\n"); got != want {
+		t.Errorf("unexpected output after replace\nwant: %s\ngot:  %s", want, got)
+	}
+
+	ns2.purge()
+	if err := ns2.Add(pod("ns2", "d")); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := expectedPodShard(t, []*v1.Pod{b}, 0, 1), strings.Join(seriesLines(store), "\n"); got != want {
+		t.Errorf("unexpected output after purge\nwant: %s\ngot:  %s", want, got)
+	}
+}
+
+// TestSelectors ensures that the label and field selectors configured per
+// resource are passed to the API server when listing and watching its objects.
+func TestSelectors(t *testing.T) {
+	var mutex sync.Mutex
+	requests := map[string][]string{}
+	record := func(verb, resource string, l labels.Selector, f fields.Selector) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		requests[resource] = append(requests[resource], fmt.Sprintf("%s labels=%q fields=%q", verb, l, f))
+	}
+
+	kubeClient := fake.NewSimpleClientset(
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "ns1", UID: "uid-1", Labels: map[string]string{"tier": "frontend"}}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod2", Namespace: "ns1", UID: "uid-2", Labels: map[string]string{"tier": "backend"}}},
+	)
+	kubeClient.PrependReactor("list", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		r := action.(k8stesting.ListAction).GetListRestrictions()
+		record("list", action.GetResource().Resource, r.Labels, r.Fields)
+		return false, nil, nil
+	})
+	kubeClient.PrependWatchReactor("*", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		r := action.(k8stesting.WatchAction).GetWatchRestrictions()
+		record("watch", action.GetResource().Resource, r.Labels, r.Fields)
+		return false, nil, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := NewBuilder(ctx, options.NewOptions())
+	b.WithNamespaces(options.DefaultNamespaces)
+	b.WithSelectors(map[string]string{"pods": "tier=frontend"}, map[string]string{"secrets": "type=kubernetes.io/tls"})
+	b.WithKubeClient(kubeClient)
+	pods := b.buildPodCollector()
+	b.buildSecretCollector()
+	b.buildNodeCollector()
+
+	s := metricsstore.NewMetricsStore(extractMetricFamilyHeaders(podMetricFamilies(allKeys, allKeys)), composeMetricGenFuncs(podMetricFamilies(allKeys, allKeys)))
+	obj, err := kubeClient.CoreV1().Pods("ns1").Get("pod1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add(obj); err != nil {
+		t.Fatal(err)
+	}
+	waitForOutput(t, pods, strings.Join(seriesLines(s), "\n"))
+
+	want := map[string][]string{
+		"pods":    {`list labels="tier=frontend" fields=""`, `watch labels="tier=frontend" fields=""`},
+		"secrets": {`list labels="" fields="type=kubernetes.io/tls"`, `watch labels="" fields="type=kubernetes.io/tls"`},
+		"nodes":   {`list labels="" fields=""`, `watch labels="" fields=""`},
+	}
+	for i := 0; i < 100; i++ {
+		mutex.Lock()
+		got := map[string][]string{}
+		for r, reqs := range requests {
+			got[r] = append([]string{}, reqs...)
+		}
+		mutex.Unlock()
+		if reflect.DeepEqual(got, want) {
+			return
+		}
+		if i == 99 {
+			t.Fatalf("unexpected requests per resource\nwant: %v\ngot:  %v", want, got)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"k8s.io/kube-state-metrics/pkg/metrics"
+
+	certv1beta1 "k8s.io/api/certificates/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	descCSRLabelsName          = "kube_certificatesigningrequest_labels"
+	descCSRLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descCSRLabelsDefaultLabels = []string{"certificatesigningrequest"}
+
+	descCSRAnnotationsName = "kube_certificatesigningrequest_annotations"
+	descCSRAnnotationsHelp = "Kubernetes annotations converted to Prometheus labels."
+)
+
+func csrMetricFamilies(allowedLabels, allowedAnnotations keyAllowList) []metricFamilyDef {
+	return []metricFamilyDef{
+		{
+			Name: "kube_certificatesigningrequest_info",
+			Type: metrics.MetricTypeGauge,
+			Help: "Information about certificatesigningrequest.",
+			GenerateFunc: wrapCSRFunc(func(c *certv1beta1.CertificateSigningRequest) []*metrics.Metric {
+				return []*metrics.Metric{{
+					LabelKeys:   []string{"username"},
+					LabelValues: []string{c.Spec.Username},
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: descCSRLabelsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descCSRLabelsHelp,
+			GenerateFunc: wrapCSRFunc(func(c *certv1beta1.CertificateSigningRequest) []*metrics.Metric {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(c.Labels, allowedLabels)
+				return []*metrics.Metric{{
+					LabelKeys:   labelKeys,
+					LabelValues: labelValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: descCSRAnnotationsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descCSRAnnotationsHelp,
+			GenerateFunc: wrapCSRFunc(func(c *certv1beta1.CertificateSigningRequest) []*metrics.Metric {
+				annotationKeys, annotationValues := kubeAnnotationsToPrometheusAnnotations(c.Annotations, allowedAnnotations)
+				return []*metrics.Metric{{
+					LabelKeys:   annotationKeys,
+					LabelValues: annotationValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_certificatesigningrequest_created",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapCSRFunc(func(c *certv1beta1.CertificateSigningRequest) []*metrics.Metric {
+				return createdMetric(c.CreationTimestamp)
+			}),
+		},
+		{
+			Name: "kube_certificatesigningrequest_usage",
+			Type: metrics.MetricTypeGauge,
+			Help: "Key usages requested by the certificatesigningrequest.",
+			GenerateFunc: wrapCSRFunc(func(c *certv1beta1.CertificateSigningRequest) []*metrics.Metric {
+				ms := make([]*metrics.Metric, len(c.Spec.Usages))
+
+				for i, u := range c.Spec.Usages {
+					ms[i] = &metrics.Metric{
+						LabelKeys:   []string{"usage"},
+						LabelValues: []string{string(u)},
+						Value:       1,
+					}
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_certificatesigningrequest_condition",
+			Type: metrics.MetricTypeGauge,
+			Help: "The current approval state of the certificatesigningrequest.",
+			GenerateFunc: wrapCSRFunc(func(c *certv1beta1.CertificateSigningRequest) []*metrics.Metric {
+				approved, denied := csrApprovalState(c)
+
+				conditions := []struct {
+					v bool
+					n string
+				}{
+					{approved && !denied, "approved"},
+					{denied, "denied"},
+					{!approved && !denied, "pending"},
+				}
+
+				ms := make([]*metrics.Metric, len(conditions))
+
+				for i, cond := range conditions {
+					ms[i] = &metrics.Metric{
+						LabelKeys:   []string{"condition"},
+						LabelValues: []string{cond.n},
+						Value:       boolFloat64(cond.v),
+					}
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_certificatesigningrequest_certificate_issued",
+			Type: metrics.MetricTypeGauge,
+			Help: "Whether a certificate has been issued for the certificatesigningrequest.",
+			GenerateFunc: wrapCSRFunc(func(c *certv1beta1.CertificateSigningRequest) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: boolFloat64(len(c.Status.Certificate) > 0),
+				}}
+			}),
+		},
+	}
+}
+
+// csrApprovalState reports whether the request carries an Approved and/or a
+// Denied condition. A denied request is never signed, so callers should let
+// denial take precedence.
+func csrApprovalState(c *certv1beta1.CertificateSigningRequest) (approved bool, denied bool) {
+	for _, cond := range c.Status.Conditions {
+		switch cond.Type {
+		case certv1beta1.CertificateApproved:
+			approved = true
+		case certv1beta1.CertificateDenied:
+			denied = true
+		}
+	}
+	return
+}
+
+func createCSRListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
+	return cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return kubeClient.CertificatesV1beta1().CertificateSigningRequests().List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return kubeClient.CertificatesV1beta1().CertificateSigningRequests().Watch(opts)
+		},
+	}
+}
+
+func wrapCSRFunc(f func(*certv1beta1.CertificateSigningRequest) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		csr := obj.(*certv1beta1.CertificateSigningRequest)
+
+		ms := f(csr)
+
+		for _, m := range ms {
+			m.LabelKeys = append(descCSRLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{csr.Name}, m.LabelValues...)
+		}
+
+		return ms
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+	"time"
+
+	certv1beta1 "k8s.io/api/certificates/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCSRCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_certificatesigningrequest_info Information about certificatesigningrequest.
+		# TYPE kube_certificatesigningrequest_info gauge
+		# HELP kube_certificatesigningrequest_labels Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_certificatesigningrequest_labels gauge
+		# HELP kube_certificatesigningrequest_annotations Kubernetes annotations converted to Prometheus labels.
+		# TYPE kube_certificatesigningrequest_annotations gauge
+		# HELP kube_certificatesigningrequest_created Unix creation timestamp
+		# TYPE kube_certificatesigningrequest_created gauge
+		# HELP kube_certificatesigningrequest_usage Key usages requested by the certificatesigningrequest.
+		# TYPE kube_certificatesigningrequest_usage gauge
+		# HELP kube_certificatesigningrequest_condition The current approval state of the certificatesigningrequest.
+		# TYPE kube_certificatesigningrequest_condition gauge
+		# HELP kube_certificatesigningrequest_certificate_issued Whether a certificate has been issued for the certificatesigningrequest.
+		# TYPE kube_certificatesigningrequest_certificate_issued gauge
+	`
+
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &certv1beta1.CertificateSigningRequest{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "node-csr-pending",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+				},
+				Spec: certv1beta1.CertificateSigningRequestSpec{
+					Username: "system:bootstrap:abcdef",
+					Usages: []certv1beta1.KeyUsage{
+						certv1beta1.UsageDigitalSignature,
+						certv1beta1.UsageClientAuth,
+					},
+				},
+			},
+			Want: `
+				kube_certificatesigningrequest_certificate_issued{certificatesigningrequest="node-csr-pending"} 0
+				kube_certificatesigningrequest_condition{certificatesigningrequest="node-csr-pending",condition="approved"} 0
+				kube_certificatesigningrequest_condition{certificatesigningrequest="node-csr-pending",condition="denied"} 0
+				kube_certificatesigningrequest_condition{certificatesigningrequest="node-csr-pending",condition="pending"} 1
+				kube_certificatesigningrequest_created{certificatesigningrequest="node-csr-pending"} 1.5e+09
+				kube_certificatesigningrequest_info{certificatesigningrequest="node-csr-pending",username="system:bootstrap:abcdef"} 1
+				kube_certificatesigningrequest_usage{certificatesigningrequest="node-csr-pending",usage="client auth"} 1
+				kube_certificatesigningrequest_usage{certificatesigningrequest="node-csr-pending",usage="digital signature"} 1
+			`,
+			MetricNames: []string{
+				"kube_certificatesigningrequest_certificate_issued",
+				"kube_certificatesigningrequest_condition",
+				"kube_certificatesigningrequest_created",
+				"kube_certificatesigningrequest_info",
+				"kube_certificatesigningrequest_usage",
+			},
+		},
+		{
+			Obj: &certv1beta1.CertificateSigningRequest{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "node-csr-issued",
+					Labels: map[string]string{
+						"app": "kubelet",
+					},
+				},
+				Status: certv1beta1.CertificateSigningRequestStatus{
+					Conditions: []certv1beta1.CertificateSigningRequestCondition{
+						{Type: certv1beta1.CertificateApproved, Reason: "AutoApproved"},
+					},
+					Certificate: []byte("-----BEGIN CERTIFICATE-----"),
+				},
+			},
+			Want: `
+				kube_certificatesigningrequest_certificate_issued{certificatesigningrequest="node-csr-issued"} 1
+				kube_certificatesigningrequest_condition{certificatesigningrequest="node-csr-issued",condition="approved"} 1
+				kube_certificatesigningrequest_condition{certificatesigningrequest="node-csr-issued",condition="denied"} 0
+				kube_certificatesigningrequest_condition{certificatesigningrequest="node-csr-issued",condition="pending"} 0
+				kube_certificatesigningrequest_labels{certificatesigningrequest="node-csr-issued",label_app="kubelet"} 1
+			`,
+			MetricNames: []string{
+				"kube_certificatesigningrequest_certificate_issued",
+				"kube_certificatesigningrequest_condition",
+				"kube_certificatesigningrequest_labels",
+			},
+		},
+		{
+			Obj: &certv1beta1.CertificateSigningRequest{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "node-csr-denied",
+				},
+				Status: certv1beta1.CertificateSigningRequestStatus{
+					Conditions: []certv1beta1.CertificateSigningRequestCondition{
+						{Type: certv1beta1.CertificateApproved},
+						{Type: certv1beta1.CertificateDenied},
+					},
+				},
+			},
+			Want: `
+				kube_certificatesigningrequest_condition{certificatesigningrequest="node-csr-denied",condition="approved"} 0
+				kube_certificatesigningrequest_condition{certificatesigningrequest="node-csr-denied",condition="denied"} 1
+				kube_certificatesigningrequest_condition{certificatesigningrequest="node-csr-denied",condition="pending"} 0
+			`,
+			MetricNames: []string{"kube_certificatesigningrequest_condition"},
+		},
+	}
+	for i, c := range cases {
+		c.Func = composeMetricGenFuncs(csrMetricFamilies(allKeys, allKeys))
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
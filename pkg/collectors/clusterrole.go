@@ -0,0 +1,112 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"k8s.io/kube-state-metrics/pkg/metrics"
+
+	rbac "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	descClusterRoleLabelsName          = "kube_clusterrole_labels"
+	descClusterRoleLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descClusterRoleLabelsDefaultLabels = []string{"clusterrole"}
+
+	descClusterRoleAnnotationsName = "kube_clusterrole_annotations"
+	descClusterRoleAnnotationsHelp = "Kubernetes annotations converted to Prometheus labels."
+)
+
+func clusterRoleMetricFamilies(allowedLabels, allowedAnnotations keyAllowList) []metricFamilyDef {
+	return []metricFamilyDef{
+		{
+			Name: descClusterRoleLabelsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descClusterRoleLabelsHelp,
+			GenerateFunc: wrapClusterRoleFunc(func(r *rbac.ClusterRole) []*metrics.Metric {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(r.Labels, allowedLabels)
+				return []*metrics.Metric{{
+					LabelKeys:   labelKeys,
+					LabelValues: labelValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: descClusterRoleAnnotationsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descClusterRoleAnnotationsHelp,
+			GenerateFunc: wrapClusterRoleFunc(func(r *rbac.ClusterRole) []*metrics.Metric {
+				annotationKeys, annotationValues := kubeAnnotationsToPrometheusAnnotations(r.Annotations, allowedAnnotations)
+				return []*metrics.Metric{{
+					LabelKeys:   annotationKeys,
+					LabelValues: annotationValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_clusterrole_created",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapClusterRoleFunc(func(r *rbac.ClusterRole) []*metrics.Metric {
+				return createdMetric(r.CreationTimestamp)
+			}),
+		},
+		{
+			Name: "kube_clusterrole_rules",
+			Type: metrics.MetricTypeGauge,
+			Help: "Number of policy rules of the clusterrole.",
+			GenerateFunc: wrapClusterRoleFunc(func(r *rbac.ClusterRole) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(len(r.Rules)),
+				}}
+			}),
+		},
+	}
+}
+
+func createClusterRoleListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
+	return cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return kubeClient.RbacV1().ClusterRoles().List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return kubeClient.RbacV1().ClusterRoles().Watch(opts)
+		},
+	}
+}
+
+func wrapClusterRoleFunc(f func(*rbac.ClusterRole) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		clusterRole := obj.(*rbac.ClusterRole)
+
+		ms := f(clusterRole)
+
+		for _, m := range ms {
+			m.LabelKeys = append(descClusterRoleLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{clusterRole.Name}, m.LabelValues...)
+		}
+
+		return ms
+	}
+}
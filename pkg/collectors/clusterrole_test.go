@@ -0,0 +1,71 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+
+	rbac "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestClusterRoleCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_clusterrole_labels Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_clusterrole_labels gauge
+		# HELP kube_clusterrole_annotations Kubernetes annotations converted to Prometheus labels.
+		# TYPE kube_clusterrole_annotations gauge
+		# HELP kube_clusterrole_created Unix creation timestamp
+		# TYPE kube_clusterrole_created gauge
+		# HELP kube_clusterrole_rules Number of policy rules of the clusterrole.
+		# TYPE kube_clusterrole_rules gauge
+	`
+
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &rbac.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "cluster-admin",
+					Annotations: map[string]string{
+						"rbac.authorization.kubernetes.io/autoupdate": "true",
+					},
+				},
+				Rules: []rbac.PolicyRule{
+					{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}},
+					{NonResourceURLs: []string{"*"}, Verbs: []string{"*"}},
+				},
+			},
+			Want: `
+				kube_clusterrole_annotations{annotation_rbac_authorization_kubernetes_io_autoupdate="true",clusterrole="cluster-admin"} 1
+				kube_clusterrole_rules{clusterrole="cluster-admin"} 2
+			`,
+			MetricNames: []string{
+				"kube_clusterrole_annotations",
+				"kube_clusterrole_created",
+				"kube_clusterrole_rules",
+			},
+		},
+	}
+	for i, c := range cases {
+		c.Func = composeMetricGenFuncs(clusterRoleMetricFamilies(allKeys, allKeys))
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"k8s.io/kube-state-metrics/pkg/metrics"
+
+	rbac "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	descClusterRoleBindingLabelsName          = "kube_clusterrolebinding_labels"
+	descClusterRoleBindingLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descClusterRoleBindingLabelsDefaultLabels = []string{"clusterrolebinding"}
+
+	descClusterRoleBindingAnnotationsName = "kube_clusterrolebinding_annotations"
+	descClusterRoleBindingAnnotationsHelp = "Kubernetes annotations converted to Prometheus labels."
+)
+
+func clusterRoleBindingMetricFamilies(allowedLabels, allowedAnnotations keyAllowList) []metricFamilyDef {
+	return []metricFamilyDef{
+		{
+			Name: descClusterRoleBindingLabelsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descClusterRoleBindingLabelsHelp,
+			GenerateFunc: wrapClusterRoleBindingFunc(func(r *rbac.ClusterRoleBinding) []*metrics.Metric {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(r.Labels, allowedLabels)
+				return []*metrics.Metric{{
+					LabelKeys:   labelKeys,
+					LabelValues: labelValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: descClusterRoleBindingAnnotationsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descClusterRoleBindingAnnotationsHelp,
+			GenerateFunc: wrapClusterRoleBindingFunc(func(r *rbac.ClusterRoleBinding) []*metrics.Metric {
+				annotationKeys, annotationValues := kubeAnnotationsToPrometheusAnnotations(r.Annotations, allowedAnnotations)
+				return []*metrics.Metric{{
+					LabelKeys:   annotationKeys,
+					LabelValues: annotationValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_clusterrolebinding_created",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapClusterRoleBindingFunc(func(r *rbac.ClusterRoleBinding) []*metrics.Metric {
+				return createdMetric(r.CreationTimestamp)
+			}),
+		},
+		{
+			Name: "kube_clusterrolebinding_info",
+			Type: metrics.MetricTypeGauge,
+			Help: "Information about clusterrolebinding.",
+			GenerateFunc: wrapClusterRoleBindingFunc(func(r *rbac.ClusterRoleBinding) []*metrics.Metric {
+				return []*metrics.Metric{{
+					LabelKeys:   []string{"roleref_kind", "roleref_name"},
+					LabelValues: []string{r.RoleRef.Kind, r.RoleRef.Name},
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_clusterrolebinding_subject",
+			Type: metrics.MetricTypeGauge,
+			Help: "Subjects bound to the referenced role by the clusterrolebinding.",
+			GenerateFunc: wrapClusterRoleBindingFunc(func(r *rbac.ClusterRoleBinding) []*metrics.Metric {
+				return subjectMetrics(r.Subjects, r.RoleRef)
+			}),
+		},
+	}
+}
+
+func createClusterRoleBindingListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
+	return cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return kubeClient.RbacV1().ClusterRoleBindings().List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return kubeClient.RbacV1().ClusterRoleBindings().Watch(opts)
+		},
+	}
+}
+
+func wrapClusterRoleBindingFunc(f func(*rbac.ClusterRoleBinding) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		clusterRoleBinding := obj.(*rbac.ClusterRoleBinding)
+
+		ms := f(clusterRoleBinding)
+
+		for _, m := range ms {
+			m.LabelKeys = append(descClusterRoleBindingLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{clusterRoleBinding.Name}, m.LabelValues...)
+		}
+
+		return ms
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+
+	rbac "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestClusterRoleBindingCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_clusterrolebinding_labels Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_clusterrolebinding_labels gauge
+		# HELP kube_clusterrolebinding_annotations Kubernetes annotations converted to Prometheus labels.
+		# TYPE kube_clusterrolebinding_annotations gauge
+		# HELP kube_clusterrolebinding_created Unix creation timestamp
+		# TYPE kube_clusterrolebinding_created gauge
+		# HELP kube_clusterrolebinding_info Information about clusterrolebinding.
+		# TYPE kube_clusterrolebinding_info gauge
+		# HELP kube_clusterrolebinding_subject Subjects bound to the referenced role by the clusterrolebinding.
+		# TYPE kube_clusterrolebinding_subject gauge
+	`
+
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &rbac.ClusterRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "cluster-admin",
+					Labels: map[string]string{
+						"kubernetes.io/bootstrapping": "rbac-defaults",
+					},
+				},
+				Subjects: []rbac.Subject{
+					{Kind: rbac.GroupKind, APIGroup: rbac.GroupName, Name: "system:masters"},
+				},
+				RoleRef: rbac.RoleRef{APIGroup: rbac.GroupName, Kind: "ClusterRole", Name: "cluster-admin"},
+			},
+			Want: `
+				kube_clusterrolebinding_info{clusterrolebinding="cluster-admin",roleref_kind="ClusterRole",roleref_name="cluster-admin"} 1
+				kube_clusterrolebinding_labels{clusterrolebinding="cluster-admin",label_kubernetes_io_bootstrapping="rbac-defaults"} 1
+				kube_clusterrolebinding_subject{clusterrolebinding="cluster-admin",roleref_kind="ClusterRole",roleref_name="cluster-admin",subject_kind="Group",subject_name="system:masters",subject_namespace=""} 1
+			`,
+			MetricNames: []string{
+				"kube_clusterrolebinding_created",
+				"kube_clusterrolebinding_info",
+				"kube_clusterrolebinding_labels",
+				"kube_clusterrolebinding_subject",
+			},
+		},
+	}
+	for i, c := range cases {
+		c.Func = composeMetricGenFuncs(clusterRoleBindingMetricFamilies(allKeys, allKeys))
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
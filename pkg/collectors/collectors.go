@@ -17,12 +17,15 @@ limitations under the License.
 package collectors
 
 import (
+	"io"
+	"sort"
 	"time"
 
 	"regexp"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/kube-state-metrics/pkg/metrics"
 )
 
@@ -45,38 +48,110 @@ var (
 		[]string{"resource"},
 	)
 
+	LabelConflictsMetric = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ksm_label_conflicts",
+			Help: "Set to 1 for each Prometheus label name that Kubernetes labels or annotations collided on since startup, only the first of the colliding keys is exposed",
+		},
+		[]string{"source", "label"},
+	)
+
 	invalidLabelCharRE = regexp.MustCompile(`[^a-zA-Z0-9_]`)
 )
 
 type store interface {
-	GetAll() []*metrics.Metric
+	WriteAll(io.Writer) error
+}
+
+// whiteBlackLister decides which metric families are generated, see
+// k8s.io/kube-state-metrics/pkg/whiteblacklist.
+type whiteBlackLister interface {
+	IsIncluded(string) bool
+	IsExcluded(string) bool
 }
 
 // Collector represents a kube-state-metrics metric collector. It is stripped
 // down version of the Prometheus client_golang collector.
 type Collector struct {
 	store store
+
+	name          string
+	reflectorSets []*reflectorSet
 }
 
 func newCollector(s store) *Collector {
-	return &Collector{s}
+	return &Collector{store: s}
 }
 
-// Collect returns all metrics of the underlying store of the collector.
-func (c *Collector) Collect() []*metrics.Metric {
-	return c.store.GetAll()
+// Collect writes all metrics of the underlying store of the collector to the
+// given writer, grouped by metric family and each family preceded by its HELP
+// and TYPE lines.
+func (c *Collector) Collect(w io.Writer) error {
+	return c.store.WriteAll(w)
 }
 
-func newMetricFamilyDef(name, help string, labelKeys []string, constLabels prometheus.Labels) *metricFamilyDef {
-	return &metricFamilyDef{name, help, labelKeys, constLabels}
+// metricFamilyDef represents a metric family definition. GenerateFunc
+// generates the metrics of the family for a single Kubernetes object.
+type metricFamilyDef struct {
+	Name         string
+	Help         string
+	Type         metrics.MetricType
+	GenerateFunc func(obj interface{}) []*metrics.Metric
 }
 
-// metricFamilyDef represents a metric family definition
-type metricFamilyDef struct {
-	Name        string
-	Help        string
-	LabelKeys   []string
-	ConstLabels prometheus.Labels
+// generate returns the metric family for the given Kubernetes object.
+func (d *metricFamilyDef) generate(obj interface{}) metrics.Family {
+	return metrics.Family{
+		Name:    d.Name,
+		Metrics: d.GenerateFunc(obj),
+	}
+}
+
+// header returns the HELP and TYPE lines of the metric family.
+func (d *metricFamilyDef) header() string {
+	return string(metrics.NewMetricFamilyDesc(d.Name, d.Help, d.Type))
+}
+
+// composeMetricGenFuncs takes a slice of metric family definitions and returns
+// a single function generating all of their families for a given object, in
+// the same order as the definitions.
+func composeMetricGenFuncs(families []metricFamilyDef) func(obj interface{}) []metrics.Family {
+	return func(obj interface{}) []metrics.Family {
+		fs := make([]metrics.Family, len(families))
+
+		for i, f := range families {
+			fs[i] = f.generate(obj)
+		}
+
+		return fs
+	}
+}
+
+// filterMetricFamilies takes a white- and a blacklist and a slice of metric
+// family definitions and returns a filtered slice. Excluded families are never
+// generated nor stored.
+func filterMetricFamilies(l whiteBlackLister, families []metricFamilyDef) []metricFamilyDef {
+	filtered := []metricFamilyDef{}
+
+	for _, f := range families {
+		if l.IsIncluded(f.Name) {
+			filtered = append(filtered, f)
+		}
+	}
+
+	return filtered
+}
+
+// extractMetricFamilyHeaders takes a slice of metric family definitions and
+// returns their HELP and TYPE lines, in the same order as the definitions.
+func extractMetricFamilyHeaders(families []metricFamilyDef) []string {
+	headers := make([]string, len(families))
+
+	for i, f := range families {
+		headers[i] = f.header()
+	}
+
+	return headers
 }
 
 func boolFloat64(b bool) float64 {
@@ -86,52 +161,104 @@ func boolFloat64(b bool) float64 {
 	return 0
 }
 
-// addConditionMetrics generates one metric for each possible node condition
-// status. For this function to work properly, the last label in the metric
-// description must be the condition.
-func addConditionMetrics(desc *metricFamilyDef, cs v1.ConditionStatus, lv ...string) []*metrics.Metric {
+// createdMetric returns the creation timestamp metric of an object, if set.
+func createdMetric(t metav1.Time) []*metrics.Metric {
 	ms := []*metrics.Metric{}
-	m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, append(lv, "true"), boolFloat64(cs == v1.ConditionTrue))
-	if err != nil {
-		panic(err)
-	}
-	ms = append(ms, m)
-	m, err = metrics.NewMetric(desc.Name, desc.LabelKeys, append(lv, "false"), boolFloat64(cs == v1.ConditionFalse))
-	if err != nil {
-		panic(err)
-	}
-	ms = append(ms, m)
-	m, err = metrics.NewMetric(desc.Name, desc.LabelKeys, append(lv, "unknown"), boolFloat64(cs == v1.ConditionUnknown))
-	if err != nil {
-		panic(err)
+
+	if !t.IsZero() {
+		ms = append(ms, &metrics.Metric{
+			Value: float64(t.Unix()),
+		})
 	}
-	ms = append(ms, m)
 
 	return ms
 }
 
-func kubeLabelsToPrometheusLabels(labels map[string]string) ([]string, []string) {
-	labelKeys := make([]string, len(labels))
-	labelValues := make([]string, len(labels))
-	i := 0
-	for k, v := range labels {
-		labelKeys[i] = "label_" + sanitizeLabelName(k)
-		labelValues[i] = v
-		i++
+// addConditionMetrics generates one metric for each possible condition
+// status. The condition status is added as a label with the given key.
+func addConditionMetrics(cs v1.ConditionStatus, statusLabelKey string) []*metrics.Metric {
+	return []*metrics.Metric{
+		{
+			LabelKeys:   []string{statusLabelKey},
+			LabelValues: []string{"true"},
+			Value:       boolFloat64(cs == v1.ConditionTrue),
+		},
+		{
+			LabelKeys:   []string{statusLabelKey},
+			LabelValues: []string{"false"},
+			Value:       boolFloat64(cs == v1.ConditionFalse),
+		},
+		{
+			LabelKeys:   []string{statusLabelKey},
+			LabelValues: []string{"unknown"},
+			Value:       boolFloat64(cs == v1.ConditionUnknown),
+		},
 	}
-	return labelKeys, labelValues
 }
 
-func kubeAnnotationsToPrometheusAnnotations(annotations map[string]string) ([]string, []string) {
-	annotationKeys := make([]string, len(annotations))
-	annotationValues := make([]string, len(annotations))
-	i := 0
-	for k, v := range annotations {
-		annotationKeys[i] = "annotation_" + sanitizeLabelName(k)
-		annotationValues[i] = v
-		i++
+// keyAllowList is the set of Kubernetes label or annotation keys exported by a
+// kube_*_labels or kube_*_annotations metric family. The key "*" allows all
+// keys, an empty keyAllowList none.
+type keyAllowList map[string]struct{}
+
+// allKeys is the keyAllowList allowing all keys.
+var allKeys = keyAllowList{"*": {}}
+
+func newKeyAllowList(keys []string) keyAllowList {
+	l := keyAllowList{}
+	for _, k := range keys {
+		l[k] = struct{}{}
+	}
+	return l
+}
+
+func (l keyAllowList) allows(key string) bool {
+	if _, ok := l["*"]; ok {
+		return true
+	}
+	_, ok := l[key]
+	return ok
+}
+
+func kubeLabelsToPrometheusLabels(labels map[string]string, allowed keyAllowList) ([]string, []string) {
+	return kubeMapToPrometheusLabels("label", labels, allowed)
+}
+
+func kubeAnnotationsToPrometheusAnnotations(annotations map[string]string, allowed keyAllowList) ([]string, []string) {
+	return kubeMapToPrometheusLabels("annotation", annotations, allowed)
+}
+
+// kubeMapToPrometheusLabels converts the allowed entries of the given
+// Kubernetes labels or annotations to Prometheus labels prefixed with source.
+// Distinct keys can collide once sanitized, e.g. app.kubernetes.io/name and
+// app_kubernetes_io_name. In that case only the entry with the
+// lexicographically smallest key is kept and the resulting name is flagged in
+// LabelConflictsMetric. Flagging is idempotent, as this runs whenever the
+// metrics of an object are regenerated.
+func kubeMapToPrometheusLabels(source string, m map[string]string, allowed keyAllowList) ([]string, []string) {
+	sortedKeys := make([]string, 0, len(m))
+	for k := range m {
+		if allowed.allows(k) {
+			sortedKeys = append(sortedKeys, k)
+		}
+	}
+	sort.Strings(sortedKeys)
+
+	keys := make([]string, 0, len(sortedKeys))
+	values := make([]string, 0, len(sortedKeys))
+	seen := make(map[string]struct{}, len(sortedKeys))
+	for _, k := range sortedKeys {
+		name := source + "_" + sanitizeLabelName(k)
+		if _, ok := seen[name]; ok {
+			LabelConflictsMetric.WithLabelValues(source, name).Set(1)
+			continue
+		}
+		seen[name] = struct{}{}
+
+		keys = append(keys, name)
+		values = append(values, m[k])
 	}
-	return annotationKeys, annotationValues
+	return keys, values
 }
 
 func sanitizeLabelName(s string) string {
@@ -0,0 +1,556 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"golang.org/x/net/context"
+	admissionregistration "k8s.io/api/admissionregistration/v1beta1"
+	apps "k8s.io/api/apps/v1beta1"
+	autoscaling "k8s.io/api/autoscaling/v2beta1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	certv1beta1 "k8s.io/api/certificates/v1beta1"
+	coordinationv1beta1 "k8s.io/api/coordination/v1beta1"
+	"k8s.io/api/core/v1"
+	extensions "k8s.io/api/extensions/v1beta1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policy "k8s.io/api/policy/v1beta1"
+	rbac "k8s.io/api/rbac/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	storagev1beta1 "k8s.io/api/storage/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/kube-state-metrics/pkg/metrics"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/whiteblacklist"
+)
+
+// collectorTestCase describes how to build the store of a collector and a
+// minimal valid object of its kind, for tests covering all collectors.
+type collectorTestCase struct {
+	name     string
+	families []metricFamilyDef
+	newObj   func(metav1.ObjectMeta) interface{}
+}
+
+func (c collectorTestCase) newStore() *metricsstore.MetricsStore {
+	return metricsstore.NewMetricsStore(extractMetricFamilyHeaders(c.families), composeMetricGenFuncs(c.families))
+}
+
+// allCollectorTestCases returns a collectorTestCase for every collector.
+func allCollectorTestCases() []collectorTestCase {
+	var (
+		one     int32 = 1
+		suspend       = false
+	)
+
+	return []collectorTestCase{
+		{"configmaps", configMapMetricFamilies, func(m metav1.ObjectMeta) interface{} { return &v1.ConfigMap{ObjectMeta: m} }},
+		{"cronjobs", cronJobMetricFamilies(allKeys, allKeys), func(m metav1.ObjectMeta) interface{} {
+			m.CreationTimestamp = metav1.Time{Time: time.Unix(1500000000, 0)}
+			return &batchv1beta1.CronJob{ObjectMeta: m, Spec: batchv1beta1.CronJobSpec{Schedule: "* * * * *", Suspend: &suspend}}
+		}},
+		{"daemonsets", daemonSetMetricFamilies(allKeys, allKeys), func(m metav1.ObjectMeta) interface{} { return &extensions.DaemonSet{ObjectMeta: m} }},
+		{"deployments", deploymentMetricFamilies(allKeys, allKeys), func(m metav1.ObjectMeta) interface{} {
+			return &extensions.Deployment{ObjectMeta: m, Spec: extensions.DeploymentSpec{Replicas: &one}}
+		}},
+		{"endpoints", endpointMetricFamilies(allKeys, allKeys), func(m metav1.ObjectMeta) interface{} { return &v1.Endpoints{ObjectMeta: m} }},
+		{"horizontalpodautoscalers", hpaMetricFamilies(allKeys, allKeys), func(m metav1.ObjectMeta) interface{} {
+			return &autoscaling.HorizontalPodAutoscaler{ObjectMeta: m, Spec: autoscaling.HorizontalPodAutoscalerSpec{MinReplicas: &one}}
+		}},
+		{"ingresses", ingressMetricFamilies(allKeys, allKeys), func(m metav1.ObjectMeta) interface{} { return &extensions.Ingress{ObjectMeta: m} }},
+		{"storageclasses", storageClassMetricFamilies(allKeys, allKeys), func(m metav1.ObjectMeta) interface{} { return &storagev1.StorageClass{ObjectMeta: m} }},
+		{"volumeattachments", volumeAttachmentMetricFamilies(allKeys, allKeys), func(m metav1.ObjectMeta) interface{} { return &storagev1beta1.VolumeAttachment{ObjectMeta: m} }},
+		{"certificatesigningrequests", csrMetricFamilies(allKeys, allKeys), func(m metav1.ObjectMeta) interface{} { return &certv1beta1.CertificateSigningRequest{ObjectMeta: m} }},
+		{"networkpolicies", networkPolicyMetricFamilies(allKeys, allKeys), func(m metav1.ObjectMeta) interface{} { return &networkingv1.NetworkPolicy{ObjectMeta: m} }},
+		{"validatingwebhookconfigurations", validatingWebhookConfigurationMetricFamilies(allKeys, allKeys), func(m metav1.ObjectMeta) interface{} {
+			return &admissionregistration.ValidatingWebhookConfiguration{ObjectMeta: m}
+		}},
+		{"mutatingwebhookconfigurations", mutatingWebhookConfigurationMetricFamilies(allKeys, allKeys), func(m metav1.ObjectMeta) interface{} {
+			return &admissionregistration.MutatingWebhookConfiguration{ObjectMeta: m}
+		}},
+		{"roles", roleMetricFamilies(allKeys, allKeys), func(m metav1.ObjectMeta) interface{} { return &rbac.Role{ObjectMeta: m} }},
+		{"clusterroles", clusterRoleMetricFamilies(allKeys, allKeys), func(m metav1.ObjectMeta) interface{} { return &rbac.ClusterRole{ObjectMeta: m} }},
+		{"rolebindings", roleBindingMetricFamilies(allKeys, allKeys), func(m metav1.ObjectMeta) interface{} { return &rbac.RoleBinding{ObjectMeta: m} }},
+		{"clusterrolebindings", clusterRoleBindingMetricFamilies(allKeys, allKeys), func(m metav1.ObjectMeta) interface{} { return &rbac.ClusterRoleBinding{ObjectMeta: m} }},
+		{"serviceaccounts", serviceAccountMetricFamilies(allKeys, allKeys), func(m metav1.ObjectMeta) interface{} { return &v1.ServiceAccount{ObjectMeta: m} }},
+		{"jobs", jobMetricFamilies(allKeys, allKeys), func(m metav1.ObjectMeta) interface{} { return &batchv1.Job{ObjectMeta: m} }},
+		{"leases", leaseMetricFamilies(allKeys, allKeys), func(m metav1.ObjectMeta) interface{} { return &coordinationv1beta1.Lease{ObjectMeta: m} }},
+		{"limitranges", limitRangeMetricFamilies, func(m metav1.ObjectMeta) interface{} { return &v1.LimitRange{ObjectMeta: m} }},
+		{"namespaces", namespaceMetricFamilies(allKeys, allKeys), func(m metav1.ObjectMeta) interface{} { return &v1.Namespace{ObjectMeta: m} }},
+		{"nodes", append(nodeMetricFamilies(allKeys, allKeys), nodeNonGenericResourceMetricFamilies...), func(m metav1.ObjectMeta) interface{} { return &v1.Node{ObjectMeta: m} }},
+		{"persistentvolumeclaims", persistentVolumeClaimMetricFamilies(allKeys, allKeys), func(m metav1.ObjectMeta) interface{} { return &v1.PersistentVolumeClaim{ObjectMeta: m} }},
+		{"persistentvolumes", persistentVolumeMetricFamilies(allKeys, allKeys), func(m metav1.ObjectMeta) interface{} { return &v1.PersistentVolume{ObjectMeta: m} }},
+		{"pods", append(podMetricFamilies(allKeys, allKeys), podNonGenericResourceMetricFamilies...), func(m metav1.ObjectMeta) interface{} { return &v1.Pod{ObjectMeta: m} }},
+		{"poddisruptionbudgets", podDisruptionBudgetMetricFamilies, func(m metav1.ObjectMeta) interface{} { return &policy.PodDisruptionBudget{ObjectMeta: m} }},
+		{"replicasets", replicaSetMetricFamilies, func(m metav1.ObjectMeta) interface{} { return &extensions.ReplicaSet{ObjectMeta: m} }},
+		{"replicationcontrollers", replicationControllerMetricFamilies, func(m metav1.ObjectMeta) interface{} { return &v1.ReplicationController{ObjectMeta: m} }},
+		{"resourcequotas", resourceQuotaMetricFamilies, func(m metav1.ObjectMeta) interface{} { return &v1.ResourceQuota{ObjectMeta: m} }},
+		{"secrets", secretMetricFamilies(allKeys, allKeys), func(m metav1.ObjectMeta) interface{} { return &v1.Secret{ObjectMeta: m} }},
+		{"services", serviceMetricFamilies(allKeys, allKeys), func(m metav1.ObjectMeta) interface{} { return &v1.Service{ObjectMeta: m} }},
+		{"statefulsets", statefulSetMetricFamilies(allKeys, allKeys), func(m metav1.ObjectMeta) interface{} { return &apps.StatefulSet{ObjectMeta: m} }},
+	}
+}
+
+// TestCrossNamespaceCollisions ensures that objects sharing a name in
+// different namespaces (or a name reused by a recreated cluster scoped object)
+// neither overwrite each other's metrics nor drop them on deletion.
+func TestCrossNamespaceCollisions(t *testing.T) {
+	for _, c := range allCollectorTestCases() {
+		obj1 := c.newObj(metav1.ObjectMeta{Name: "web-0", Namespace: "ns1", UID: types.UID("uid-1")})
+		obj2 := c.newObj(metav1.ObjectMeta{Name: "web-0", Namespace: "ns2", UID: types.UID("uid-2")})
+
+		onlyObj2 := c.newStore()
+		if err := onlyObj2.Add(obj2); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		s := c.newStore()
+		for _, o := range []interface{}{obj1, obj2} {
+			if err := s.Add(o); err != nil {
+				t.Fatalf("%s: %v", c.name, err)
+			}
+		}
+
+		if got, want := len(seriesLines(s)), 2*len(seriesLines(onlyObj2)); got != want {
+			t.Errorf("%s: expected %d series for two colliding objects but got %d", c.name, want, got)
+		}
+
+		tombstone := cache.DeletedFinalStateUnknown{Key: "ns1/web-0", Obj: obj1}
+		if err := s.Delete(tombstone); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		if got, want := seriesLines(s), seriesLines(onlyObj2); strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("%s: expected deletion of one object to keep the other's series\nwant: %v\ngot:  %v", c.name, want, got)
+		}
+	}
+}
+
+// TestSharding ensures that for every collector the union of all shards
+// equals the unsharded output, with each object exposed by exactly one shard.
+func TestSharding(t *testing.T) {
+	const (
+		objectCount = 30
+		totalShards = 3
+	)
+
+	for _, c := range allCollectorTestCases() {
+		objs := make([]interface{}, objectCount)
+		for i := range objs {
+			objs[i] = c.newObj(metav1.ObjectMeta{
+				Name:      fmt.Sprintf("obj%d", i),
+				Namespace: fmt.Sprintf("ns%d", i%4),
+				UID:       types.UID(fmt.Sprintf("uid-%d", i)),
+			})
+		}
+
+		unsharded := c.newStore()
+		shards := make([]*metricsstore.MetricsStore, totalShards)
+		for i := range shards {
+			shards[i] = c.newStore()
+			shards[i].WithSharding(int32(i), totalShards)
+		}
+
+		for _, o := range objs {
+			for _, s := range append(shards, unsharded) {
+				if err := s.Add(o); err != nil {
+					t.Fatalf("%s: %v", c.name, err)
+				}
+			}
+		}
+
+		all := seriesLines(unsharded)
+		union := []string{}
+		seen := map[string]int{}
+		for i, s := range shards {
+			lines := seriesLines(s)
+			if len(all) > 0 && len(lines) == len(all) {
+				t.Errorf("%s: expected shard %d to only hold a subset of the objects", c.name, i)
+			}
+			for _, l := range lines {
+				seen[l]++
+			}
+			union = append(union, lines...)
+		}
+		sort.Strings(union)
+
+		for l, n := range seen {
+			if n > 1 {
+				t.Errorf("%s: expected series to be exposed by a single shard, got %d: %s", c.name, n, l)
+			}
+		}
+
+		if got, want := strings.Join(union, "\n"), strings.Join(all, "\n"); got != want {
+			t.Errorf("%s: expected union of all shards to equal the unsharded output\nwant: %s\ngot:  %s", c.name, want, got)
+		}
+	}
+}
+
+// TestNamespaceDenylist ensures that for every namespaced collector objects in
+// denylisted namespaces are dropped before reaching the store, and that the
+// series of an object are removed once it is updated into such a namespace.
+func TestNamespaceDenylist(t *testing.T) {
+	b := NewBuilder(context.TODO(), options.NewOptions())
+	b.WithNamespaceDenylist(options.NamespaceList{"kube-system", "ci-1"})
+
+	for _, c := range allCollectorTestCases() {
+		if _, ok := clusterScopedCollectors[c.name]; ok {
+			continue
+		}
+
+		kept := c.newObj(metav1.ObjectMeta{Name: "obj1", Namespace: "ns1", UID: "uid-1"})
+		denied := c.newObj(metav1.ObjectMeta{Name: "obj2", Namespace: "kube-system", UID: "uid-2"})
+		moved := c.newObj(metav1.ObjectMeta{Name: "obj1", Namespace: "ci-1", UID: "uid-1"})
+
+		onlyKept := c.newStore()
+		if err := onlyKept.Add(kept); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		want := strings.Join(seriesLines(onlyKept), "\n")
+
+		s := c.newStore()
+		filtered := newFilteredStore(s, b.notInDeniedNamespace)
+		if err := filtered.Replace([]interface{}{kept, denied}, ""); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if err := filtered.Add(c.newObj(metav1.ObjectMeta{Name: "obj3", Namespace: "ci-1", UID: "uid-3"})); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if got := strings.Join(seriesLines(s), "\n"); got != want {
+			t.Errorf("%s: expected only the object outside the denylist\nwant: %s\ngot:  %s", c.name, want, got)
+		}
+
+		if err := filtered.Update(moved); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if got := seriesLines(s); len(got) != 0 {
+			t.Errorf("%s: expected the series of the moved object to be removed, got %v", c.name, got)
+		}
+	}
+
+	s := newEventStore(time.Hour)
+	filtered := newFilteredStore(s, b.notInDeniedNamespace)
+	for _, ns := range []string{"ns1", "kube-system"} {
+		e := &v1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "event", Namespace: ns, UID: types.UID("uid-" + ns)},
+			InvolvedObject: v1.ObjectReference{Kind: "Pod", Name: "pod"},
+			Reason:         "BackOff",
+		}
+		if err := filtered.Add(e); err != nil {
+			t.Fatalf("events: %v", err)
+		}
+	}
+	if got := seriesLines(s); len(got) != 1 || !strings.Contains(got[0], `namespace="ns1"`) {
+		t.Errorf("events: expected only the event outside the denylist to be counted, got %v", got)
+	}
+}
+
+func TestLabelsAllowList(t *testing.T) {
+	meta := metav1.ObjectMeta{
+		Name:      "obj",
+		Namespace: "ns",
+		Labels: map[string]string{
+			"app":               "web",
+			"team":              "core",
+			"pod-template-hash": "12345",
+		},
+		Annotations: map[string]string{
+			"app":       "web-annotation",
+			"build.sha": "abcdef",
+		},
+	}
+
+	cases := []struct {
+		desc      string
+		allowList map[string][]string
+		tc        generateMetricsTestCase
+		families  func(b *Builder) []metricFamilyDef
+	}{
+		{
+			desc: "pods default to all labels and no annotations",
+			tc: generateMetricsTestCase{
+				Obj: &v1.Pod{ObjectMeta: meta},
+				Want: `
+					kube_pod_annotations{namespace="ns",pod="obj"} 1
+					kube_pod_labels{label_app="web",label_pod_template_hash="12345",label_team="core",namespace="ns",pod="obj"} 1
+				`,
+			},
+			families: func(b *Builder) []metricFamilyDef { return podMetricFamilies(b.allowedKeys("pods")) },
+		},
+		{
+			desc:      "pods restricted to the allowed keys",
+			allowList: map[string][]string{"pods": {"app", "team"}, "nodes": {"*"}},
+			tc: generateMetricsTestCase{
+				Obj: &v1.Pod{ObjectMeta: meta},
+				Want: `
+					kube_pod_annotations{annotation_app="web-annotation",namespace="ns",pod="obj"} 1
+					kube_pod_labels{label_app="web",label_team="core",namespace="ns",pod="obj"} 1
+				`,
+			},
+			families: func(b *Builder) []metricFamilyDef { return podMetricFamilies(b.allowedKeys("pods")) },
+		},
+		{
+			desc:      "pods without any allowed keys",
+			allowList: map[string][]string{"pods": {}},
+			tc: generateMetricsTestCase{
+				Obj: &v1.Pod{ObjectMeta: meta},
+				Want: `
+					kube_pod_annotations{namespace="ns",pod="obj"} 1
+					kube_pod_labels{namespace="ns",pod="obj"} 1
+				`,
+			},
+			families: func(b *Builder) []metricFamilyDef { return podMetricFamilies(b.allowedKeys("pods")) },
+		},
+		{
+			desc: "namespaces default to all labels and annotations",
+			tc: generateMetricsTestCase{
+				Obj: &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: meta.Name, Labels: meta.Labels, Annotations: meta.Annotations}},
+				Want: `
+					kube_namespace_annotations{annotation_app="web-annotation",annotation_build_sha="abcdef",namespace="obj"} 1
+					kube_namespace_labels{label_app="web",label_pod_template_hash="12345",label_team="core",namespace="obj"} 1
+				`,
+			},
+			families: func(b *Builder) []metricFamilyDef { return namespaceMetricFamilies(b.allowedKeys("namespaces")) },
+		},
+		{
+			desc:      "nodes with all keys allowed",
+			allowList: map[string][]string{"nodes": {"*"}},
+			tc: generateMetricsTestCase{
+				Obj: &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: meta.Name, Labels: meta.Labels, Annotations: meta.Annotations}},
+				Want: `
+					kube_node_annotations{annotation_app="web-annotation",annotation_build_sha="abcdef",node="obj"} 1
+					kube_node_labels{label_app="web",label_pod_template_hash="12345",label_team="core",node="obj"} 1
+				`,
+			},
+			families: func(b *Builder) []metricFamilyDef { return nodeMetricFamilies(b.allowedKeys("nodes")) },
+		},
+	}
+
+	for _, c := range cases {
+		b := NewBuilder(context.TODO(), options.NewOptions())
+		b.WithLabelsAllowList(c.allowList)
+
+		c.tc.Func = composeMetricGenFuncs(c.families(b))
+		c.tc.MetricNames = []string{}
+		for _, f := range c.families(b) {
+			if strings.HasSuffix(f.Name, "_labels") || strings.HasSuffix(f.Name, "_annotations") {
+				c.tc.MetricNames = append(c.tc.MetricNames, f.Name)
+			}
+		}
+
+		if err := c.tc.run(); err != nil {
+			t.Errorf("%s: unexpected collecting result:\n%s", c.desc, err)
+		}
+	}
+}
+
+func TestKubeMapToPrometheusLabelsConflicts(t *testing.T) {
+	cases := []struct {
+		desc          string
+		m             map[string]string
+		allowed       keyAllowList
+		wantKeys      []string
+		wantValues    []string
+		wantConflicts []string
+	}{
+		{
+			desc:       "no conflict",
+			m:          map[string]string{"app": "web", "team": "core"},
+			allowed:    allKeys,
+			wantKeys:   []string{"app", "team"},
+			wantValues: []string{"web", "core"},
+		},
+		{
+			desc:          "two keys sanitized to the same name",
+			m:             map[string]string{"app_kubernetes_io_name": "b", "app.kubernetes.io/name": "a"},
+			allowed:       allKeys,
+			wantKeys:      []string{"app_kubernetes_io_name"},
+			wantValues:    []string{"a"},
+			wantConflicts: []string{"app_kubernetes_io_name"},
+		},
+		{
+			desc:          "three keys sanitized to the same name",
+			m:             map[string]string{"a.b": "2", "a_b": "3", "a-b": "1", "c": "4"},
+			allowed:       allKeys,
+			wantKeys:      []string{"a_b", "c"},
+			wantValues:    []string{"1", "4"},
+			wantConflicts: []string{"a_b"},
+		},
+		{
+			desc:       "conflicting key not allowed",
+			m:          map[string]string{"a.b": "1", "a_b": "2"},
+			allowed:    newKeyAllowList([]string{"a_b"}),
+			wantKeys:   []string{"a_b"},
+			wantValues: []string{"2"},
+		},
+	}
+
+	for _, c := range cases {
+		for _, source := range []string{"label", "annotation"} {
+			convert := kubeLabelsToPrometheusLabels
+			if source == "annotation" {
+				convert = kubeAnnotationsToPrometheusAnnotations
+			}
+
+			// The result has to be the same regardless of map iteration order.
+			for i := 0; i < 10; i++ {
+				keys, values := convert(c.m, c.allowed)
+
+				wantKeys := make([]string, len(c.wantKeys))
+				for j, k := range c.wantKeys {
+					wantKeys[j] = source + "_" + k
+				}
+
+				if !reflect.DeepEqual(keys, wantKeys) || !reflect.DeepEqual(values, c.wantValues) {
+					t.Errorf("%s (%s): expected %v=%v, got %v=%v", c.desc, source, wantKeys, c.wantValues, keys, values)
+				}
+			}
+
+			for _, name := range c.wantConflicts {
+				if got := gaugeVecValue(t, LabelConflictsMetric, source, source+"_"+name); got != 1 {
+					t.Errorf("%s (%s): expected conflict on %s to be flagged once, got %v", c.desc, source, name, got)
+				}
+			}
+		}
+	}
+}
+
+func gaugeVecValue(t *testing.T, g *prometheus.GaugeVec, labelValues ...string) float64 {
+	m := &dto.Metric{}
+	if err := g.WithLabelValues(labelValues...).Write(m); err != nil {
+		t.Fatal(err)
+	}
+
+	return m.GetGauge().GetValue()
+}
+
+// seriesLines returns the sorted series lines of the given store, omitting
+// HELP and TYPE lines.
+func seriesLines(s store) []string {
+	lines := []string{}
+
+	for _, l := range strings.Split(writeAll(s), "\n") {
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+		lines = append(lines, l)
+	}
+
+	sort.Strings(lines)
+
+	return lines
+}
+
+func TestFilterMetricFamilies(t *testing.T) {
+	generated := map[string]int{}
+	family := func(name string) metricFamilyDef {
+		return metricFamilyDef{
+			Name: name,
+			Type: metrics.MetricTypeGauge,
+			Help: "Test family.",
+			GenerateFunc: func(obj interface{}) []*metrics.Metric {
+				generated[name]++
+				return []*metrics.Metric{{Value: 1}}
+			},
+		}
+	}
+	families := []metricFamilyDef{
+		family("kube_test_info"),
+		family("kube_test_labels"),
+		family("kube_test_status_ready"),
+		family("kube_test_status_phase"),
+	}
+
+	tests := []struct {
+		Desc      string
+		Whitelist map[string]struct{}
+		Blacklist map[string]struct{}
+		Want      []string
+	}{
+		{
+			Desc: "no filtering",
+			Want: []string{"kube_test_info", "kube_test_labels", "kube_test_status_ready", "kube_test_status_phase"},
+		},
+		{
+			Desc:      "whitelist",
+			Whitelist: map[string]struct{}{"kube_test_info": {}, "kube_test_status_.*": {}},
+			Want:      []string{"kube_test_info", "kube_test_status_ready", "kube_test_status_phase"},
+		},
+		{
+			Desc:      "blacklist",
+			Blacklist: map[string]struct{}{"kube_test_labels": {}, ".*_phase": {}},
+			Want:      []string{"kube_test_info", "kube_test_status_ready"},
+		},
+	}
+
+	for _, test := range tests {
+		for k := range generated {
+			delete(generated, k)
+		}
+
+		l, err := whiteblacklist.New(test.Whitelist, test.Blacklist)
+		if err != nil {
+			t.Fatalf("%s: %v", test.Desc, err)
+		}
+
+		filtered := filterMetricFamilies(l, families)
+		s := metricsstore.NewMetricsStore(extractMetricFamilyHeaders(filtered), composeMetricGenFuncs(filtered))
+		if err := s.Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", UID: types.UID("uid")}}); err != nil {
+			t.Fatalf("%s: %v", test.Desc, err)
+		}
+		out := writeAll(s)
+
+		for _, f := range families {
+			want := false
+			for _, w := range test.Want {
+				if f.Name == w {
+					want = true
+				}
+			}
+
+			if got := generated[f.Name] > 0; got != want {
+				t.Errorf("%s: expected family %s to be generated: %v, got: %v", test.Desc, f.Name, want, got)
+			}
+			if got := strings.Contains(out, f.Name); got != want {
+				t.Errorf("%s: expected family %s to be exposed: %v, got: %v\n%s", test.Desc, f.Name, want, got, out)
+			}
+		}
+	}
+}
+
+func writeAll(s store) string {
+	buf := &bytes.Buffer{}
+
+	if err := s.WriteAll(buf); err != nil {
+		panic(err)
+	}
+
+	return buf.String()
+}
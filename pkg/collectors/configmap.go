@@ -30,26 +30,46 @@ import (
 var (
 	descConfigMapLabelsDefaultLabels = []string{"namespace", "configmap"}
 
-	descConfigMapInfo = newMetricFamilyDef(
-		"kube_configmap_info",
-		"Information about configmap.",
-		descConfigMapLabelsDefaultLabels,
-		nil,
-	)
-
-	descConfigMapCreated = newMetricFamilyDef(
-		"kube_configmap_created",
-		"Unix creation timestamp",
-		descConfigMapLabelsDefaultLabels,
-		nil,
-	)
-
-	descConfigMapMetadataResourceVersion = newMetricFamilyDef(
-		"kube_configmap_metadata_resource_version",
-		"Resource version representing a specific version of the configmap.",
-		append(descConfigMapLabelsDefaultLabels, "resource_version"),
-		nil,
-	)
+	configMapMetricFamilies = []metricFamilyDef{
+		{
+			Name: "kube_configmap_info",
+			Type: metrics.MetricTypeGauge,
+			Help: "Information about configmap.",
+			GenerateFunc: wrapConfigMapFunc(func(c *v1.ConfigMap) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: 1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_configmap_created",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapConfigMapFunc(func(c *v1.ConfigMap) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				if !c.CreationTimestamp.IsZero() {
+					ms = append(ms, &metrics.Metric{
+						Value: float64(c.CreationTimestamp.Unix()),
+					})
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_configmap_metadata_resource_version",
+			Type: metrics.MetricTypeGauge,
+			Help: "Resource version representing a specific version of the configmap.",
+			GenerateFunc: wrapConfigMapFunc(func(c *v1.ConfigMap) []*metrics.Metric {
+				return []*metrics.Metric{{
+					LabelKeys:   []string{"resource_version"},
+					LabelValues: []string{string(c.ObjectMeta.ResourceVersion)},
+					Value:       1,
+				}}
+			}),
+		},
+	}
 )
 
 func createConfigMapListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
@@ -63,33 +83,17 @@ func createConfigMapListWatch(kubeClient clientset.Interface, ns string) cache.L
 	}
 }
 
-func generateConfigMapMetrics(obj interface{}) []*metrics.Metric {
-	ms := []*metrics.Metric{}
+func wrapConfigMapFunc(f func(*v1.ConfigMap) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		configMap := obj.(*v1.ConfigMap)
 
-	// TODO: Refactor
-	mPointer := obj.(*v1.ConfigMap)
-	m := *mPointer
+		ms := f(configMap)
 
-	addConstMetric := func(desc *metricFamilyDef, v float64, lv ...string) {
-		lv = append([]string{m.Namespace, m.Name}, lv...)
-
-		m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, lv, v)
-		if err != nil {
-			panic(err)
+		for _, m := range ms {
+			m.LabelKeys = append(descConfigMapLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{configMap.Namespace, configMap.Name}, m.LabelValues...)
 		}
 
-		ms = append(ms, m)
-	}
-	addGauge := func(desc *metricFamilyDef, v float64, lv ...string) {
-		addConstMetric(desc, v, lv...)
-	}
-	addGauge(descConfigMapInfo, 1)
-
-	if !m.CreationTimestamp.IsZero() {
-		addGauge(descConfigMapCreated, float64(m.CreationTimestamp.Unix()))
+		return ms
 	}
-
-	addGauge(descConfigMapMetadataResourceVersion, 1, string(m.ObjectMeta.ResourceVersion))
-
-	return ms
 }
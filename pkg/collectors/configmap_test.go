@@ -71,7 +71,7 @@ func TestConfigMapCollector(t *testing.T) {
 		},
 	}
 	for i, c := range cases {
-		c.Func = generateConfigMapMetrics
+		c.Func = composeMetricGenFuncs(configMapMetricFamilies)
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
@@ -37,56 +37,147 @@ var (
 	descCronJobLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
 	descCronJobLabelsDefaultLabels = []string{"namespace", "cronjob"}
 
-	descCronJobLabels = newMetricFamilyDef(
-		descCronJobLabelsName,
-		descCronJobLabelsHelp,
-		descCronJobLabelsDefaultLabels, nil,
-	)
-
-	descCronJobInfo = newMetricFamilyDef(
-		"kube_cronjob_info",
-		"Info about cronjob.",
-		append(descCronJobLabelsDefaultLabels, "schedule", "concurrency_policy"),
-		nil,
-	)
-	descCronJobCreated = newMetricFamilyDef(
-		"kube_cronjob_created",
-		"Unix creation timestamp",
-		descCronJobLabelsDefaultLabels,
-		nil,
-	)
-	descCronJobStatusActive = newMetricFamilyDef(
-		"kube_cronjob_status_active",
-		"Active holds pointers to currently running jobs.",
-		descCronJobLabelsDefaultLabels,
-		nil,
-	)
-	descCronJobStatusLastScheduleTime = newMetricFamilyDef(
-		"kube_cronjob_status_last_schedule_time",
-		"LastScheduleTime keeps information of when was the last time the job was successfully scheduled.",
-		descCronJobLabelsDefaultLabels,
-		nil,
-	)
-	descCronJobSpecSuspend = newMetricFamilyDef(
-		"kube_cronjob_spec_suspend",
-		"Suspend flag tells the controller to suspend subsequent executions.",
-		descCronJobLabelsDefaultLabels,
-		nil,
-	)
-	descCronJobSpecStartingDeadlineSeconds = newMetricFamilyDef(
-		"kube_cronjob_spec_starting_deadline_seconds",
-		"Deadline in seconds for starting the job if it misses scheduled time for any reason.",
-		descCronJobLabelsDefaultLabels,
-		nil,
-	)
-	descCronJobNextScheduledTime = newMetricFamilyDef(
-		"kube_cronjob_next_schedule_time",
-		"Next time the cronjob should be scheduled. The time after lastScheduleTime, or after the cron job's creation time if it's never been scheduled. Use this to determine if the job is delayed.",
-		descCronJobLabelsDefaultLabels,
-		nil,
-	)
+	descCronJobAnnotationsName = "kube_cronjob_annotations"
+	descCronJobAnnotationsHelp = "Kubernetes annotations converted to Prometheus labels."
 )
 
+func cronJobMetricFamilies(allowedLabels, allowedAnnotations keyAllowList) []metricFamilyDef {
+	return []metricFamilyDef{
+		{
+			Name: descCronJobLabelsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descCronJobLabelsHelp,
+			GenerateFunc: wrapCronJobFunc(func(j *batchv1beta1.CronJob) []*metrics.Metric {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(j.Labels, allowedLabels)
+				return []*metrics.Metric{{
+					LabelKeys:   labelKeys,
+					LabelValues: labelValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: descCronJobAnnotationsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descCronJobAnnotationsHelp,
+			GenerateFunc: wrapCronJobFunc(func(j *batchv1beta1.CronJob) []*metrics.Metric {
+				annotationKeys, annotationValues := kubeAnnotationsToPrometheusAnnotations(j.Annotations, allowedAnnotations)
+				return []*metrics.Metric{{
+					LabelKeys:   annotationKeys,
+					LabelValues: annotationValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_cronjob_info",
+			Type: metrics.MetricTypeGauge,
+			Help: "Info about cronjob.",
+			GenerateFunc: wrapCronJobFunc(func(j *batchv1beta1.CronJob) []*metrics.Metric {
+				return []*metrics.Metric{{
+					LabelKeys:   []string{"schedule", "concurrency_policy"},
+					LabelValues: []string{j.Spec.Schedule, string(j.Spec.ConcurrencyPolicy)},
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_cronjob_created",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapCronJobFunc(func(j *batchv1beta1.CronJob) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				if !j.CreationTimestamp.IsZero() {
+					ms = append(ms, &metrics.Metric{
+						Value: float64(j.CreationTimestamp.Unix()),
+					})
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_cronjob_status_active",
+			Type: metrics.MetricTypeGauge,
+			Help: "Active holds pointers to currently running jobs.",
+			GenerateFunc: wrapCronJobFunc(func(j *batchv1beta1.CronJob) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(len(j.Status.Active)),
+				}}
+			}),
+		},
+		{
+			Name: "kube_cronjob_status_last_schedule_time",
+			Type: metrics.MetricTypeGauge,
+			Help: "LastScheduleTime keeps information of when was the last time the job was successfully scheduled.",
+			GenerateFunc: wrapCronJobFunc(func(j *batchv1beta1.CronJob) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				if j.Status.LastScheduleTime != nil {
+					ms = append(ms, &metrics.Metric{
+						Value: float64(j.Status.LastScheduleTime.Unix()),
+					})
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_cronjob_spec_suspend",
+			Type: metrics.MetricTypeGauge,
+			Help: "Suspend flag tells the controller to suspend subsequent executions.",
+			GenerateFunc: wrapCronJobFunc(func(j *batchv1beta1.CronJob) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				if j.Spec.Suspend != nil {
+					ms = append(ms, &metrics.Metric{
+						Value: boolFloat64(*j.Spec.Suspend),
+					})
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_cronjob_spec_starting_deadline_seconds",
+			Type: metrics.MetricTypeGauge,
+			Help: "Deadline in seconds for starting the job if it misses scheduled time for any reason.",
+			GenerateFunc: wrapCronJobFunc(func(j *batchv1beta1.CronJob) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				if j.Spec.StartingDeadlineSeconds != nil {
+					ms = append(ms, &metrics.Metric{
+						Value: float64(*j.Spec.StartingDeadlineSeconds),
+					})
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_cronjob_next_schedule_time",
+			Type: metrics.MetricTypeGauge,
+			Help: "Next time the cronjob should be scheduled. The time after lastScheduleTime, or after the cron job's creation time if it's never been scheduled. Use this to determine if the job is delayed.",
+			GenerateFunc: wrapCronJobFunc(func(j *batchv1beta1.CronJob) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				// If the cron job is suspended, don't track the next scheduled time
+				nextScheduledTime, err := getNextScheduledTime(j.Spec.Schedule, j.Status.LastScheduleTime, j.CreationTimestamp)
+				if err != nil {
+					panic(err)
+				} else if !*j.Spec.Suspend {
+					ms = append(ms, &metrics.Metric{
+						Value: float64(nextScheduledTime.Unix()),
+					})
+				}
+
+				return ms
+			}),
+		},
+	}
+}
+
 func createCronJobListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
 	return cache.ListWatch{
 		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
@@ -112,61 +203,17 @@ func getNextScheduledTime(schedule string, lastScheduleTime *metav1.Time, create
 	return time.Time{}, fmt.Errorf("Created time and lastScheduleTime are both zero")
 }
 
-func cronJobLabelsDesc(labelKeys []string) *metricFamilyDef {
-	return newMetricFamilyDef(
-		descCronJobLabelsName,
-		descCronJobLabelsHelp,
-		append(descCronJobLabelsDefaultLabels, labelKeys...),
-		nil,
-	)
-}
-
-func generateCronJobMetrics(obj interface{}) []*metrics.Metric {
-	ms := []*metrics.Metric{}
+func wrapCronJobFunc(f func(*batchv1beta1.CronJob) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		cronJob := obj.(*batchv1beta1.CronJob)
 
-	// TODO: Refactor
-	jPointer := obj.(*batchv1beta1.CronJob)
-	j := *jPointer
+		ms := f(cronJob)
 
-	addGauge := func(desc *metricFamilyDef, v float64, lv ...string) {
-		lv = append([]string{j.Namespace, j.Name}, lv...)
-
-		m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, lv, v)
-		if err != nil {
-			panic(err)
+		for _, m := range ms {
+			m.LabelKeys = append(descCronJobLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{cronJob.Namespace, cronJob.Name}, m.LabelValues...)
 		}
 
-		ms = append(ms, m)
-	}
-
-	if j.Spec.StartingDeadlineSeconds != nil {
-		addGauge(descCronJobSpecStartingDeadlineSeconds, float64(*j.Spec.StartingDeadlineSeconds))
-	}
-
-	// If the cron job is suspended, don't track the next scheduled time
-	nextScheduledTime, err := getNextScheduledTime(j.Spec.Schedule, j.Status.LastScheduleTime, j.CreationTimestamp)
-	if err != nil {
-		panic(err)
-	} else if !*j.Spec.Suspend {
-		addGauge(descCronJobNextScheduledTime, float64(nextScheduledTime.Unix()))
-	}
-
-	addGauge(descCronJobInfo, 1, j.Spec.Schedule, string(j.Spec.ConcurrencyPolicy))
-
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(j.Labels)
-	addGauge(cronJobLabelsDesc(labelKeys), 1, labelValues...)
-
-	if !j.CreationTimestamp.IsZero() {
-		addGauge(descCronJobCreated, float64(j.CreationTimestamp.Unix()))
-	}
-	addGauge(descCronJobStatusActive, float64(len(j.Status.Active)))
-	if j.Spec.Suspend != nil {
-		addGauge(descCronJobSpecSuspend, boolFloat64(*j.Spec.Suspend))
+		return ms
 	}
-
-	if j.Status.LastScheduleTime != nil {
-		addGauge(descCronJobStatusLastScheduleTime, float64(j.Status.LastScheduleTime.Unix()))
-	}
-
-	return ms
 }
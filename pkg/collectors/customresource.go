@@ -0,0 +1,134 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/golang/glog"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	jsonserializer "k8s.io/apimachinery/pkg/runtime/serializer/json"
+	"k8s.io/apimachinery/pkg/runtime/serializer/streaming"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/pkg/customresource"
+	"k8s.io/kube-state-metrics/pkg/metrics"
+)
+
+// customResourceMetricFamilies returns the metric family definitions declared
+// for the given custom resource.
+func customResourceMetricFamilies(r *customresource.Resource) []metricFamilyDef {
+	families := make([]metricFamilyDef, len(r.Metrics))
+
+	for i := range r.Metrics {
+		m := &r.Metrics[i]
+
+		families[i] = metricFamilyDef{
+			Name: r.MetricName(*m),
+			Type: metrics.MetricType(m.Type),
+			Help: m.Help,
+			GenerateFunc: wrapCustomResourceFunc(r, func(u *unstructured.Unstructured) []*metrics.Metric {
+				v, ok, err := m.Value(u.Object)
+				if err != nil {
+					glog.V(2).Infof("Failed to generate metric %s for %s %s/%s: %v", r.MetricName(*m), r.Kind, u.GetNamespace(), u.GetName(), err)
+					return []*metrics.Metric{}
+				}
+				if !ok {
+					return []*metrics.Metric{}
+				}
+
+				labelValues, err := m.LabelValues(u.Object)
+				if err != nil {
+					glog.V(2).Infof("Failed to generate metric %s for %s %s/%s: %v", r.MetricName(*m), r.Kind, u.GetNamespace(), u.GetName(), err)
+					return []*metrics.Metric{}
+				}
+
+				return []*metrics.Metric{{
+					LabelKeys:   m.LabelKeys(),
+					LabelValues: labelValues,
+					Value:       v,
+				}}
+			}),
+		}
+	}
+
+	return families
+}
+
+func createCustomResourceListWatch(client rest.Interface, r *customresource.Resource, ns string) cache.ListWatch {
+	return cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return client.Get().
+				AbsPath(r.APIPath(ns)).
+				SpecificallyVersionedParams(&opts, metav1.ParameterCodec, metav1.SchemeGroupVersion).
+				Do().
+				Get()
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			opts.Watch = true
+			return client.Get().
+				AbsPath(r.APIPath(ns)).
+				SpecificallyVersionedParams(&opts, metav1.ParameterCodec, metav1.SchemeGroupVersion).
+				WatchWithSpecificDecoders(
+					func(body io.ReadCloser) streaming.Decoder {
+						return streaming.NewDecoder(jsonserializer.Framer.NewFrameReader(body), watchEventDecoder{})
+					},
+					unstructured.UnstructuredJSONScheme,
+				)
+		},
+	}
+}
+
+// watchEventDecoder decodes the envelope of watch events, which, unlike the
+// embedded objects, carries no kind.
+type watchEventDecoder struct{}
+
+func (watchEventDecoder) Decode(data []byte, _ *schema.GroupVersionKind, into runtime.Object) (runtime.Object, *schema.GroupVersionKind, error) {
+	if err := json.Unmarshal(data, into); err != nil {
+		return nil, nil, err
+	}
+
+	return into, nil, nil
+}
+
+func wrapCustomResourceFunc(r *customresource.Resource, f func(*unstructured.Unstructured) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	defaultLabelKeys := r.DefaultLabelKeys()
+
+	return func(obj interface{}) []*metrics.Metric {
+		u := obj.(*unstructured.Unstructured)
+
+		ms := f(u)
+
+		defaultLabelValues := []string{u.GetName()}
+		if r.Namespaced {
+			defaultLabelValues = []string{u.GetNamespace(), u.GetName()}
+		}
+
+		for _, m := range ms {
+			m.LabelKeys = append(defaultLabelKeys, m.LabelKeys...)
+			m.LabelValues = append(defaultLabelValues, m.LabelValues...)
+		}
+
+		return ms
+	}
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/pkg/customresource"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+)
+
+const testCustomResourceConfig = `
+resources:
+- group: example.com
+  version: v1
+  kind: Widget
+  resource: widgets
+  namespaced: true
+  metrics:
+  - name: replicas
+    help: Number of desired replicas.
+    valuePath: .spec.replicas
+    labels:
+    - name: color
+      path: .spec.color
+  - name: ready
+    help: Whether the widget is ready.
+    valuePath: '{.status.conditions[?(@.type=="Ready")].status}'
+`
+
+func TestCustomResourceCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_crd_widget_replicas Number of desired replicas.
+		# TYPE kube_crd_widget_replicas gauge
+		# HELP kube_crd_widget_ready Whether the widget is ready.
+		# TYPE kube_crd_widget_ready gauge
+	`
+
+	c, err := customresource.ParseConfig([]byte(testCustomResourceConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	families := customResourceMetricFamilies(&c.Resources[0])
+
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "example.com/v1",
+				"kind":       "Widget",
+				"metadata": map[string]interface{}{
+					"name":      "widget1",
+					"namespace": "ns1",
+				},
+				"spec": map[string]interface{}{
+					"replicas": int64(3),
+					"color":    "blue",
+				},
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Ready", "status": "True"},
+					},
+				},
+			}},
+			Want: metadata + `
+				kube_crd_widget_replicas{color="blue",namespace="ns1",widget="widget1"} 3
+				kube_crd_widget_ready{namespace="ns1",widget="widget1"} 1
+			`,
+		},
+		{
+			Obj: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "example.com/v1",
+				"kind":       "Widget",
+				"metadata": map[string]interface{}{
+					"name":      "widget2",
+					"namespace": "ns2",
+				},
+				"spec": map[string]interface{}{
+					"replicas": "not-a-number",
+				},
+			}},
+			Want: metadata,
+		},
+	}
+
+	for i, c := range cases {
+		c.Func = composeMetricGenFuncs(families)
+		c.Want = strings.Replace(c.Want, metadata, "", 1)
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
+
+func TestCustomResourceListWatch(t *testing.T) {
+	c, err := customresource.ParseConfig([]byte(testCustomResourceConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &c.Resources[0]
+
+	widget := func(name string, replicas int) string {
+		return fmt.Sprintf(`{"apiVersion":"example.com/v1","kind":"Widget","metadata":{"name":%q,"namespace":"ns1","uid":%q,"resourceVersion":"1"},"spec":{"replicas":%d}}`, name, name, replicas)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/apis/example.com/v1/namespaces/ns1/widgets" {
+			http.NotFound(w, req)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if req.URL.Query().Get("watch") != "true" {
+			fmt.Fprintf(w, `{"apiVersion":"example.com/v1","kind":"WidgetList","metadata":{"resourceVersion":"1"},"items":[%s]}`, widget("widget1", 1))
+			return
+		}
+
+		fmt.Fprintf(w, `{"type":"ADDED","object":%s}`+"\n", widget("widget2", 2))
+		w.(http.Flusher).Flush()
+		<-req.Context().Done()
+	}))
+	defer server.Close()
+
+	client, err := customresource.NewClient(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	families := customResourceMetricFamilies(r)
+	store := metricsstore.NewMetricsStore(extractMetricFamilyHeaders(families), composeMetricGenFuncs(families))
+
+	lw := createCustomResourceListWatch(client, r, "ns1")
+	reflector := cache.NewReflector(&lw, &unstructured.Unstructured{}, store, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reflector.Run(ctx.Done())
+
+	want := []string{
+		`kube_crd_widget_replicas{color="",namespace="ns1",widget="widget1"} 1`,
+		`kube_crd_widget_replicas{color="",namespace="ns1",widget="widget2"} 2`,
+	}
+
+	var out string
+	for i := 0; i < 100; i++ {
+		out = writeAll(store)
+		if strings.Contains(out, want[0]) && strings.Contains(out, want[1]) {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	t.Fatalf("expected listed and watched widgets to be exposed, got:\n%s", out)
+}
@@ -32,82 +32,136 @@ var (
 	descDaemonSetLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
 	descDaemonSetLabelsDefaultLabels = []string{"namespace", "daemonset"}
 
-	descDaemonSetCreated = newMetricFamilyDef(
-		"kube_daemonset_created",
-		"Unix creation timestamp",
-		descDaemonSetLabelsDefaultLabels,
-		nil,
-	)
-	descDaemonSetCurrentNumberScheduled = newMetricFamilyDef(
-		"kube_daemonset_status_current_number_scheduled",
-		"The number of nodes running at least one daemon pod and are supposed to.",
-		descDaemonSetLabelsDefaultLabels,
-		nil,
-	)
-	descDaemonSetDesiredNumberScheduled = newMetricFamilyDef(
-		"kube_daemonset_status_desired_number_scheduled",
-		"The number of nodes that should be running the daemon pod.",
-		descDaemonSetLabelsDefaultLabels,
-		nil,
-	)
-	descDaemonSetNumberAvailable = newMetricFamilyDef(
-		"kube_daemonset_status_number_available",
-		"The number of nodes that should be running the daemon pod and have one or more of the daemon pod running and available",
-		descDaemonSetLabelsDefaultLabels,
-		nil,
-	)
-	descDaemonSetNumberMisscheduled = newMetricFamilyDef(
-		"kube_daemonset_status_number_misscheduled",
-		"The number of nodes running a daemon pod but are not supposed to.",
-		descDaemonSetLabelsDefaultLabels,
-		nil,
-	)
-	descDaemonSetNumberReady = newMetricFamilyDef(
-		"kube_daemonset_status_number_ready",
-		"The number of nodes that should be running the daemon pod and have one or more of the daemon pod running and ready.",
-		descDaemonSetLabelsDefaultLabels,
-		nil,
-	)
-	descDaemonSetNumberUnavailable = newMetricFamilyDef(
-		"kube_daemonset_status_number_unavailable",
-		"The number of nodes that should be running the daemon pod and have none of the daemon pod running and available",
-		descDaemonSetLabelsDefaultLabels,
-		nil,
-	)
-	descDaemonSetUpdatedNumberScheduled = newMetricFamilyDef(
-		"kube_daemonset_updated_number_scheduled",
-		"The total number of nodes that are running updated daemon pod",
-		descDaemonSetLabelsDefaultLabels,
-		nil,
-	)
-	descDaemonSetMetadataGeneration = newMetricFamilyDef(
-		"kube_daemonset_metadata_generation",
-		"Sequence number representing a specific generation of the desired state.",
-		descDaemonSetLabelsDefaultLabels,
-		nil,
-	)
-	descDaemonSetLabels = newMetricFamilyDef(
-		descDaemonSetLabelsName,
-		descDaemonSetLabelsHelp,
-		descDaemonSetLabelsDefaultLabels,
-		nil,
-	)
+	descDaemonSetAnnotationsName = "kube_daemonset_annotations"
+	descDaemonSetAnnotationsHelp = "Kubernetes annotations converted to Prometheus labels."
 )
 
-// TODO: Not necessary without HELP and TYPE line
-// // Describe implements the prometheus.Collector interface.
-// func (dc *daemonsetCollector) Describe(ch chan<- *metricFamilyDef) {
-// 	ch <- descDaemonSetCreated
-// 	ch <- descDaemonSetCurrentNumberScheduled
-// 	ch <- descDaemonSetNumberAvailable
-// 	ch <- descDaemonSetNumberMisscheduled
-// 	ch <- descDaemonSetNumberUnavailable
-// 	ch <- descDaemonSetDesiredNumberScheduled
-// 	ch <- descDaemonSetNumberReady
-// 	ch <- descDaemonSetUpdatedNumberScheduled
-// 	ch <- descDaemonSetMetadataGeneration
-// 	ch <- descDaemonSetLabels
-// }
+func daemonSetMetricFamilies(allowedLabels, allowedAnnotations keyAllowList) []metricFamilyDef {
+	return []metricFamilyDef{
+		{
+			Name: "kube_daemonset_created",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapDaemonSetFunc(func(d *v1beta1.DaemonSet) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				if !d.CreationTimestamp.IsZero() {
+					ms = append(ms, &metrics.Metric{
+						Value: float64(d.CreationTimestamp.Unix()),
+					})
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_daemonset_status_current_number_scheduled",
+			Type: metrics.MetricTypeGauge,
+			Help: "The number of nodes running at least one daemon pod and are supposed to.",
+			GenerateFunc: wrapDaemonSetFunc(func(d *v1beta1.DaemonSet) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(d.Status.CurrentNumberScheduled),
+				}}
+			}),
+		},
+		{
+			Name: "kube_daemonset_status_desired_number_scheduled",
+			Type: metrics.MetricTypeGauge,
+			Help: "The number of nodes that should be running the daemon pod.",
+			GenerateFunc: wrapDaemonSetFunc(func(d *v1beta1.DaemonSet) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(d.Status.DesiredNumberScheduled),
+				}}
+			}),
+		},
+		{
+			Name: "kube_daemonset_status_number_available",
+			Type: metrics.MetricTypeGauge,
+			Help: "The number of nodes that should be running the daemon pod and have one or more of the daemon pod running and available",
+			GenerateFunc: wrapDaemonSetFunc(func(d *v1beta1.DaemonSet) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(d.Status.NumberAvailable),
+				}}
+			}),
+		},
+		{
+			Name: "kube_daemonset_status_number_misscheduled",
+			Type: metrics.MetricTypeGauge,
+			Help: "The number of nodes running a daemon pod but are not supposed to.",
+			GenerateFunc: wrapDaemonSetFunc(func(d *v1beta1.DaemonSet) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(d.Status.NumberMisscheduled),
+				}}
+			}),
+		},
+		{
+			Name: "kube_daemonset_status_number_ready",
+			Type: metrics.MetricTypeGauge,
+			Help: "The number of nodes that should be running the daemon pod and have one or more of the daemon pod running and ready.",
+			GenerateFunc: wrapDaemonSetFunc(func(d *v1beta1.DaemonSet) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(d.Status.NumberReady),
+				}}
+			}),
+		},
+		{
+			Name: "kube_daemonset_status_number_unavailable",
+			Type: metrics.MetricTypeGauge,
+			Help: "The number of nodes that should be running the daemon pod and have none of the daemon pod running and available",
+			GenerateFunc: wrapDaemonSetFunc(func(d *v1beta1.DaemonSet) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(d.Status.NumberUnavailable),
+				}}
+			}),
+		},
+		{
+			Name: "kube_daemonset_updated_number_scheduled",
+			Type: metrics.MetricTypeGauge,
+			Help: "The total number of nodes that are running updated daemon pod",
+			GenerateFunc: wrapDaemonSetFunc(func(d *v1beta1.DaemonSet) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(d.Status.UpdatedNumberScheduled),
+				}}
+			}),
+		},
+		{
+			Name: "kube_daemonset_metadata_generation",
+			Type: metrics.MetricTypeGauge,
+			Help: "Sequence number representing a specific generation of the desired state.",
+			GenerateFunc: wrapDaemonSetFunc(func(d *v1beta1.DaemonSet) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(d.ObjectMeta.Generation),
+				}}
+			}),
+		},
+		{
+			Name: descDaemonSetLabelsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descDaemonSetLabelsHelp,
+			GenerateFunc: wrapDaemonSetFunc(func(d *v1beta1.DaemonSet) []*metrics.Metric {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(d.ObjectMeta.Labels, allowedLabels)
+				return []*metrics.Metric{{
+					LabelKeys:   labelKeys,
+					LabelValues: labelValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: descDaemonSetAnnotationsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descDaemonSetAnnotationsHelp,
+			GenerateFunc: wrapDaemonSetFunc(func(d *v1beta1.DaemonSet) []*metrics.Metric {
+				annotationKeys, annotationValues := kubeAnnotationsToPrometheusAnnotations(d.ObjectMeta.Annotations, allowedAnnotations)
+				return []*metrics.Metric{{
+					LabelKeys:   annotationKeys,
+					LabelValues: annotationValues,
+					Value:       1,
+				}}
+			}),
+		},
+	}
+}
 
 func createDaemonSetListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
 	return cache.ListWatch{
@@ -120,46 +174,17 @@ func createDaemonSetListWatch(kubeClient clientset.Interface, ns string) cache.L
 	}
 }
 
-func DaemonSetLabelsDesc(labelKeys []string) *metricFamilyDef {
-	return newMetricFamilyDef(
-		descDaemonSetLabelsName,
-		descDaemonSetLabelsHelp,
-		append(descDaemonSetLabelsDefaultLabels, labelKeys...),
-		nil,
-	)
-}
-
-func generateDaemonSetMetrics(obj interface{}) []*metrics.Metric {
-	ms := []*metrics.Metric{}
+func wrapDaemonSetFunc(f func(*v1beta1.DaemonSet) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		daemonSet := obj.(*v1beta1.DaemonSet)
 
-	// TODO: Refactor
-	dPointer := obj.(*v1beta1.DaemonSet)
-	d := *dPointer
+		ms := f(daemonSet)
 
-	addGauge := func(desc *metricFamilyDef, v float64, lv ...string) {
-		lv = append([]string{d.Namespace, d.Name}, lv...)
-
-		m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, lv, v)
-		if err != nil {
-			panic(err)
+		for _, m := range ms {
+			m.LabelKeys = append(descDaemonSetLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{daemonSet.Namespace, daemonSet.Name}, m.LabelValues...)
 		}
 
-		ms = append(ms, m)
-	}
-	if !d.CreationTimestamp.IsZero() {
-		addGauge(descDaemonSetCreated, float64(d.CreationTimestamp.Unix()))
+		return ms
 	}
-	addGauge(descDaemonSetCurrentNumberScheduled, float64(d.Status.CurrentNumberScheduled))
-	addGauge(descDaemonSetNumberAvailable, float64(d.Status.NumberAvailable))
-	addGauge(descDaemonSetNumberUnavailable, float64(d.Status.NumberUnavailable))
-	addGauge(descDaemonSetNumberMisscheduled, float64(d.Status.NumberMisscheduled))
-	addGauge(descDaemonSetDesiredNumberScheduled, float64(d.Status.DesiredNumberScheduled))
-	addGauge(descDaemonSetNumberReady, float64(d.Status.NumberReady))
-	addGauge(descDaemonSetUpdatedNumberScheduled, float64(d.Status.UpdatedNumberScheduled))
-	addGauge(descDaemonSetMetadataGeneration, float64(d.ObjectMeta.Generation))
-
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(d.ObjectMeta.Labels)
-	addGauge(DaemonSetLabelsDesc(labelKeys), 1, labelValues...)
-
-	return ms
 }
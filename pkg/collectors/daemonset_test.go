@@ -181,7 +181,7 @@ func TestDaemonSetCollector(t *testing.T) {
 		},
 	}
 	for i, c := range cases {
-		c.Func = generateDaemonSetMetrics
+		c.Func = composeMetricGenFuncs(daemonSetMetricFamilies(allKeys, allKeys))
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
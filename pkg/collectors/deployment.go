@@ -33,87 +33,175 @@ var (
 	descDeploymentLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
 	descDeploymentLabelsDefaultLabels = []string{"namespace", "deployment"}
 
-	descDeploymentCreated = newMetricFamilyDef(
-		"kube_deployment_created",
-		"Unix creation timestamp",
-		descDeploymentLabelsDefaultLabels,
-		nil,
-	)
-
-	descDeploymentStatusReplicas = newMetricFamilyDef(
-		"kube_deployment_status_replicas",
-		"The number of replicas per deployment.",
-		descDeploymentLabelsDefaultLabels,
-		nil,
-	)
-	descDeploymentStatusReplicasAvailable = newMetricFamilyDef(
-		"kube_deployment_status_replicas_available",
-		"The number of available replicas per deployment.",
-		descDeploymentLabelsDefaultLabels,
-		nil,
-	)
-	descDeploymentStatusReplicasUnavailable = newMetricFamilyDef(
-		"kube_deployment_status_replicas_unavailable",
-		"The number of unavailable replicas per deployment.",
-		descDeploymentLabelsDefaultLabels,
-		nil,
-	)
-	descDeploymentStatusReplicasUpdated = newMetricFamilyDef(
-		"kube_deployment_status_replicas_updated",
-		"The number of updated replicas per deployment.",
-		descDeploymentLabelsDefaultLabels,
-		nil,
-	)
-
-	descDeploymentStatusObservedGeneration = newMetricFamilyDef(
-		"kube_deployment_status_observed_generation",
-		"The generation observed by the deployment controller.",
-		descDeploymentLabelsDefaultLabels,
-		nil,
-	)
-
-	descDeploymentSpecReplicas = newMetricFamilyDef(
-		"kube_deployment_spec_replicas",
-		"Number of desired pods for a deployment.",
-		descDeploymentLabelsDefaultLabels,
-		nil,
-	)
-
-	descDeploymentSpecPaused = newMetricFamilyDef(
-		"kube_deployment_spec_paused",
-		"Whether the deployment is paused and will not be processed by the deployment controller.",
-		descDeploymentLabelsDefaultLabels,
-		nil,
-	)
-
-	descDeploymentStrategyRollingUpdateMaxUnavailable = newMetricFamilyDef(
-		"kube_deployment_spec_strategy_rollingupdate_max_unavailable",
-		"Maximum number of unavailable replicas during a rolling update of a deployment.",
-		descDeploymentLabelsDefaultLabels,
-		nil,
-	)
-
-	descDeploymentStrategyRollingUpdateMaxSurge = newMetricFamilyDef(
-		"kube_deployment_spec_strategy_rollingupdate_max_surge",
-		"Maximum number of replicas that can be scheduled above the desired number of replicas during a rolling update of a deployment.",
-		descDeploymentLabelsDefaultLabels,
-		nil,
-	)
-
-	descDeploymentMetadataGeneration = newMetricFamilyDef(
-		"kube_deployment_metadata_generation",
-		"Sequence number representing a specific generation of the desired state.",
-		descDeploymentLabelsDefaultLabels,
-		nil,
-	)
-
-	descDeploymentLabels = newMetricFamilyDef(
-		descDeploymentLabelsName,
-		descDeploymentLabelsHelp,
-		descDeploymentLabelsDefaultLabels, nil,
-	)
+	descDeploymentAnnotationsName = "kube_deployment_annotations"
+	descDeploymentAnnotationsHelp = "Kubernetes annotations converted to Prometheus labels."
 )
 
+func deploymentMetricFamilies(allowedLabels, allowedAnnotations keyAllowList) []metricFamilyDef {
+	return []metricFamilyDef{
+		{
+			Name: "kube_deployment_created",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapDeploymentFunc(func(d *v1beta1.Deployment) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				if !d.CreationTimestamp.IsZero() {
+					ms = append(ms, &metrics.Metric{
+						Value: float64(d.CreationTimestamp.Unix()),
+					})
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_deployment_status_replicas",
+			Type: metrics.MetricTypeGauge,
+			Help: "The number of replicas per deployment.",
+			GenerateFunc: wrapDeploymentFunc(func(d *v1beta1.Deployment) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(d.Status.Replicas),
+				}}
+			}),
+		},
+		{
+			Name: "kube_deployment_status_replicas_available",
+			Type: metrics.MetricTypeGauge,
+			Help: "The number of available replicas per deployment.",
+			GenerateFunc: wrapDeploymentFunc(func(d *v1beta1.Deployment) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(d.Status.AvailableReplicas),
+				}}
+			}),
+		},
+		{
+			Name: "kube_deployment_status_replicas_unavailable",
+			Type: metrics.MetricTypeGauge,
+			Help: "The number of unavailable replicas per deployment.",
+			GenerateFunc: wrapDeploymentFunc(func(d *v1beta1.Deployment) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(d.Status.UnavailableReplicas),
+				}}
+			}),
+		},
+		{
+			Name: "kube_deployment_status_replicas_updated",
+			Type: metrics.MetricTypeGauge,
+			Help: "The number of updated replicas per deployment.",
+			GenerateFunc: wrapDeploymentFunc(func(d *v1beta1.Deployment) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(d.Status.UpdatedReplicas),
+				}}
+			}),
+		},
+		{
+			Name: "kube_deployment_status_observed_generation",
+			Type: metrics.MetricTypeGauge,
+			Help: "The generation observed by the deployment controller.",
+			GenerateFunc: wrapDeploymentFunc(func(d *v1beta1.Deployment) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(d.Status.ObservedGeneration),
+				}}
+			}),
+		},
+		{
+			Name: "kube_deployment_spec_replicas",
+			Type: metrics.MetricTypeGauge,
+			Help: "Number of desired pods for a deployment.",
+			GenerateFunc: wrapDeploymentFunc(func(d *v1beta1.Deployment) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(*d.Spec.Replicas),
+				}}
+			}),
+		},
+		{
+			Name: "kube_deployment_spec_paused",
+			Type: metrics.MetricTypeGauge,
+			Help: "Whether the deployment is paused and will not be processed by the deployment controller.",
+			GenerateFunc: wrapDeploymentFunc(func(d *v1beta1.Deployment) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: boolFloat64(d.Spec.Paused),
+				}}
+			}),
+		},
+		{
+			Name: "kube_deployment_spec_strategy_rollingupdate_max_unavailable",
+			Type: metrics.MetricTypeGauge,
+			Help: "Maximum number of unavailable replicas during a rolling update of a deployment.",
+			GenerateFunc: wrapDeploymentFunc(func(d *v1beta1.Deployment) []*metrics.Metric {
+				if d.Spec.Strategy.RollingUpdate == nil {
+					return []*metrics.Metric{}
+				}
+
+				maxUnavailable, err := intstr.GetValueFromIntOrPercent(d.Spec.Strategy.RollingUpdate.MaxUnavailable, int(*d.Spec.Replicas), true)
+				if err != nil {
+					panic(err)
+				}
+
+				return []*metrics.Metric{{
+					Value: float64(maxUnavailable),
+				}}
+			}),
+		},
+		{
+			Name: "kube_deployment_spec_strategy_rollingupdate_max_surge",
+			Type: metrics.MetricTypeGauge,
+			Help: "Maximum number of replicas that can be scheduled above the desired number of replicas during a rolling update of a deployment.",
+			GenerateFunc: wrapDeploymentFunc(func(d *v1beta1.Deployment) []*metrics.Metric {
+				if d.Spec.Strategy.RollingUpdate == nil {
+					return []*metrics.Metric{}
+				}
+
+				maxSurge, err := intstr.GetValueFromIntOrPercent(d.Spec.Strategy.RollingUpdate.MaxSurge, int(*d.Spec.Replicas), true)
+				if err != nil {
+					panic(err)
+				}
+
+				return []*metrics.Metric{{
+					Value: float64(maxSurge),
+				}}
+			}),
+		},
+		{
+			Name: "kube_deployment_metadata_generation",
+			Type: metrics.MetricTypeGauge,
+			Help: "Sequence number representing a specific generation of the desired state.",
+			GenerateFunc: wrapDeploymentFunc(func(d *v1beta1.Deployment) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(d.ObjectMeta.Generation),
+				}}
+			}),
+		},
+		{
+			Name: descDeploymentLabelsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descDeploymentLabelsHelp,
+			GenerateFunc: wrapDeploymentFunc(func(d *v1beta1.Deployment) []*metrics.Metric {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(d.Labels, allowedLabels)
+				return []*metrics.Metric{{
+					LabelKeys:   labelKeys,
+					LabelValues: labelValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: descDeploymentAnnotationsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descDeploymentAnnotationsHelp,
+			GenerateFunc: wrapDeploymentFunc(func(d *v1beta1.Deployment) []*metrics.Metric {
+				annotationKeys, annotationValues := kubeAnnotationsToPrometheusAnnotations(d.Annotations, allowedAnnotations)
+				return []*metrics.Metric{{
+					LabelKeys:   annotationKeys,
+					LabelValues: annotationValues,
+					Value:       1,
+				}}
+			}),
+		},
+	}
+}
+
 func createDeploymentListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
 	return cache.ListWatch{
 		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
@@ -125,64 +213,17 @@ func createDeploymentListWatch(kubeClient clientset.Interface, ns string) cache.
 	}
 }
 
-func deploymentLabelsDesc(labelKeys []string) *metricFamilyDef {
-	return newMetricFamilyDef(
-		descDeploymentLabelsName,
-		descDeploymentLabelsHelp,
-		append(descDeploymentLabelsDefaultLabels, labelKeys...),
-		nil,
-	)
-}
+func wrapDeploymentFunc(f func(*v1beta1.Deployment) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		deployment := obj.(*v1beta1.Deployment)
 
-func generateDeploymentMetrics(obj interface{}) []*metrics.Metric {
-	ms := []*metrics.Metric{}
+		ms := f(deployment)
 
-	// TODO: Refactor
-	dPointer := obj.(*v1beta1.Deployment)
-	d := *dPointer
-
-	addGauge := func(desc *metricFamilyDef, v float64, lv ...string) {
-		lv = append([]string{d.Namespace, d.Name}, lv...)
-
-		m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, lv, v)
-		if err != nil {
-			// TODO: Handle!
-			panic(err)
+		for _, m := range ms {
+			m.LabelKeys = append(descDeploymentLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{deployment.Namespace, deployment.Name}, m.LabelValues...)
 		}
 
-		ms = append(ms, m)
-	}
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(d.Labels)
-	addGauge(deploymentLabelsDesc(labelKeys), 1, labelValues...)
-	if !d.CreationTimestamp.IsZero() {
-		addGauge(descDeploymentCreated, float64(d.CreationTimestamp.Unix()))
+		return ms
 	}
-	addGauge(descDeploymentStatusReplicas, float64(d.Status.Replicas))
-	addGauge(descDeploymentStatusReplicasAvailable, float64(d.Status.AvailableReplicas))
-	addGauge(descDeploymentStatusReplicasUnavailable, float64(d.Status.UnavailableReplicas))
-	addGauge(descDeploymentStatusReplicasUpdated, float64(d.Status.UpdatedReplicas))
-	addGauge(descDeploymentStatusObservedGeneration, float64(d.Status.ObservedGeneration))
-	addGauge(descDeploymentSpecPaused, boolFloat64(d.Spec.Paused))
-	addGauge(descDeploymentSpecReplicas, float64(*d.Spec.Replicas))
-	addGauge(descDeploymentMetadataGeneration, float64(d.ObjectMeta.Generation))
-
-	if d.Spec.Strategy.RollingUpdate == nil {
-		return nil
-	}
-
-	maxUnavailable, err := intstr.GetValueFromIntOrPercent(d.Spec.Strategy.RollingUpdate.MaxUnavailable, int(*d.Spec.Replicas), true)
-	if err != nil {
-		panic(err)
-	} else {
-		addGauge(descDeploymentStrategyRollingUpdateMaxUnavailable, float64(maxUnavailable))
-	}
-
-	maxSurge, err := intstr.GetValueFromIntOrPercent(d.Spec.Strategy.RollingUpdate.MaxSurge, int(*d.Spec.Replicas), true)
-	if err != nil {
-		panic(err)
-	} else {
-		addGauge(descDeploymentStrategyRollingUpdateMaxSurge, float64(maxSurge))
-	}
-
-	return ms
 }
@@ -96,6 +96,7 @@ func TestDeploymentCollector(t *testing.T) {
 			},
 			Want: `
         kube_deployment_created{deployment="depl1",namespace="ns1"} 1.5e+09
+        kube_deployment_annotations{deployment="depl1",namespace="ns1"} 1
         kube_deployment_labels{deployment="depl1",label_app="example1",namespace="ns1"} 1
         kube_deployment_metadata_generation{deployment="depl1",namespace="ns1"} 21
         kube_deployment_spec_paused{deployment="depl1",namespace="ns1"} 0
@@ -138,6 +139,7 @@ func TestDeploymentCollector(t *testing.T) {
 				},
 			},
 			Want: `
+       kube_deployment_annotations{deployment="depl2",namespace="ns2"} 1
        kube_deployment_labels{deployment="depl2",label_app="example2",namespace="ns2"} 1
         kube_deployment_metadata_generation{deployment="depl2",namespace="ns2"} 14
         kube_deployment_spec_paused{deployment="depl2",namespace="ns2"} 1
@@ -154,7 +156,7 @@ func TestDeploymentCollector(t *testing.T) {
 	}
 
 	for i, c := range cases {
-		c.Func = generateDeploymentMetrics
+		c.Func = composeMetricGenFuncs(deploymentMetricFamilies(allKeys, allKeys))
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
@@ -32,42 +32,97 @@ var (
 	descEndpointLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
 	descEndpointLabelsDefaultLabels = []string{"namespace", "endpoint"}
 
-	descEndpointInfo = newMetricFamilyDef(
-		"kube_endpoint_info",
-		"Information about endpoint.",
-		descEndpointLabelsDefaultLabels,
-		nil,
-	)
-
-	descEndpointCreated = newMetricFamilyDef(
-		"kube_endpoint_created",
-		"Unix creation timestamp",
-		descEndpointLabelsDefaultLabels,
-		nil,
-	)
-
-	descEndpointLabels = newMetricFamilyDef(
-		descEndpointLabelsName,
-		descEndpointLabelsHelp,
-		descEndpointLabelsDefaultLabels,
-		nil,
-	)
-
-	descEndpointAddressAvailable = newMetricFamilyDef(
-		"kube_endpoint_address_available",
-		"Number of addresses available in endpoint.",
-		descEndpointLabelsDefaultLabels,
-		nil,
-	)
-
-	descEndpointAddressNotReady = newMetricFamilyDef(
-		"kube_endpoint_address_not_ready",
-		"Number of addresses not ready in endpoint",
-		descEndpointLabelsDefaultLabels,
-		nil,
-	)
+	descEndpointAnnotationsName = "kube_endpoint_annotations"
+	descEndpointAnnotationsHelp = "Kubernetes annotations converted to Prometheus labels."
 )
 
+func endpointMetricFamilies(allowedLabels, allowedAnnotations keyAllowList) []metricFamilyDef {
+	return []metricFamilyDef{
+		{
+			Name: "kube_endpoint_info",
+			Type: metrics.MetricTypeGauge,
+			Help: "Information about endpoint.",
+			GenerateFunc: wrapEndpointFunc(func(e *v1.Endpoints) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: 1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_endpoint_created",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapEndpointFunc(func(e *v1.Endpoints) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				if !e.CreationTimestamp.IsZero() {
+					ms = append(ms, &metrics.Metric{
+						Value: float64(e.CreationTimestamp.Unix()),
+					})
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: descEndpointLabelsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descEndpointLabelsHelp,
+			GenerateFunc: wrapEndpointFunc(func(e *v1.Endpoints) []*metrics.Metric {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(e.Labels, allowedLabels)
+				return []*metrics.Metric{{
+					LabelKeys:   labelKeys,
+					LabelValues: labelValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: descEndpointAnnotationsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descEndpointAnnotationsHelp,
+			GenerateFunc: wrapEndpointFunc(func(e *v1.Endpoints) []*metrics.Metric {
+				annotationKeys, annotationValues := kubeAnnotationsToPrometheusAnnotations(e.Annotations, allowedAnnotations)
+				return []*metrics.Metric{{
+					LabelKeys:   annotationKeys,
+					LabelValues: annotationValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_endpoint_address_available",
+			Type: metrics.MetricTypeGauge,
+			Help: "Number of addresses available in endpoint.",
+			GenerateFunc: wrapEndpointFunc(func(e *v1.Endpoints) []*metrics.Metric {
+				var available int
+				for _, s := range e.Subsets {
+					available += len(s.Addresses) * len(s.Ports)
+				}
+
+				return []*metrics.Metric{{
+					Value: float64(available),
+				}}
+			}),
+		},
+		{
+			Name: "kube_endpoint_address_not_ready",
+			Type: metrics.MetricTypeGauge,
+			Help: "Number of addresses not ready in endpoint",
+			GenerateFunc: wrapEndpointFunc(func(e *v1.Endpoints) []*metrics.Metric {
+				var notReady int
+				for _, s := range e.Subsets {
+					notReady += len(s.NotReadyAddresses) * len(s.Ports)
+				}
+
+				return []*metrics.Metric{{
+					Value: float64(notReady),
+				}}
+			}),
+		},
+	}
+}
+
 func createEndpointsListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
 	return cache.ListWatch{
 		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
@@ -79,54 +134,17 @@ func createEndpointsListWatch(kubeClient clientset.Interface, ns string) cache.L
 	}
 }
 
-func generateEndpointsMetrics(obj interface{}) []*metrics.Metric {
-	ms := []*metrics.Metric{}
-
-	// TODO: Refactor
-	ePointer := obj.(*v1.Endpoints)
-	e := *ePointer
+func wrapEndpointFunc(f func(*v1.Endpoints) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		endpoint := obj.(*v1.Endpoints)
 
-	addConstMetric := func(desc *metricFamilyDef, v float64, lv ...string) {
-		lv = append([]string{e.Namespace, e.Name}, lv...)
+		ms := f(endpoint)
 
-		m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, lv, v)
-		if err != nil {
-			panic(err)
+		for _, m := range ms {
+			m.LabelKeys = append(descEndpointLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{endpoint.Namespace, endpoint.Name}, m.LabelValues...)
 		}
 
-		ms = append(ms, m)
+		return ms
 	}
-	addGauge := func(desc *metricFamilyDef, v float64, lv ...string) {
-		addConstMetric(desc, v, lv...)
-	}
-
-	addGauge(descEndpointInfo, 1)
-	if !e.CreationTimestamp.IsZero() {
-		addGauge(descEndpointCreated, float64(e.CreationTimestamp.Unix()))
-	}
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(e.Labels)
-	addGauge(endpointLabelsDesc(labelKeys), 1, labelValues...)
-
-	var available int
-	for _, s := range e.Subsets {
-		available += len(s.Addresses) * len(s.Ports)
-	}
-	addGauge(descEndpointAddressAvailable, float64(available))
-
-	var notReady int
-	for _, s := range e.Subsets {
-		notReady += len(s.NotReadyAddresses) * len(s.Ports)
-	}
-	addGauge(descEndpointAddressNotReady, float64(notReady))
-
-	return ms
-}
-
-func endpointLabelsDesc(labelKeys []string) *metricFamilyDef {
-	return newMetricFamilyDef(
-		descEndpointLabelsName,
-		descEndpointLabelsHelp,
-		append(descEndpointLabelsDefaultLabels, labelKeys...),
-		nil,
-	)
 }
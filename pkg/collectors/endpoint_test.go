@@ -88,12 +88,13 @@ func TestEndpointCollector(t *testing.T) {
 				kube_endpoint_address_not_ready{endpoint="test-endpoint",namespace="default"} 6
 				kube_endpoint_created{endpoint="test-endpoint",namespace="default"} 1.5e+09
 				kube_endpoint_info{endpoint="test-endpoint",namespace="default"} 1
+				kube_endpoint_annotations{endpoint="test-endpoint",namespace="default"} 1
 				kube_endpoint_labels{endpoint="test-endpoint",label_app="foobar",namespace="default"} 1
 			`,
 		},
 	}
 	for i, c := range cases {
-		c.Func = generateEndpointsMetrics
+		c.Func = composeMetricGenFuncs(endpointMetricFamilies(allKeys, allKeys))
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
@@ -0,0 +1,304 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/kube-state-metrics/pkg/metrics"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	descEventTotalName          = "kube_event_total"
+	descEventTotalHelp          = "Number of occurrences of events, as reported by their count, per involved object, type and reason."
+	descEventTotalDefaultLabels = []string{"namespace", "involved_object_kind", "involved_object_name", "type", "reason"}
+)
+
+func createEventListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
+	return cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return kubeClient.CoreV1().Events(ns).List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return kubeClient.CoreV1().Events(ns).Watch(opts)
+		},
+	}
+}
+
+// eventKey identifies the series of the events counted together.
+type eventKey struct {
+	namespace string
+	kind      string
+	name      string
+	eventType string
+	reason    string
+}
+
+func newEventKey(e *v1.Event) eventKey {
+	return eventKey{
+		namespace: e.Namespace,
+		kind:      e.InvolvedObject.Kind,
+		name:      e.InvolvedObject.Name,
+		eventType: e.Type,
+		reason:    e.Reason,
+	}
+}
+
+// shardKey returns the key used to assign the series of k to a shard. All
+// events counted in a series have to be counted by the same shard, so the
+// series is sharded instead of the individual events.
+func (k eventKey) shardKey() types.UID {
+	return types.UID(strings.Join([]string{k.namespace, k.kind, k.name, k.eventType, k.reason}, "/"))
+}
+
+// eventCount returns the number of occurrences of the given event.
+func eventCount(e *v1.Event) int32 {
+	if e.Series != nil && e.Series.Count > 0 {
+		return e.Series.Count
+	}
+	if e.Count > 0 {
+		return e.Count
+	}
+	return 1
+}
+
+// eventState is the last observed state of a single event.
+type eventState struct {
+	key   eventKey
+	count int32
+}
+
+// eventCounter counts the occurrences of all events sharing an eventKey.
+type eventCounter struct {
+	value       float64
+	lastUpdated time.Time
+}
+
+// eventStore implements the k8s.io/kubernetes/client-go/tools/cache.Store
+// interface. Multiple events can share an involved object, type and reason, so
+// instead of generating metrics per event like the MetricsStore, it sums up
+// their occurrences per eventKey.
+//
+// The counters only ever increase: occurrences are added as the count of an
+// event grows, and the occurrences of deleted events are kept. A counter is
+// only removed once it was not incremented for the retention period, which
+// bounds the memory held for deleted and expired events. Expired counters are
+// removed on scrapes and, at most once per retention period, on changes, so
+// the memory is bounded as well when the metrics are not scraped.
+type eventStore struct {
+	mutex sync.Mutex
+	// events contains the last observed state of each event, indexed by UID.
+	events   map[types.UID]eventState
+	counters map[eventKey]*eventCounter
+
+	retention  time.Duration
+	now        func() time.Time
+	lastPruned time.Time
+
+	shard       int32
+	totalShards int
+}
+
+func newEventStore(retention time.Duration) *eventStore {
+	return &eventStore{
+		events:      map[types.UID]eventState{},
+		counters:    map[eventKey]*eventCounter{},
+		retention:   retention,
+		now:         time.Now,
+		totalShards: 1,
+	}
+}
+
+// WithSharding configures the store to only count the events of the series
+// whose eventKey hashes to the given shard, out of totalShards. As the
+// counters can not be split by shard, changing the sharding resets all
+// counters.
+func (s *eventStore) WithSharding(shard int32, totalShards int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.shard = shard
+	s.totalShards = totalShards
+	s.events = map[types.UID]eventState{}
+	s.counters = map[eventKey]*eventCounter{}
+}
+
+// Add adds the occurrences of the given event since it was last observed to
+// the counter of its eventKey.
+func (s *eventStore) Add(obj interface{}) error {
+	e, ok := obj.(*v1.Event)
+	if !ok {
+		return fmt.Errorf("expected *v1.Event, got %T", obj)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastPruned) >= s.retention {
+		s.prune(now)
+	}
+
+	state := eventState{key: newEventKey(e), count: eventCount(e)}
+
+	if !metricsstore.IsInShard(state.key.shardKey(), s.shard, s.totalShards) {
+		delete(s.events, e.UID)
+		return nil
+	}
+
+	increment := state.count
+	if last, ok := s.events[e.UID]; ok && last.key == state.key {
+		increment = state.count - last.count
+	}
+	s.events[e.UID] = state
+
+	if increment <= 0 {
+		return nil
+	}
+
+	c, ok := s.counters[state.key]
+	if !ok {
+		c = &eventCounter{}
+		s.counters[state.key] = c
+	}
+	c.value += float64(increment)
+	c.lastUpdated = now
+
+	return nil
+}
+
+func (s *eventStore) Update(obj interface{}) error {
+	return s.Add(obj)
+}
+
+// Delete forgets the given event. Its occurrences remain counted.
+func (s *eventStore) Delete(obj interface{}) error {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+
+	o, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.events, o.GetUID())
+
+	return nil
+}
+
+func (s *eventStore) List() []interface{} {
+	return nil
+}
+
+func (s *eventStore) ListKeys() []string {
+	return nil
+}
+
+func (s *eventStore) Get(obj interface{}) (item interface{}, exists bool, err error) {
+	return nil, false, nil
+}
+
+func (s *eventStore) GetByKey(key string) (item interface{}, exists bool, err error) {
+	return nil, false, nil
+}
+
+// Replace forgets all events missing in the given list and adds the others.
+// Events already observed before are not counted twice.
+func (s *eventStore) Replace(list []interface{}, _ string) error {
+	uids := make(map[types.UID]struct{}, len(list))
+	for _, obj := range list {
+		o, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+		uids[o.GetUID()] = struct{}{}
+	}
+
+	s.mutex.Lock()
+	for uid := range s.events {
+		if _, ok := uids[uid]; !ok {
+			delete(s.events, uid)
+		}
+	}
+	s.mutex.Unlock()
+
+	for _, obj := range list {
+		if err := s.Add(obj); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *eventStore) Resync() error {
+	return nil
+}
+
+// prune removes the counters which were not incremented for the retention
+// period.
+func (s *eventStore) prune(now time.Time) {
+	for k, c := range s.counters {
+		if now.Sub(c.lastUpdated) > s.retention {
+			delete(s.counters, k)
+		}
+	}
+	s.lastPruned = now
+}
+
+// WriteAll removes the counters which were not incremented for the retention
+// period and writes the remaining ones to the given writer.
+func (s *eventStore) WriteAll(w io.Writer) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.prune(s.now())
+
+	f := metrics.Family{Name: descEventTotalName}
+	for k, c := range s.counters {
+		f.Metrics = append(f.Metrics, &metrics.Metric{
+			LabelKeys:   descEventTotalDefaultLabels,
+			LabelValues: []string{k.namespace, k.kind, k.name, k.eventType, k.reason},
+			Value:       c.value,
+		})
+	}
+
+	if _, err := io.WriteString(w, string(metrics.NewMetricFamilyDesc(descEventTotalName, descEventTotalHelp, metrics.MetricTypeCounter))); err != nil {
+		return err
+	}
+
+	_, err := w.Write(f.AppendTo(make([]byte, 0, f.Len())))
+	return err
+}
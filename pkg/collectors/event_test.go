@@ -0,0 +1,208 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestEventStore(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	s := newEventStore(time.Hour)
+	s.now = func() time.Time { return now }
+
+	newEvent := func(uid, pod, reason string, count int32) *v1.Event {
+		return &v1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: uid, Namespace: "ns1", UID: types.UID(uid)},
+			InvolvedObject: v1.ObjectReference{Kind: "Pod", Name: pod},
+			Type:           v1.EventTypeWarning,
+			Reason:         reason,
+			Count:          count,
+		}
+	}
+
+	backOff := `kube_event_total{involved_object_kind="Pod",involved_object_name="pod1",namespace="ns1",reason="BackOff",type="Warning"} `
+	failedMount := `kube_event_total{involved_object_kind="Pod",involved_object_name="pod2",namespace="ns1",reason="FailedMount",type="Warning"} `
+
+	steps := []struct {
+		desc  string
+		apply func() error
+		want  []string
+	}{
+		{
+			desc:  "add event",
+			apply: func() error { return s.Add(newEvent("e1", "pod1", "BackOff", 1)) },
+			want:  []string{backOff + "1"},
+		},
+		{
+			desc:  "add event with the same key",
+			apply: func() error { return s.Add(newEvent("e2", "pod1", "BackOff", 3)) },
+			want:  []string{backOff + "4"},
+		},
+		{
+			desc:  "add event with another key",
+			apply: func() error { return s.Add(newEvent("e3", "pod2", "FailedMount", 2)) },
+			want:  []string{backOff + "4", failedMount + "2"},
+		},
+		{
+			desc:  "update count of event",
+			apply: func() error { return s.Update(newEvent("e1", "pod1", "BackOff", 5)) },
+			want:  []string{backOff + "8", failedMount + "2"},
+		},
+		{
+			desc:  "update event without count change",
+			apply: func() error { return s.Update(newEvent("e1", "pod1", "BackOff", 5)) },
+			want:  []string{backOff + "8", failedMount + "2"},
+		},
+		{
+			desc:  "delete event keeps its occurrences",
+			apply: func() error { return s.Delete(newEvent("e1", "pod1", "BackOff", 5)) },
+			want:  []string{backOff + "8", failedMount + "2"},
+		},
+		{
+			desc: "delete tombstone",
+			apply: func() error {
+				return s.Delete(cache.DeletedFinalStateUnknown{Key: "ns1/e3", Obj: newEvent("e3", "pod2", "FailedMount", 2)})
+			},
+			want: []string{backOff + "8", failedMount + "2"},
+		},
+		{
+			desc: "replace does not count observed events twice",
+			apply: func() error {
+				return s.Replace([]interface{}{newEvent("e2", "pod1", "BackOff", 4)}, "")
+			},
+			want: []string{backOff + "9", failedMount + "2"},
+		},
+		{
+			desc: "counters not incremented within the retention period are removed",
+			apply: func() error {
+				now = now.Add(50 * time.Minute)
+				if err := s.Add(newEvent("e2", "pod1", "BackOff", 5)); err != nil {
+					return err
+				}
+				now = now.Add(20 * time.Minute)
+				return nil
+			},
+			want: []string{backOff + "10"},
+		},
+		{
+			desc: "all counters expired",
+			apply: func() error {
+				now = now.Add(time.Hour + time.Second)
+				return nil
+			},
+			want: []string{},
+		},
+	}
+
+	for _, step := range steps {
+		if err := step.apply(); err != nil {
+			t.Fatalf("%s: %v", step.desc, err)
+		}
+
+		if got, want := strings.Join(seriesLines(s), "\n"), strings.Join(step.want, "\n"); got != want {
+			t.Errorf("%s: expected\n%s\ngot\n%s", step.desc, want, got)
+		}
+	}
+
+	if len(s.counters) != 0 {
+		t.Errorf("expected expired counters to be removed, got %d", len(s.counters))
+	}
+	if len(s.events) != 1 {
+		t.Errorf("expected only the event not deleted to be kept, got %d", len(s.events))
+	}
+}
+
+func TestEventStorePruneWithoutScrape(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	s := newEventStore(time.Hour)
+	s.now = func() time.Time { return now }
+
+	for i, reason := range []string{"BackOff", "FailedMount", "Unhealthy"} {
+		e := &v1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Namespace: "ns1", UID: types.UID(reason)},
+			InvolvedObject: v1.ObjectReference{Kind: "Pod", Name: "pod1"},
+			Reason:         reason,
+		}
+		if err := s.Add(e); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(s.counters) != 1 {
+			t.Errorf("step %d: expected expired counters to be removed without scrape, got %d counters", i, len(s.counters))
+		}
+
+		now = now.Add(time.Hour + time.Second)
+	}
+}
+
+func TestEventStoreSharding(t *testing.T) {
+	// Events with distinct UIDs sharing an eventKey have to be counted by the
+	// same shard, otherwise each shard exposes a partial series of its own.
+	events := []*v1.Event{}
+	for i := 0; i < 10; i++ {
+		events = append(events, &v1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Namespace: "ns1", UID: types.UID(fmt.Sprintf("e%d", i))},
+			InvolvedObject: v1.ObjectReference{Kind: "Pod", Name: "pod1"},
+			Type:           v1.EventTypeWarning,
+			Reason:         "BackOff",
+		})
+	}
+
+	totalShards := 2
+	series := []string{}
+	for shard := 0; shard < totalShards; shard++ {
+		s := newEventStore(time.Hour)
+		s.WithSharding(int32(shard), totalShards)
+
+		for _, e := range events {
+			if err := s.Add(e); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		series = append(series, seriesLines(s)...)
+	}
+
+	want := []string{`kube_event_total{involved_object_kind="Pod",involved_object_name="pod1",namespace="ns1",reason="BackOff",type="Warning"} 10`}
+	if !reflect.DeepEqual(series, want) {
+		t.Errorf("expected %v across shards, got %v", want, series)
+	}
+}
+
+func TestEventStoreHeader(t *testing.T) {
+	s := newEventStore(time.Hour)
+	if err := s.Add(&v1.Event{ObjectMeta: metav1.ObjectMeta{UID: "e1"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "# HELP kube_event_total Number of occurrences of events, as reported by their count, per involved object, type and reason.\n" +
+		"# TYPE kube_event_total counter\n" +
+		`kube_event_total{involved_object_kind="",involved_object_name="",namespace="",reason="",type=""} 1` + "\n"
+	if got := writeAll(s); got != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+}
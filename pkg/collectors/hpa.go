@@ -32,50 +32,112 @@ var (
 	descHorizontalPodAutoscalerLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
 	descHorizontalPodAutoscalerLabelsDefaultLabels = []string{"namespace", "hpa"}
 
-	descHorizontalPodAutoscalerMetadataGeneration = newMetricFamilyDef(
-		"kube_hpa_metadata_generation",
-		"The generation observed by the HorizontalPodAutoscaler controller.",
-		descHorizontalPodAutoscalerLabelsDefaultLabels,
-		nil,
-	)
-	descHorizontalPodAutoscalerSpecMaxReplicas = newMetricFamilyDef(
-		"kube_hpa_spec_max_replicas",
-		"Upper limit for the number of pods that can be set by the autoscaler; cannot be smaller than MinReplicas.",
-		descHorizontalPodAutoscalerLabelsDefaultLabels,
-		nil,
-	)
-	descHorizontalPodAutoscalerSpecMinReplicas = newMetricFamilyDef(
-		"kube_hpa_spec_min_replicas",
-		"Lower limit for the number of pods that can be set by the autoscaler, default 1.",
-		descHorizontalPodAutoscalerLabelsDefaultLabels,
-		nil,
-	)
-	descHorizontalPodAutoscalerStatusCurrentReplicas = newMetricFamilyDef(
-		"kube_hpa_status_current_replicas",
-		"Current number of replicas of pods managed by this autoscaler.",
-		descHorizontalPodAutoscalerLabelsDefaultLabels,
-		nil,
-	)
-	descHorizontalPodAutoscalerStatusDesiredReplicas = newMetricFamilyDef(
-		"kube_hpa_status_desired_replicas",
-		"Desired number of replicas of pods managed by this autoscaler.",
-		descHorizontalPodAutoscalerLabelsDefaultLabels,
-		nil,
-	)
-	descHorizontalPodAutoscalerLabels = newMetricFamilyDef(
-		descHorizontalPodAutoscalerLabelsName,
-		descHorizontalPodAutoscalerLabelsHelp,
-		descHorizontalPodAutoscalerLabelsDefaultLabels,
-		nil,
-	)
-	descHorizontalPodAutoscalerCondition = newMetricFamilyDef(
-		"kube_hpa_status_condition",
-		"The condition of this autoscaler.",
-		append(descHorizontalPodAutoscalerLabelsDefaultLabels, "condition", "status"),
-		nil,
-	)
+	descHorizontalPodAutoscalerAnnotationsName = "kube_hpa_annotations"
+	descHorizontalPodAutoscalerAnnotationsHelp = "Kubernetes annotations converted to Prometheus labels."
 )
 
+func hpaMetricFamilies(allowedLabels, allowedAnnotations keyAllowList) []metricFamilyDef {
+	return []metricFamilyDef{
+		{
+			Name: "kube_hpa_metadata_generation",
+			Type: metrics.MetricTypeGauge,
+			Help: "The generation observed by the HorizontalPodAutoscaler controller.",
+			GenerateFunc: wrapHPAFunc(func(a *autoscaling.HorizontalPodAutoscaler) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(a.ObjectMeta.Generation),
+				}}
+			}),
+		},
+		{
+			Name: "kube_hpa_spec_max_replicas",
+			Type: metrics.MetricTypeGauge,
+			Help: "Upper limit for the number of pods that can be set by the autoscaler; cannot be smaller than MinReplicas.",
+			GenerateFunc: wrapHPAFunc(func(a *autoscaling.HorizontalPodAutoscaler) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(a.Spec.MaxReplicas),
+				}}
+			}),
+		},
+		{
+			Name: "kube_hpa_spec_min_replicas",
+			Type: metrics.MetricTypeGauge,
+			Help: "Lower limit for the number of pods that can be set by the autoscaler, default 1.",
+			GenerateFunc: wrapHPAFunc(func(a *autoscaling.HorizontalPodAutoscaler) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(*a.Spec.MinReplicas),
+				}}
+			}),
+		},
+		{
+			Name: "kube_hpa_status_current_replicas",
+			Type: metrics.MetricTypeGauge,
+			Help: "Current number of replicas of pods managed by this autoscaler.",
+			GenerateFunc: wrapHPAFunc(func(a *autoscaling.HorizontalPodAutoscaler) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(a.Status.CurrentReplicas),
+				}}
+			}),
+		},
+		{
+			Name: "kube_hpa_status_desired_replicas",
+			Type: metrics.MetricTypeGauge,
+			Help: "Desired number of replicas of pods managed by this autoscaler.",
+			GenerateFunc: wrapHPAFunc(func(a *autoscaling.HorizontalPodAutoscaler) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(a.Status.DesiredReplicas),
+				}}
+			}),
+		},
+		{
+			Name: descHorizontalPodAutoscalerLabelsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descHorizontalPodAutoscalerLabelsHelp,
+			GenerateFunc: wrapHPAFunc(func(a *autoscaling.HorizontalPodAutoscaler) []*metrics.Metric {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(a.Labels, allowedLabels)
+				return []*metrics.Metric{{
+					LabelKeys:   labelKeys,
+					LabelValues: labelValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: descHorizontalPodAutoscalerAnnotationsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descHorizontalPodAutoscalerAnnotationsHelp,
+			GenerateFunc: wrapHPAFunc(func(a *autoscaling.HorizontalPodAutoscaler) []*metrics.Metric {
+				annotationKeys, annotationValues := kubeAnnotationsToPrometheusAnnotations(a.Annotations, allowedAnnotations)
+				return []*metrics.Metric{{
+					LabelKeys:   annotationKeys,
+					LabelValues: annotationValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_hpa_status_condition",
+			Type: metrics.MetricTypeGauge,
+			Help: "The condition of this autoscaler.",
+			GenerateFunc: wrapHPAFunc(func(a *autoscaling.HorizontalPodAutoscaler) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				for _, c := range a.Status.Conditions {
+					conditionMetrics := addConditionMetrics(c.Status, "status")
+
+					for _, m := range conditionMetrics {
+						m.LabelKeys = append([]string{"condition"}, m.LabelKeys...)
+						m.LabelValues = append([]string{string(c.Type)}, m.LabelValues...)
+					}
+
+					ms = append(ms, conditionMetrics...)
+				}
+
+				return ms
+			}),
+		},
+	}
+}
+
 func createHPAListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
 	return cache.ListWatch{
 		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
@@ -87,44 +149,17 @@ func createHPAListWatch(kubeClient clientset.Interface, ns string) cache.ListWat
 	}
 }
 
-func hpaLabelsDesc(labelKeys []string) *metricFamilyDef {
-	return newMetricFamilyDef(
-		descHorizontalPodAutoscalerLabelsName,
-		descHorizontalPodAutoscalerLabelsHelp,
-		append(descHorizontalPodAutoscalerLabelsDefaultLabels, labelKeys...),
-		nil,
-	)
-}
-
-func generateHPAMetrics(obj interface{}) []*metrics.Metric {
+func wrapHPAFunc(f func(*autoscaling.HorizontalPodAutoscaler) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		hpa := obj.(*autoscaling.HorizontalPodAutoscaler)
 
-	ms := []*metrics.Metric{}
+		ms := f(hpa)
 
-	// TODO: Refactor
-	hPointer := obj.(*autoscaling.HorizontalPodAutoscaler)
-	h := *hPointer
-
-	addGauge := func(desc *metricFamilyDef, v float64, lv ...string) {
-		lv = append([]string{h.Namespace, h.Name}, lv...)
-
-		m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, lv, v)
-		if err != nil {
-			panic(err)
+		for _, m := range ms {
+			m.LabelKeys = append(descHorizontalPodAutoscalerLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{hpa.Namespace, hpa.Name}, m.LabelValues...)
 		}
 
-		ms = append(ms, m)
+		return ms
 	}
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(h.Labels)
-	addGauge(hpaLabelsDesc(labelKeys), 1, labelValues...)
-	addGauge(descHorizontalPodAutoscalerMetadataGeneration, float64(h.ObjectMeta.Generation))
-	addGauge(descHorizontalPodAutoscalerSpecMaxReplicas, float64(h.Spec.MaxReplicas))
-	addGauge(descHorizontalPodAutoscalerSpecMinReplicas, float64(*h.Spec.MinReplicas))
-	addGauge(descHorizontalPodAutoscalerStatusCurrentReplicas, float64(h.Status.CurrentReplicas))
-	addGauge(descHorizontalPodAutoscalerStatusDesiredReplicas, float64(h.Status.DesiredReplicas))
-
-	for _, c := range h.Status.Conditions {
-		ms = append(ms, addConditionMetrics(descHorizontalPodAutoscalerCondition, c.Status, h.Namespace, h.Name, string(c.Type))...)
-	}
-
-	return ms
 }
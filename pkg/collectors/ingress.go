@@ -0,0 +1,167 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"k8s.io/kube-state-metrics/pkg/metrics"
+
+	"k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	descIngressLabelsName          = "kube_ingress_labels"
+	descIngressLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descIngressLabelsDefaultLabels = []string{"namespace", "ingress"}
+
+	descIngressAnnotationsName = "kube_ingress_annotations"
+	descIngressAnnotationsHelp = "Kubernetes annotations converted to Prometheus labels."
+)
+
+func ingressMetricFamilies(allowedLabels, allowedAnnotations keyAllowList) []metricFamilyDef {
+	return []metricFamilyDef{
+		{
+			Name: "kube_ingress_info",
+			Type: metrics.MetricTypeGauge,
+			Help: "Information about ingress.",
+			GenerateFunc: wrapIngressFunc(func(i *v1beta1.Ingress) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: 1,
+				}}
+			}),
+		},
+		{
+			Name: descIngressLabelsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descIngressLabelsHelp,
+			GenerateFunc: wrapIngressFunc(func(i *v1beta1.Ingress) []*metrics.Metric {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(i.Labels, allowedLabels)
+				return []*metrics.Metric{{
+					LabelKeys:   labelKeys,
+					LabelValues: labelValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: descIngressAnnotationsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descIngressAnnotationsHelp,
+			GenerateFunc: wrapIngressFunc(func(i *v1beta1.Ingress) []*metrics.Metric {
+				annotationKeys, annotationValues := kubeAnnotationsToPrometheusAnnotations(i.Annotations, allowedAnnotations)
+				return []*metrics.Metric{{
+					LabelKeys:   annotationKeys,
+					LabelValues: annotationValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_ingress_created",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapIngressFunc(func(i *v1beta1.Ingress) []*metrics.Metric {
+				return createdMetric(i.CreationTimestamp)
+			}),
+		},
+		{
+			Name: "kube_ingress_path",
+			Type: metrics.MetricTypeGauge,
+			Help: "Ingress host, path and backend service information. The default backend has an empty host and path.",
+			GenerateFunc: wrapIngressFunc(func(i *v1beta1.Ingress) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				if b := i.Spec.Backend; b != nil {
+					ms = append(ms, ingressPathMetric("", "", *b))
+				}
+
+				for _, rule := range i.Spec.Rules {
+					if rule.HTTP == nil {
+						continue
+					}
+					for _, path := range rule.HTTP.Paths {
+						ms = append(ms, ingressPathMetric(rule.Host, path.Path, path.Backend))
+					}
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_ingress_tls",
+			Type: metrics.MetricTypeGauge,
+			Help: "Ingress TLS host and secret information. A TLS entry without hosts has an empty host.",
+			GenerateFunc: wrapIngressFunc(func(i *v1beta1.Ingress) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				for _, tls := range i.Spec.TLS {
+					hosts := tls.Hosts
+					if len(hosts) == 0 {
+						hosts = []string{""}
+					}
+					for _, host := range hosts {
+						ms = append(ms, &metrics.Metric{
+							LabelKeys:   []string{"tls_host", "secret"},
+							LabelValues: []string{host, tls.SecretName},
+							Value:       1,
+						})
+					}
+				}
+
+				return ms
+			}),
+		},
+	}
+}
+
+func ingressPathMetric(host, path string, backend v1beta1.IngressBackend) *metrics.Metric {
+	return &metrics.Metric{
+		LabelKeys:   []string{"host", "path", "service", "service_port"},
+		LabelValues: []string{host, path, backend.ServiceName, backend.ServicePort.String()},
+		Value:       1,
+	}
+}
+
+func createIngressListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
+	return cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return kubeClient.ExtensionsV1beta1().Ingresses(ns).List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return kubeClient.ExtensionsV1beta1().Ingresses(ns).Watch(opts)
+		},
+	}
+}
+
+func wrapIngressFunc(f func(*v1beta1.Ingress) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		ingress := obj.(*v1beta1.Ingress)
+
+		ms := f(ingress)
+
+		for _, m := range ms {
+			m.LabelKeys = append(descIngressLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{ingress.Namespace, ingress.Name}, m.LabelValues...)
+		}
+
+		return ms
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestIngressCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_ingress_info Information about ingress.
+		# TYPE kube_ingress_info gauge
+		# HELP kube_ingress_labels Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_ingress_labels gauge
+		# HELP kube_ingress_annotations Kubernetes annotations converted to Prometheus labels.
+		# TYPE kube_ingress_annotations gauge
+		# HELP kube_ingress_created Unix creation timestamp
+		# TYPE kube_ingress_created gauge
+		# HELP kube_ingress_path Ingress host, path and backend service information. The default backend has an empty host and path.
+		# TYPE kube_ingress_path gauge
+		# HELP kube_ingress_tls Ingress TLS host and secret information. A TLS entry without hosts has an empty host.
+		# TYPE kube_ingress_tls gauge
+	`
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &v1beta1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ingress1",
+					Namespace: "ns1",
+				},
+			},
+			Want: `
+				kube_ingress_annotations{ingress="ingress1",namespace="ns1"} 1
+				kube_ingress_info{ingress="ingress1",namespace="ns1"} 1
+				kube_ingress_labels{ingress="ingress1",namespace="ns1"} 1
+			`,
+		},
+		{
+			Obj: &v1beta1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "ingress2",
+					Namespace:         "ns2",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+					Labels: map[string]string{
+						"app": "web",
+					},
+				},
+				Spec: v1beta1.IngressSpec{
+					Backend: &v1beta1.IngressBackend{
+						ServiceName: "default-backend",
+						ServicePort: intstr.FromInt(80),
+					},
+					Rules: []v1beta1.IngressRule{
+						{
+							Host: "example.com",
+							IngressRuleValue: v1beta1.IngressRuleValue{
+								HTTP: &v1beta1.HTTPIngressRuleValue{
+									Paths: []v1beta1.HTTPIngressPath{
+										{
+											Path:    "/",
+											Backend: v1beta1.IngressBackend{ServiceName: "web", ServicePort: intstr.FromString("http")},
+										},
+										{
+											Path:    "/api",
+											Backend: v1beta1.IngressBackend{ServiceName: "api", ServicePort: intstr.FromInt(8080)},
+										},
+									},
+								},
+							},
+						},
+						{
+							Host: "no-paths.example.com",
+						},
+					},
+					TLS: []v1beta1.IngressTLS{
+						{
+							Hosts:      []string{"example.com", "www.example.com"},
+							SecretName: "example-tls",
+						},
+						{
+							SecretName: "wildcard-tls",
+						},
+					},
+				},
+			},
+			Want: `
+				kube_ingress_created{ingress="ingress2",namespace="ns2"} 1.5e+09
+				kube_ingress_labels{ingress="ingress2",label_app="web",namespace="ns2"} 1
+				kube_ingress_path{host="",ingress="ingress2",namespace="ns2",path="",service="default-backend",service_port="80"} 1
+				kube_ingress_path{host="example.com",ingress="ingress2",namespace="ns2",path="/",service="web",service_port="http"} 1
+				kube_ingress_path{host="example.com",ingress="ingress2",namespace="ns2",path="/api",service="api",service_port="8080"} 1
+				kube_ingress_tls{ingress="ingress2",namespace="ns2",secret="example-tls",tls_host="example.com"} 1
+				kube_ingress_tls{ingress="ingress2",namespace="ns2",secret="example-tls",tls_host="www.example.com"} 1
+				kube_ingress_tls{ingress="ingress2",namespace="ns2",secret="wildcard-tls",tls_host=""} 1
+			`,
+			MetricNames: []string{"kube_ingress_created", "kube_ingress_labels", "kube_ingress_path", "kube_ingress_tls"},
+		},
+	}
+	for i, c := range cases {
+		c.Func = composeMetricGenFuncs(ingressMetricFamilies(allKeys, allKeys))
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
@@ -32,164 +32,231 @@ var (
 	descJobLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
 	descJobLabelsDefaultLabels = []string{"namespace", "job_name"}
 
-	descJobLabels = newMetricFamilyDef(
-		descJobLabelsName,
-		descJobLabelsHelp,
-		descJobLabelsDefaultLabels,
-		nil,
-	)
-
-	descJobInfo = newMetricFamilyDef(
-		"kube_job_info",
-		"Information about job.",
-		descJobLabelsDefaultLabels,
-		nil,
-	)
-	descJobCreated = newMetricFamilyDef(
-		"kube_job_created",
-		"Unix creation timestamp",
-		descJobLabelsDefaultLabels,
-		nil,
-	)
-	descJobSpecParallelism = newMetricFamilyDef(
-		"kube_job_spec_parallelism",
-		"The maximum desired number of pods the job should run at any given time.",
-		descJobLabelsDefaultLabels,
-		nil,
-	)
-	descJobSpecCompletions = newMetricFamilyDef(
-		"kube_job_spec_completions",
-		"The desired number of successfully finished pods the job should be run with.",
-		descJobLabelsDefaultLabels,
-		nil,
-	)
-	descJobSpecActiveDeadlineSeconds = newMetricFamilyDef(
-		"kube_job_spec_active_deadline_seconds",
-		"The duration in seconds relative to the startTime that the job may be active before the system tries to terminate it.",
-		descJobLabelsDefaultLabels,
-		nil,
-	)
-	descJobStatusSucceeded = newMetricFamilyDef(
-		"kube_job_status_succeeded",
-		"The number of pods which reached Phase Succeeded.",
-		descJobLabelsDefaultLabels,
-		nil,
-	)
-	descJobStatusFailed = newMetricFamilyDef(
-		"kube_job_status_failed",
-		"The number of pods which reached Phase Failed.",
-		descJobLabelsDefaultLabels,
-		nil,
-	)
-	descJobStatusActive = newMetricFamilyDef(
-		"kube_job_status_active",
-		"The number of actively running pods.",
-		descJobLabelsDefaultLabels,
-		nil,
-	)
-	descJobConditionComplete = newMetricFamilyDef(
-		"kube_job_complete",
-		"The job has completed its execution.",
-		append(descJobLabelsDefaultLabels, "condition"),
-		nil,
-	)
-	descJobConditionFailed = newMetricFamilyDef(
-		"kube_job_failed",
-		"The job has failed its execution.",
-		append(descJobLabelsDefaultLabels, "condition"),
-		nil,
-	)
-	descJobStatusStartTime = newMetricFamilyDef(
-		"kube_job_status_start_time",
-		"StartTime represents time when the job was acknowledged by the Job Manager.",
-		descJobLabelsDefaultLabels,
-		nil,
-	)
-	descJobStatusCompletionTime = newMetricFamilyDef(
-		"kube_job_status_completion_time",
-		"CompletionTime represents time when the job was completed.",
-		descJobLabelsDefaultLabels,
-		nil,
-	)
+	descJobAnnotationsName = "kube_job_annotations"
+	descJobAnnotationsHelp = "Kubernetes annotations converted to Prometheus labels."
 )
 
-func createJobListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
-	return cache.ListWatch{
-		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
-			return kubeClient.BatchV1().Jobs(ns).List(opts)
+func jobMetricFamilies(allowedLabels, allowedAnnotations keyAllowList) []metricFamilyDef {
+	return []metricFamilyDef{
+		{
+			Name: descJobLabelsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descJobLabelsHelp,
+			GenerateFunc: wrapJobFunc(func(j *v1batch.Job) []*metrics.Metric {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(j.Labels, allowedLabels)
+				return []*metrics.Metric{{
+					LabelKeys:   labelKeys,
+					LabelValues: labelValues,
+					Value:       1,
+				}}
+			}),
 		},
-		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
-			return kubeClient.BatchV1().Jobs(ns).Watch(opts)
+		{
+			Name: descJobAnnotationsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descJobAnnotationsHelp,
+			GenerateFunc: wrapJobFunc(func(j *v1batch.Job) []*metrics.Metric {
+				annotationKeys, annotationValues := kubeAnnotationsToPrometheusAnnotations(j.Annotations, allowedAnnotations)
+				return []*metrics.Metric{{
+					LabelKeys:   annotationKeys,
+					LabelValues: annotationValues,
+					Value:       1,
+				}}
+			}),
 		},
-	}
-}
+		{
+			Name: "kube_job_info",
+			Type: metrics.MetricTypeGauge,
+			Help: "Information about job.",
+			GenerateFunc: wrapJobFunc(func(j *v1batch.Job) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: 1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_job_created",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapJobFunc(func(j *v1batch.Job) []*metrics.Metric {
+				ms := []*metrics.Metric{}
 
-func jobLabelsDesc(labelKeys []string) *metricFamilyDef {
-	return newMetricFamilyDef(
-		descJobLabelsName,
-		descJobLabelsHelp,
-		append(descJobLabelsDefaultLabels, labelKeys...),
-		nil,
-	)
-}
+				if !j.CreationTimestamp.IsZero() {
+					ms = append(ms, &metrics.Metric{
+						Value: float64(j.CreationTimestamp.Unix()),
+					})
+				}
 
-func generateJobMetrics(obj interface{}) []*metrics.Metric {
-	ms := []*metrics.Metric{}
+				return ms
+			}),
+		},
+		{
+			Name: "kube_job_spec_parallelism",
+			Type: metrics.MetricTypeGauge,
+			Help: "The maximum desired number of pods the job should run at any given time.",
+			GenerateFunc: wrapJobFunc(func(j *v1batch.Job) []*metrics.Metric {
+				ms := []*metrics.Metric{}
 
-	// TODO: Refactor
-	jPointer := obj.(*v1batch.Job)
-	j := *jPointer
+				if j.Spec.Parallelism != nil {
+					ms = append(ms, &metrics.Metric{
+						Value: float64(*j.Spec.Parallelism),
+					})
+				}
 
-	addGauge := func(desc *metricFamilyDef, v float64, lv ...string) {
-		lv = append([]string{j.Namespace, j.Name}, lv...)
+				return ms
+			}),
+		},
+		{
+			Name: "kube_job_spec_completions",
+			Type: metrics.MetricTypeGauge,
+			Help: "The desired number of successfully finished pods the job should be run with.",
+			GenerateFunc: wrapJobFunc(func(j *v1batch.Job) []*metrics.Metric {
+				ms := []*metrics.Metric{}
 
-		m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, lv, v)
-		if err != nil {
-			panic(err)
-		}
+				if j.Spec.Completions != nil {
+					ms = append(ms, &metrics.Metric{
+						Value: float64(*j.Spec.Completions),
+					})
+				}
 
-		ms = append(ms, m)
-	}
+				return ms
+			}),
+		},
+		{
+			Name: "kube_job_spec_active_deadline_seconds",
+			Type: metrics.MetricTypeGauge,
+			Help: "The duration in seconds relative to the startTime that the job may be active before the system tries to terminate it.",
+			GenerateFunc: wrapJobFunc(func(j *v1batch.Job) []*metrics.Metric {
+				ms := []*metrics.Metric{}
 
-	addGauge(descJobInfo, 1)
+				if j.Spec.ActiveDeadlineSeconds != nil {
+					ms = append(ms, &metrics.Metric{
+						Value: float64(*j.Spec.ActiveDeadlineSeconds),
+					})
+				}
 
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(j.Labels)
-	addGauge(jobLabelsDesc(labelKeys), 1, labelValues...)
+				return ms
+			}),
+		},
+		{
+			Name: "kube_job_status_succeeded",
+			Type: metrics.MetricTypeGauge,
+			Help: "The number of pods which reached Phase Succeeded.",
+			GenerateFunc: wrapJobFunc(func(j *v1batch.Job) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(j.Status.Succeeded),
+				}}
+			}),
+		},
+		{
+			Name: "kube_job_status_failed",
+			Type: metrics.MetricTypeGauge,
+			Help: "The number of pods which reached Phase Failed.",
+			GenerateFunc: wrapJobFunc(func(j *v1batch.Job) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(j.Status.Failed),
+				}}
+			}),
+		},
+		{
+			Name: "kube_job_status_active",
+			Type: metrics.MetricTypeGauge,
+			Help: "The number of actively running pods.",
+			GenerateFunc: wrapJobFunc(func(j *v1batch.Job) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(j.Status.Active),
+				}}
+			}),
+		},
+		{
+			Name: "kube_job_complete",
+			Type: metrics.MetricTypeGauge,
+			Help: "The job has completed its execution.",
+			GenerateFunc: wrapJobFunc(func(j *v1batch.Job) []*metrics.Metric {
+				ms := []*metrics.Metric{}
 
-	if j.Spec.Parallelism != nil {
-		addGauge(descJobSpecParallelism, float64(*j.Spec.Parallelism))
-	}
+				for _, c := range j.Status.Conditions {
+					if c.Type == v1batch.JobComplete {
+						ms = append(ms, addConditionMetrics(c.Status, "condition")...)
+					}
+				}
 
-	if j.Spec.Completions != nil {
-		addGauge(descJobSpecCompletions, float64(*j.Spec.Completions))
-	}
-	if !j.CreationTimestamp.IsZero() {
-		addGauge(descJobCreated, float64(j.CreationTimestamp.Unix()))
-	}
+				return ms
+			}),
+		},
+		{
+			Name: "kube_job_failed",
+			Type: metrics.MetricTypeGauge,
+			Help: "The job has failed its execution.",
+			GenerateFunc: wrapJobFunc(func(j *v1batch.Job) []*metrics.Metric {
+				ms := []*metrics.Metric{}
 
-	if j.Spec.ActiveDeadlineSeconds != nil {
-		addGauge(descJobSpecActiveDeadlineSeconds, float64(*j.Spec.ActiveDeadlineSeconds))
-	}
+				for _, c := range j.Status.Conditions {
+					if c.Type == v1batch.JobFailed {
+						ms = append(ms, addConditionMetrics(c.Status, "condition")...)
+					}
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_job_status_start_time",
+			Type: metrics.MetricTypeGauge,
+			Help: "StartTime represents time when the job was acknowledged by the Job Manager.",
+			GenerateFunc: wrapJobFunc(func(j *v1batch.Job) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				if j.Status.StartTime != nil {
+					ms = append(ms, &metrics.Metric{
+						Value: float64(j.Status.StartTime.Unix()),
+					})
+				}
 
-	addGauge(descJobStatusSucceeded, float64(j.Status.Succeeded))
-	addGauge(descJobStatusFailed, float64(j.Status.Failed))
-	addGauge(descJobStatusActive, float64(j.Status.Active))
+				return ms
+			}),
+		},
+		{
+			Name: "kube_job_status_completion_time",
+			Type: metrics.MetricTypeGauge,
+			Help: "CompletionTime represents time when the job was completed.",
+			GenerateFunc: wrapJobFunc(func(j *v1batch.Job) []*metrics.Metric {
+				ms := []*metrics.Metric{}
 
-	if j.Status.StartTime != nil {
-		addGauge(descJobStatusStartTime, float64(j.Status.StartTime.Unix()))
+				if j.Status.CompletionTime != nil {
+					ms = append(ms, &metrics.Metric{
+						Value: float64(j.Status.CompletionTime.Unix()),
+					})
+				}
+
+				return ms
+			}),
+		},
 	}
+}
 
-	if j.Status.CompletionTime != nil {
-		addGauge(descJobStatusCompletionTime, float64(j.Status.CompletionTime.Unix()))
+func createJobListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
+	return cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return kubeClient.BatchV1().Jobs(ns).List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return kubeClient.BatchV1().Jobs(ns).Watch(opts)
+		},
 	}
+}
+
+func wrapJobFunc(f func(*v1batch.Job) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		job := obj.(*v1batch.Job)
 
-	for _, c := range j.Status.Conditions {
-		switch c.Type {
-		case v1batch.JobComplete:
-			ms = append(ms, addConditionMetrics(descJobConditionComplete, c.Status, j.Namespace, j.Name)...)
-		case v1batch.JobFailed:
-			ms = append(ms, addConditionMetrics(descJobConditionFailed, c.Status, j.Namespace, j.Name)...)
+		ms := f(job)
+
+		for _, m := range ms {
+			m.LabelKeys = append(descJobLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{job.Namespace, job.Name}, m.LabelValues...)
 		}
+
+		return ms
 	}
-	return ms
 }
@@ -99,6 +99,7 @@ func TestJobCollector(t *testing.T) {
 			Want: `
 				kube_job_created{job_name="RunningJob1",namespace="ns1"} 1.5e+09
 				kube_job_info{job_name="RunningJob1",namespace="ns1"} 1
+				kube_job_annotations{job_name="RunningJob1",namespace="ns1"} 1
 				kube_job_labels{job_name="RunningJob1",label_app="example-running-1",namespace="ns1"} 1
 				kube_job_spec_active_deadline_seconds{job_name="RunningJob1",namespace="ns1"} 900
 				kube_job_spec_completions{job_name="RunningJob1",namespace="ns1"} 1
@@ -140,6 +141,7 @@ func TestJobCollector(t *testing.T) {
 				kube_job_complete{condition="true",job_name="SuccessfulJob1",namespace="ns1"} 1
 				kube_job_complete{condition="unknown",job_name="SuccessfulJob1",namespace="ns1"} 0
 				kube_job_info{job_name="SuccessfulJob1",namespace="ns1"} 1
+				kube_job_annotations{job_name="SuccessfulJob1",namespace="ns1"} 1
 				kube_job_labels{job_name="SuccessfulJob1",label_app="example-successful-1",namespace="ns1"} 1
 				kube_job_spec_active_deadline_seconds{job_name="SuccessfulJob1",namespace="ns1"} 900
 				kube_job_spec_completions{job_name="SuccessfulJob1",namespace="ns1"} 1
@@ -182,6 +184,7 @@ func TestJobCollector(t *testing.T) {
 				kube_job_failed{condition="true",job_name="FailedJob1",namespace="ns1"} 1
 				kube_job_failed{condition="unknown",job_name="FailedJob1",namespace="ns1"} 0
 				kube_job_info{job_name="FailedJob1",namespace="ns1"} 1
+				kube_job_annotations{job_name="FailedJob1",namespace="ns1"} 1
 				kube_job_labels{job_name="FailedJob1",label_app="example-failed-1",namespace="ns1"} 1
 				kube_job_spec_active_deadline_seconds{job_name="FailedJob1",namespace="ns1"} 900
 				kube_job_spec_completions{job_name="FailedJob1",namespace="ns1"} 1
@@ -225,6 +228,7 @@ func TestJobCollector(t *testing.T) {
 
 				kube_job_complete{condition="unknown",job_name="SuccessfulJob2NoActiveDeadlineSeconds",namespace="ns1"} 0
 				kube_job_info{job_name="SuccessfulJob2NoActiveDeadlineSeconds",namespace="ns1"} 1
+				kube_job_annotations{job_name="SuccessfulJob2NoActiveDeadlineSeconds",namespace="ns1"} 1
 				kube_job_labels{job_name="SuccessfulJob2NoActiveDeadlineSeconds",label_app="example-successful-2",namespace="ns1"} 1
 				kube_job_spec_completions{job_name="SuccessfulJob2NoActiveDeadlineSeconds",namespace="ns1"} 1
 				kube_job_spec_parallelism{job_name="SuccessfulJob2NoActiveDeadlineSeconds",namespace="ns1"} 1
@@ -237,7 +241,7 @@ func TestJobCollector(t *testing.T) {
 		},
 	}
 	for i, c := range cases {
-		c.Func = generateJobMetrics
+		c.Func = composeMetricGenFuncs(jobMetricFamilies(allKeys, allKeys))
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
@@ -0,0 +1,174 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"k8s.io/kube-state-metrics/pkg/metrics"
+
+	coordinationv1beta1 "k8s.io/api/coordination/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	descLeaseLabelsName          = "kube_lease_labels"
+	descLeaseLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descLeaseLabelsDefaultLabels = []string{"namespace", "lease"}
+
+	descLeaseAnnotationsName = "kube_lease_annotations"
+	descLeaseAnnotationsHelp = "Kubernetes annotations converted to Prometheus labels."
+)
+
+func leaseMetricFamilies(allowedLabels, allowedAnnotations keyAllowList) []metricFamilyDef {
+	return []metricFamilyDef{
+		{
+			Name: descLeaseLabelsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descLeaseLabelsHelp,
+			GenerateFunc: wrapLeaseFunc(func(l *coordinationv1beta1.Lease) []*metrics.Metric {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(l.Labels, allowedLabels)
+				return []*metrics.Metric{{
+					LabelKeys:   labelKeys,
+					LabelValues: labelValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: descLeaseAnnotationsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descLeaseAnnotationsHelp,
+			GenerateFunc: wrapLeaseFunc(func(l *coordinationv1beta1.Lease) []*metrics.Metric {
+				annotationKeys, annotationValues := kubeAnnotationsToPrometheusAnnotations(l.Annotations, allowedAnnotations)
+				return []*metrics.Metric{{
+					LabelKeys:   annotationKeys,
+					LabelValues: annotationValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_lease_created",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapLeaseFunc(func(l *coordinationv1beta1.Lease) []*metrics.Metric {
+				return createdMetric(l.CreationTimestamp)
+			}),
+		},
+		{
+			Name: "kube_lease_holder",
+			Type: metrics.MetricTypeGauge,
+			Help: "Identity of the current holder of the lease.",
+			GenerateFunc: wrapLeaseFunc(func(l *coordinationv1beta1.Lease) []*metrics.Metric {
+				if l.Spec.HolderIdentity == nil || *l.Spec.HolderIdentity == "" {
+					return []*metrics.Metric{}
+				}
+
+				return []*metrics.Metric{{
+					LabelKeys:   []string{"holder_identity"},
+					LabelValues: []string{*l.Spec.HolderIdentity},
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_lease_renew_time",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix timestamp of the last renewal of the lease by its holder.",
+			GenerateFunc: wrapLeaseFunc(func(l *coordinationv1beta1.Lease) []*metrics.Metric {
+				return microTimeMetric(l.Spec.RenewTime)
+			}),
+		},
+		{
+			Name: "kube_lease_acquire_time",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix timestamp of the acquisition of the lease by its current holder.",
+			GenerateFunc: wrapLeaseFunc(func(l *coordinationv1beta1.Lease) []*metrics.Metric {
+				return microTimeMetric(l.Spec.AcquireTime)
+			}),
+		},
+		{
+			Name: "kube_lease_duration_seconds",
+			Type: metrics.MetricTypeGauge,
+			Help: "Duration candidates for the lease wait after its last renewal to force acquire it.",
+			GenerateFunc: wrapLeaseFunc(func(l *coordinationv1beta1.Lease) []*metrics.Metric {
+				if l.Spec.LeaseDurationSeconds == nil {
+					return []*metrics.Metric{}
+				}
+
+				return []*metrics.Metric{{
+					Value: float64(*l.Spec.LeaseDurationSeconds),
+				}}
+			}),
+		},
+		{
+			Name: "kube_lease_transitions",
+			Type: metrics.MetricTypeGauge,
+			Help: "Number of transitions of the lease between holders.",
+			GenerateFunc: wrapLeaseFunc(func(l *coordinationv1beta1.Lease) []*metrics.Metric {
+				if l.Spec.LeaseTransitions == nil {
+					return []*metrics.Metric{}
+				}
+
+				return []*metrics.Metric{{
+					Value: float64(*l.Spec.LeaseTransitions),
+				}}
+			}),
+		},
+	}
+}
+
+// microTimeMetric returns the given time as fractional unix timestamp, or no
+// metric if it is not set.
+func microTimeMetric(t *metav1.MicroTime) []*metrics.Metric {
+	if t == nil || t.IsZero() {
+		return []*metrics.Metric{}
+	}
+
+	return []*metrics.Metric{{
+		Value: float64(t.UnixNano()) / 1e9,
+	}}
+}
+
+func createLeaseListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
+	return cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return kubeClient.CoordinationV1beta1().Leases(ns).List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return kubeClient.CoordinationV1beta1().Leases(ns).Watch(opts)
+		},
+	}
+}
+
+func wrapLeaseFunc(f func(*coordinationv1beta1.Lease) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		lease := obj.(*coordinationv1beta1.Lease)
+
+		ms := f(lease)
+
+		for _, m := range ms {
+			m.LabelKeys = append(descLeaseLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{lease.Namespace, lease.Name}, m.LabelValues...)
+		}
+
+		return ms
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+	"time"
+
+	coordinationv1beta1 "k8s.io/api/coordination/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLeaseCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_lease_labels Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_lease_labels gauge
+		# HELP kube_lease_annotations Kubernetes annotations converted to Prometheus labels.
+		# TYPE kube_lease_annotations gauge
+		# HELP kube_lease_created Unix creation timestamp
+		# TYPE kube_lease_created gauge
+		# HELP kube_lease_holder Identity of the current holder of the lease.
+		# TYPE kube_lease_holder gauge
+		# HELP kube_lease_renew_time Unix timestamp of the last renewal of the lease by its holder.
+		# TYPE kube_lease_renew_time gauge
+		# HELP kube_lease_acquire_time Unix timestamp of the acquisition of the lease by its current holder.
+		# TYPE kube_lease_acquire_time gauge
+		# HELP kube_lease_duration_seconds Duration candidates for the lease wait after its last renewal to force acquire it.
+		# TYPE kube_lease_duration_seconds gauge
+		# HELP kube_lease_transitions Number of transitions of the lease between holders.
+		# TYPE kube_lease_transitions gauge
+	`
+
+	var (
+		holder      = "node-1"
+		duration    = int32(40)
+		transitions = int32(3)
+	)
+
+	cases := []generateMetricsTestCase{
+		{
+			// Node heartbeat lease.
+			Obj: &coordinationv1beta1.Lease{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "node-1",
+					Namespace:         "kube-node-lease",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+					Labels: map[string]string{
+						"app": "foobar",
+					},
+				},
+				Spec: coordinationv1beta1.LeaseSpec{
+					HolderIdentity:       &holder,
+					LeaseDurationSeconds: &duration,
+					AcquireTime:          &metav1.MicroTime{Time: time.Unix(1500000100, 0)},
+					RenewTime:            &metav1.MicroTime{Time: time.Unix(1500000200, 500000000)},
+					LeaseTransitions:     &transitions,
+				},
+			},
+			Want: `
+				kube_lease_acquire_time{lease="node-1",namespace="kube-node-lease"} 1.5000001e+09
+				kube_lease_created{lease="node-1",namespace="kube-node-lease"} 1.5e+09
+				kube_lease_duration_seconds{lease="node-1",namespace="kube-node-lease"} 40
+				kube_lease_holder{holder_identity="node-1",lease="node-1",namespace="kube-node-lease"} 1
+				kube_lease_labels{label_app="foobar",lease="node-1",namespace="kube-node-lease"} 1
+				kube_lease_renew_time{lease="node-1",namespace="kube-node-lease"} 1.5000002005e+09
+				kube_lease_transitions{lease="node-1",namespace="kube-node-lease"} 3
+			`,
+			MetricNames: []string{
+				"kube_lease_acquire_time",
+				"kube_lease_created",
+				"kube_lease_duration_seconds",
+				"kube_lease_holder",
+				"kube_lease_labels",
+				"kube_lease_renew_time",
+				"kube_lease_transitions",
+			},
+		},
+		{
+			// Released lease without holder.
+			Obj: &coordinationv1beta1.Lease{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "kube-controller-manager",
+					Namespace: "kube-system",
+				},
+			},
+			Want: `
+				kube_lease_labels{lease="kube-controller-manager",namespace="kube-system"} 1
+			`,
+			MetricNames: []string{
+				"kube_lease_acquire_time",
+				"kube_lease_duration_seconds",
+				"kube_lease_holder",
+				"kube_lease_labels",
+				"kube_lease_renew_time",
+				"kube_lease_transitions",
+			},
+		},
+	}
+	for i, c := range cases {
+		c.Func = composeMetricGenFuncs(leaseMetricFamilies(allKeys, allKeys))
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
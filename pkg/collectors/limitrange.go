@@ -29,19 +29,66 @@ import (
 
 var (
 	descLimitRangeLabelsDefaultLabels = []string{"limitrange", "namespace"}
-	descLimitRange                    = newMetricFamilyDef(
-		"kube_limitrange",
-		"Information about limit range.",
-		append(descLimitRangeLabelsDefaultLabels, "resource", "type", "constraint"),
-		nil,
-	)
-
-	descLimitRangeCreated = newMetricFamilyDef(
-		"kube_limitrange_created",
-		"Unix creation timestamp",
-		descLimitRangeLabelsDefaultLabels,
-		nil,
-	)
+
+	limitRangeMetricFamilies = []metricFamilyDef{
+		{
+			Name: "kube_limitrange",
+			Type: metrics.MetricTypeGauge,
+			Help: "Information about limit range.",
+			GenerateFunc: wrapLimitRangeFunc(func(r *v1.LimitRange) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				addLimit := func(resource v1.ResourceName, limitType v1.LimitType, constraint string, value float64) {
+					ms = append(ms, &metrics.Metric{
+						LabelKeys:   []string{"resource", "type", "constraint"},
+						LabelValues: []string{string(resource), string(limitType), constraint},
+						Value:       value,
+					})
+				}
+
+				rawLimitRanges := r.Spec.Limits
+				for _, rawLimitRange := range rawLimitRanges {
+					for resource, min := range rawLimitRange.Min {
+						addLimit(resource, rawLimitRange.Type, "min", float64(min.MilliValue())/1000)
+					}
+
+					for resource, max := range rawLimitRange.Max {
+						addLimit(resource, rawLimitRange.Type, "max", float64(max.MilliValue())/1000)
+					}
+
+					for resource, df := range rawLimitRange.Default {
+						addLimit(resource, rawLimitRange.Type, "default", float64(df.MilliValue())/1000)
+					}
+
+					for resource, dfR := range rawLimitRange.DefaultRequest {
+						addLimit(resource, rawLimitRange.Type, "defaultRequest", float64(dfR.MilliValue())/1000)
+					}
+
+					for resource, mLR := range rawLimitRange.MaxLimitRequestRatio {
+						addLimit(resource, rawLimitRange.Type, "maxLimitRequestRatio", float64(mLR.MilliValue())/1000)
+					}
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_limitrange_created",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapLimitRangeFunc(func(r *v1.LimitRange) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				if !r.CreationTimestamp.IsZero() {
+					ms = append(ms, &metrics.Metric{
+						Value: float64(r.CreationTimestamp.Unix()),
+					})
+				}
+
+				return ms
+			}),
+		},
+	}
 )
 
 func createLimitRangeListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
@@ -54,50 +101,18 @@ func createLimitRangeListWatch(kubeClient clientset.Interface, ns string) cache.
 		},
 	}
 }
-func generateLimitRangeMetrics(obj interface{}) []*metrics.Metric {
-	ms := []*metrics.Metric{}
-
-	// TODO: Refactor
-	lPointer := obj.(*v1.LimitRange)
-	l := *lPointer
-
-	addGauge := func(desc *metricFamilyDef, v float64, lv ...string) {
-		lv = append([]string{l.Name, l.Namespace}, lv...)
-
-		m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, lv, v)
-		if err != nil {
-			panic(err)
-		}
-
-		ms = append(ms, m)
-	}
-	if !l.CreationTimestamp.IsZero() {
-		addGauge(descLimitRangeCreated, float64(l.CreationTimestamp.Unix()))
-	}
 
-	rawLimitRanges := l.Spec.Limits
-	for _, rawLimitRange := range rawLimitRanges {
-		for resource, min := range rawLimitRange.Min {
-			addGauge(descLimitRange, float64(min.MilliValue())/1000, string(resource), string(rawLimitRange.Type), "min")
-		}
+func wrapLimitRangeFunc(f func(*v1.LimitRange) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		limitRange := obj.(*v1.LimitRange)
 
-		for resource, max := range rawLimitRange.Max {
-			addGauge(descLimitRange, float64(max.MilliValue())/1000, string(resource), string(rawLimitRange.Type), "max")
-		}
+		ms := f(limitRange)
 
-		for resource, df := range rawLimitRange.Default {
-			addGauge(descLimitRange, float64(df.MilliValue())/1000, string(resource), string(rawLimitRange.Type), "default")
-		}
-
-		for resource, dfR := range rawLimitRange.DefaultRequest {
-			addGauge(descLimitRange, float64(dfR.MilliValue())/1000, string(resource), string(rawLimitRange.Type), "defaultRequest")
-		}
-
-		for resource, mLR := range rawLimitRange.MaxLimitRequestRatio {
-			addGauge(descLimitRange, float64(mLR.MilliValue())/1000, string(resource), string(rawLimitRange.Type), "maxLimitRequestRatio")
+		for _, m := range ms {
+			m.LabelKeys = append(descLimitRangeLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{limitRange.Name, limitRange.Namespace}, m.LabelValues...)
 		}
 
+		return ms
 	}
-
-	return ms
 }
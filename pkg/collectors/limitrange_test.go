@@ -79,7 +79,7 @@ func TestLimitRangeollector(t *testing.T) {
 		},
 	}
 	for i, c := range cases {
-		c.Func = generateLimitRangeMetrics
+		c.Func = composeMetricGenFuncs(limitRangeMetricFamilies)
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
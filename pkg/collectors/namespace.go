@@ -32,36 +32,76 @@ var (
 	descNamespaceLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
 	descNamespaceLabelsDefaultLabels = []string{"namespace"}
 
-	descNamespaceAnnotationsName          = "kube_namespace_annotations"
-	descNamespaceAnnotationsHelp          = "Kubernetes annotations converted to Prometheus labels."
-	descNamespaceAnnotationsDefaultLabels = []string{"namespace"}
-
-	descNamespaceCreated = newMetricFamilyDef(
-		"kube_namespace_created",
-		"Unix creation timestamp",
-		descNamespaceLabelsDefaultLabels,
-		nil,
-	)
-	descNamespaceLabels = newMetricFamilyDef(
-		descNamespaceLabelsName,
-		descNamespaceLabelsHelp,
-		descNamespaceLabelsDefaultLabels,
-		nil,
-	)
-	descNamespaceAnnotations = newMetricFamilyDef(
-		descNamespaceAnnotationsName,
-		descNamespaceAnnotationsHelp,
-		descNamespaceAnnotationsDefaultLabels,
-		nil,
-	)
-	descNamespacePhase = newMetricFamilyDef(
-		"kube_namespace_status_phase",
-		"kubernetes namespace status phase.",
-		append(descNamespaceLabelsDefaultLabels, "phase"),
-		nil,
-	)
+	descNamespaceAnnotationsName = "kube_namespace_annotations"
+	descNamespaceAnnotationsHelp = "Kubernetes annotations converted to Prometheus labels."
 )
 
+func namespaceMetricFamilies(allowedLabels, allowedAnnotations keyAllowList) []metricFamilyDef {
+	return []metricFamilyDef{
+		{
+			Name: "kube_namespace_created",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapNamespaceFunc(func(n *v1.Namespace) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				if !n.CreationTimestamp.IsZero() {
+					ms = append(ms, &metrics.Metric{
+						Value: float64(n.CreationTimestamp.Unix()),
+					})
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: descNamespaceLabelsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descNamespaceLabelsHelp,
+			GenerateFunc: wrapNamespaceFunc(func(n *v1.Namespace) []*metrics.Metric {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(n.Labels, allowedLabels)
+				return []*metrics.Metric{{
+					LabelKeys:   labelKeys,
+					LabelValues: labelValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: descNamespaceAnnotationsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descNamespaceAnnotationsHelp,
+			GenerateFunc: wrapNamespaceFunc(func(n *v1.Namespace) []*metrics.Metric {
+				annotationKeys, annotationValues := kubeAnnotationsToPrometheusAnnotations(n.Annotations, allowedAnnotations)
+				return []*metrics.Metric{{
+					LabelKeys:   annotationKeys,
+					LabelValues: annotationValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_namespace_status_phase",
+			Type: metrics.MetricTypeGauge,
+			Help: "kubernetes namespace status phase.",
+			GenerateFunc: wrapNamespaceFunc(func(n *v1.Namespace) []*metrics.Metric {
+				return []*metrics.Metric{
+					{
+						LabelKeys:   []string{"phase"},
+						LabelValues: []string{string(v1.NamespaceActive)},
+						Value:       boolFloat64(n.Status.Phase == v1.NamespaceActive),
+					},
+					{
+						LabelKeys:   []string{"phase"},
+						LabelValues: []string{string(v1.NamespaceTerminating)},
+						Value:       boolFloat64(n.Status.Phase == v1.NamespaceTerminating),
+					},
+				}
+			}),
+		},
+	}
+}
+
 func createNamespaceListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
 	return cache.ListWatch{
 		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
@@ -73,54 +113,17 @@ func createNamespaceListWatch(kubeClient clientset.Interface, ns string) cache.L
 	}
 }
 
-func generateNamespaceMetrics(obj interface{}) []*metrics.Metric {
-	ms := []*metrics.Metric{}
+func wrapNamespaceFunc(f func(*v1.Namespace) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		namespace := obj.(*v1.Namespace)
 
-	// TODO: Refactor
-	nPointer := obj.(*v1.Namespace)
-	n := *nPointer
+		ms := f(namespace)
 
-	addGauge := func(desc *metricFamilyDef, v float64, lv ...string) {
-		lv = append([]string{n.Name}, lv...)
-
-		m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, lv, v)
-		if err != nil {
-			panic(err)
+		for _, m := range ms {
+			m.LabelKeys = append(descNamespaceLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{namespace.Name}, m.LabelValues...)
 		}
 
-		ms = append(ms, m)
-	}
-
-	addGauge(descNamespacePhase, boolFloat64(n.Status.Phase == v1.NamespaceActive), string(v1.NamespaceActive))
-	addGauge(descNamespacePhase, boolFloat64(n.Status.Phase == v1.NamespaceTerminating), string(v1.NamespaceTerminating))
-
-	if !n.CreationTimestamp.IsZero() {
-		addGauge(descNamespaceCreated, float64(n.CreationTimestamp.Unix()))
+		return ms
 	}
-
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(n.Labels)
-	addGauge(namespaceLabelsDesc(labelKeys), 1, labelValues...)
-
-	annnotationKeys, annotationValues := kubeAnnotationsToPrometheusAnnotations(n.Annotations)
-	addGauge(namespaceAnnotationsDesc(annnotationKeys), 1, annotationValues...)
-
-	return ms
-}
-
-func namespaceLabelsDesc(labelKeys []string) *metricFamilyDef {
-	return newMetricFamilyDef(
-		descNamespaceLabelsName,
-		descNamespaceLabelsHelp,
-		append(descNamespaceLabelsDefaultLabels, labelKeys...),
-		nil,
-	)
-}
-
-func namespaceAnnotationsDesc(annotationKeys []string) *metricFamilyDef {
-	return newMetricFamilyDef(
-		descNamespaceAnnotationsName,
-		descNamespaceAnnotationsHelp,
-		append(descNamespaceAnnotationsDefaultLabels, annotationKeys...),
-		nil,
-	)
 }
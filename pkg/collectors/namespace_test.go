@@ -135,7 +135,7 @@ func TestNamespaceCollector(t *testing.T) {
 	}
 
 	for i, c := range cases {
-		c.Func = generateNamespaceMetrics
+		c.Func = composeMetricGenFuncs(namespaceMetricFamilies(allKeys, allKeys))
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
@@ -0,0 +1,157 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"k8s.io/kube-state-metrics/pkg/metrics"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	descNetworkPolicyLabelsName          = "kube_networkpolicy_labels"
+	descNetworkPolicyLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descNetworkPolicyLabelsDefaultLabels = []string{"namespace", "networkpolicy"}
+
+	descNetworkPolicyAnnotationsName = "kube_networkpolicy_annotations"
+	descNetworkPolicyAnnotationsHelp = "Kubernetes annotations converted to Prometheus labels."
+)
+
+func networkPolicyMetricFamilies(allowedLabels, allowedAnnotations keyAllowList) []metricFamilyDef {
+	return []metricFamilyDef{
+		{
+			Name: descNetworkPolicyLabelsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descNetworkPolicyLabelsHelp,
+			GenerateFunc: wrapNetworkPolicyFunc(func(n *networkingv1.NetworkPolicy) []*metrics.Metric {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(n.Labels, allowedLabels)
+				return []*metrics.Metric{{
+					LabelKeys:   labelKeys,
+					LabelValues: labelValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: descNetworkPolicyAnnotationsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descNetworkPolicyAnnotationsHelp,
+			GenerateFunc: wrapNetworkPolicyFunc(func(n *networkingv1.NetworkPolicy) []*metrics.Metric {
+				annotationKeys, annotationValues := kubeAnnotationsToPrometheusAnnotations(n.Annotations, allowedAnnotations)
+				return []*metrics.Metric{{
+					LabelKeys:   annotationKeys,
+					LabelValues: annotationValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_networkpolicy_created",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapNetworkPolicyFunc(func(n *networkingv1.NetworkPolicy) []*metrics.Metric {
+				return createdMetric(n.CreationTimestamp)
+			}),
+		},
+		{
+			Name: "kube_networkpolicy_spec_policy_type",
+			Type: metrics.MetricTypeGauge,
+			Help: "Policy types the networkpolicy applies to.",
+			GenerateFunc: wrapNetworkPolicyFunc(func(n *networkingv1.NetworkPolicy) []*metrics.Metric {
+				policyTypes := networkPolicyTypes(n)
+				ms := make([]*metrics.Metric, len(policyTypes))
+
+				for i, t := range policyTypes {
+					ms[i] = &metrics.Metric{
+						LabelKeys:   []string{"policy_type"},
+						LabelValues: []string{string(t)},
+						Value:       1,
+					}
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_networkpolicy_spec_ingress_rules",
+			Type: metrics.MetricTypeGauge,
+			Help: "Number of ingress rules on the networkpolicy.",
+			GenerateFunc: wrapNetworkPolicyFunc(func(n *networkingv1.NetworkPolicy) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(len(n.Spec.Ingress)),
+				}}
+			}),
+		},
+		{
+			Name: "kube_networkpolicy_spec_egress_rules",
+			Type: metrics.MetricTypeGauge,
+			Help: "Number of egress rules on the networkpolicy.",
+			GenerateFunc: wrapNetworkPolicyFunc(func(n *networkingv1.NetworkPolicy) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(len(n.Spec.Egress)),
+				}}
+			}),
+		},
+	}
+}
+
+// networkPolicyTypes returns the policy types in effect for the policy,
+// applying the API server defaults if none are set: Ingress always, and
+// Egress only if the policy has egress rules.
+func networkPolicyTypes(n *networkingv1.NetworkPolicy) []networkingv1.PolicyType {
+	if len(n.Spec.PolicyTypes) > 0 {
+		return n.Spec.PolicyTypes
+	}
+
+	policyTypes := []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}
+	if len(n.Spec.Egress) > 0 {
+		policyTypes = append(policyTypes, networkingv1.PolicyTypeEgress)
+	}
+
+	return policyTypes
+}
+
+func createNetworkPolicyListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
+	return cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return kubeClient.NetworkingV1().NetworkPolicies(ns).List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return kubeClient.NetworkingV1().NetworkPolicies(ns).Watch(opts)
+		},
+	}
+}
+
+func wrapNetworkPolicyFunc(f func(*networkingv1.NetworkPolicy) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		networkPolicy := obj.(*networkingv1.NetworkPolicy)
+
+		ms := f(networkPolicy)
+
+		for _, m := range ms {
+			m.LabelKeys = append(descNetworkPolicyLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{networkPolicy.Namespace, networkPolicy.Name}, m.LabelValues...)
+		}
+
+		return ms
+	}
+}
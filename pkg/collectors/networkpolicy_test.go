@@ -0,0 +1,119 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+	"time"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNetworkPolicyCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_networkpolicy_labels Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_networkpolicy_labels gauge
+		# HELP kube_networkpolicy_annotations Kubernetes annotations converted to Prometheus labels.
+		# TYPE kube_networkpolicy_annotations gauge
+		# HELP kube_networkpolicy_created Unix creation timestamp
+		# TYPE kube_networkpolicy_created gauge
+		# HELP kube_networkpolicy_spec_policy_type Policy types the networkpolicy applies to.
+		# TYPE kube_networkpolicy_spec_policy_type gauge
+		# HELP kube_networkpolicy_spec_ingress_rules Number of ingress rules on the networkpolicy.
+		# TYPE kube_networkpolicy_spec_ingress_rules gauge
+		# HELP kube_networkpolicy_spec_egress_rules Number of egress rules on the networkpolicy.
+		# TYPE kube_networkpolicy_spec_egress_rules gauge
+	`
+
+	cases := []generateMetricsTestCase{
+		{
+			// Default deny all ingress traffic, policy types left to the
+			// API server defaults.
+			Obj: &networkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "default-deny",
+					Namespace:         "ns1",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+					Labels: map[string]string{
+						"app": "foobar",
+					},
+				},
+			},
+			Want: `
+				kube_networkpolicy_created{namespace="ns1",networkpolicy="default-deny"} 1.5e+09
+				kube_networkpolicy_labels{label_app="foobar",namespace="ns1",networkpolicy="default-deny"} 1
+				kube_networkpolicy_spec_egress_rules{namespace="ns1",networkpolicy="default-deny"} 0
+				kube_networkpolicy_spec_ingress_rules{namespace="ns1",networkpolicy="default-deny"} 0
+				kube_networkpolicy_spec_policy_type{namespace="ns1",networkpolicy="default-deny",policy_type="Ingress"} 1
+			`,
+			MetricNames: []string{
+				"kube_networkpolicy_created",
+				"kube_networkpolicy_labels",
+				"kube_networkpolicy_spec_egress_rules",
+				"kube_networkpolicy_spec_ingress_rules",
+				"kube_networkpolicy_spec_policy_type",
+			},
+		},
+		{
+			Obj: &networkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "allow-frontend",
+					Namespace: "ns2",
+				},
+				Spec: networkingv1.NetworkPolicySpec{
+					Ingress: []networkingv1.NetworkPolicyIngressRule{{}, {}},
+					Egress:  []networkingv1.NetworkPolicyEgressRule{{}},
+				},
+			},
+			Want: `
+				kube_networkpolicy_spec_egress_rules{namespace="ns2",networkpolicy="allow-frontend"} 1
+				kube_networkpolicy_spec_ingress_rules{namespace="ns2",networkpolicy="allow-frontend"} 2
+				kube_networkpolicy_spec_policy_type{namespace="ns2",networkpolicy="allow-frontend",policy_type="Egress"} 1
+				kube_networkpolicy_spec_policy_type{namespace="ns2",networkpolicy="allow-frontend",policy_type="Ingress"} 1
+			`,
+			MetricNames: []string{
+				"kube_networkpolicy_spec_egress_rules",
+				"kube_networkpolicy_spec_ingress_rules",
+				"kube_networkpolicy_spec_policy_type",
+			},
+		},
+		{
+			Obj: &networkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "deny-egress",
+					Namespace: "ns3",
+				},
+				Spec: networkingv1.NetworkPolicySpec{
+					PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+				},
+			},
+			Want: `
+				kube_networkpolicy_spec_policy_type{namespace="ns3",networkpolicy="deny-egress",policy_type="Egress"} 1
+			`,
+			MetricNames: []string{"kube_networkpolicy_spec_policy_type"},
+		},
+	}
+	for i, c := range cases {
+		c.Func = composeMetricGenFuncs(networkPolicyMetricFamilies(allKeys, allKeys))
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
@@ -34,104 +34,233 @@ var (
 	descNodeLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
 	descNodeLabelsDefaultLabels = []string{"node"}
 
-	descNodeInfo = newMetricFamilyDef(
-		"kube_node_info",
-		"Information about a cluster node.",
-		append(descNodeLabelsDefaultLabels,
-			"kernel_version",
-			"os_image",
-			"container_runtime_version",
-			"kubelet_version",
-			"kubeproxy_version",
-			"provider_id"),
-		nil,
-	)
-	descNodeCreated = newMetricFamilyDef(
-		"kube_node_created",
-		"Unix creation timestamp",
-		descNodeLabelsDefaultLabels,
-		nil,
-	)
-	descNodeLabels = newMetricFamilyDef(
-		descNodeLabelsName,
-		descNodeLabelsHelp,
-		descNodeLabelsDefaultLabels,
-		nil,
-	)
-	descNodeSpecUnschedulable = newMetricFamilyDef(
-		"kube_node_spec_unschedulable",
-		"Whether a node can schedule new pods.",
-		descNodeLabelsDefaultLabels,
-		nil,
-	)
-	descNodeSpecTaint = newMetricFamilyDef(
-		"kube_node_spec_taint",
-		"The taint of a cluster node.",
-		append(descNodeLabelsDefaultLabels, "key", "value", "effect"),
-		nil,
-	)
-	descNodeStatusCondition = newMetricFamilyDef(
-		"kube_node_status_condition",
-		"The condition of a cluster node.",
-		append(descNodeLabelsDefaultLabels, "condition", "status"),
-		nil,
-	)
-	descNodeStatusPhase = newMetricFamilyDef(
-		"kube_node_status_phase",
-		"The phase the node is currently in.",
-		append(descNodeLabelsDefaultLabels, "phase"),
-		nil,
-	)
-	descNodeStatusCapacity = newMetricFamilyDef(
-		"kube_node_status_capacity",
-		"The capacity for different resources of a node.",
-		append(descNodeLabelsDefaultLabels, "resource", "unit"),
-		nil,
-	)
-	descNodeStatusCapacityPods = newMetricFamilyDef(
-		"kube_node_status_capacity_pods",
-		"The total pod resources of the node.",
-		descNodeLabelsDefaultLabels,
-		nil,
-	)
-	descNodeStatusCapacityCPU = newMetricFamilyDef(
-		"kube_node_status_capacity_cpu_cores",
-		"The total CPU resources of the node.",
-		descNodeLabelsDefaultLabels,
-		nil,
-	)
-	descNodeStatusCapacityMemory = newMetricFamilyDef(
-		"kube_node_status_capacity_memory_bytes",
-		"The total memory resources of the node.",
-		descNodeLabelsDefaultLabels,
-		nil,
-	)
-	descNodeStatusAllocatable = newMetricFamilyDef(
-		"kube_node_status_allocatable",
-		"The allocatable for different resources of a node that are available for scheduling.",
-		append(descNodeLabelsDefaultLabels, "resource", "unit"),
-		nil,
-	)
-	descNodeStatusAllocatablePods = newMetricFamilyDef(
-		"kube_node_status_allocatable_pods",
-		"The pod resources of a node that are available for scheduling.",
-		descNodeLabelsDefaultLabels,
-		nil,
-	)
-	descNodeStatusAllocatableCPU = newMetricFamilyDef(
-		"kube_node_status_allocatable_cpu_cores",
-		"The CPU resources of a node that are available for scheduling.",
-		descNodeLabelsDefaultLabels,
-		nil,
-	)
-	descNodeStatusAllocatableMemory = newMetricFamilyDef(
-		"kube_node_status_allocatable_memory_bytes",
-		"The memory resources of a node that are available for scheduling.",
-		descNodeLabelsDefaultLabels,
-		nil,
-	)
+	descNodeAnnotationsName = "kube_node_annotations"
+	descNodeAnnotationsHelp = "Kubernetes annotations converted to Prometheus labels."
+
+	// nodeNonGenericResourceMetricFamilies can be disabled via
+	// --disable-node-non-generic-resource-metrics.
+	nodeNonGenericResourceMetricFamilies = []metricFamilyDef{
+		{
+			Name: "kube_node_status_capacity_pods",
+			Type: metrics.MetricTypeGauge,
+			Help: "The total pod resources of the node.",
+			GenerateFunc: wrapNodeFunc(func(n *v1.Node) []*metrics.Metric {
+				return nodeResourceMetric(n.Status.Capacity, v1.ResourcePods)
+			}),
+		},
+		{
+			Name: "kube_node_status_capacity_cpu_cores",
+			Type: metrics.MetricTypeGauge,
+			Help: "The total CPU resources of the node.",
+			GenerateFunc: wrapNodeFunc(func(n *v1.Node) []*metrics.Metric {
+				return nodeResourceMetric(n.Status.Capacity, v1.ResourceCPU)
+			}),
+		},
+		{
+			Name: "kube_node_status_capacity_memory_bytes",
+			Type: metrics.MetricTypeGauge,
+			Help: "The total memory resources of the node.",
+			GenerateFunc: wrapNodeFunc(func(n *v1.Node) []*metrics.Metric {
+				return nodeResourceMetric(n.Status.Capacity, v1.ResourceMemory)
+			}),
+		},
+		{
+			Name: "kube_node_status_allocatable_pods",
+			Type: metrics.MetricTypeGauge,
+			Help: "The pod resources of a node that are available for scheduling.",
+			GenerateFunc: wrapNodeFunc(func(n *v1.Node) []*metrics.Metric {
+				return nodeResourceMetric(n.Status.Allocatable, v1.ResourcePods)
+			}),
+		},
+		{
+			Name: "kube_node_status_allocatable_cpu_cores",
+			Type: metrics.MetricTypeGauge,
+			Help: "The CPU resources of a node that are available for scheduling.",
+			GenerateFunc: wrapNodeFunc(func(n *v1.Node) []*metrics.Metric {
+				return nodeResourceMetric(n.Status.Allocatable, v1.ResourceCPU)
+			}),
+		},
+		{
+			Name: "kube_node_status_allocatable_memory_bytes",
+			Type: metrics.MetricTypeGauge,
+			Help: "The memory resources of a node that are available for scheduling.",
+			GenerateFunc: wrapNodeFunc(func(n *v1.Node) []*metrics.Metric {
+				return nodeResourceMetric(n.Status.Allocatable, v1.ResourceMemory)
+			}),
+		},
+	}
 )
 
+func nodeMetricFamilies(allowedLabels, allowedAnnotations keyAllowList) []metricFamilyDef {
+	return []metricFamilyDef{
+		{
+			Name: "kube_node_info",
+			Type: metrics.MetricTypeGauge,
+			Help: "Information about a cluster node.",
+			GenerateFunc: wrapNodeFunc(func(n *v1.Node) []*metrics.Metric {
+				return []*metrics.Metric{{
+					LabelKeys: []string{
+						"kernel_version",
+						"os_image",
+						"container_runtime_version",
+						"kubelet_version",
+						"kubeproxy_version",
+						"provider_id",
+					},
+					LabelValues: []string{
+						n.Status.NodeInfo.KernelVersion,
+						n.Status.NodeInfo.OSImage,
+						n.Status.NodeInfo.ContainerRuntimeVersion,
+						n.Status.NodeInfo.KubeletVersion,
+						n.Status.NodeInfo.KubeProxyVersion,
+						n.Spec.ProviderID,
+					},
+					Value: 1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_node_created",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapNodeFunc(func(n *v1.Node) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				if !n.CreationTimestamp.IsZero() {
+					ms = append(ms, &metrics.Metric{
+						Value: float64(n.CreationTimestamp.Unix()),
+					})
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: descNodeLabelsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descNodeLabelsHelp,
+			GenerateFunc: wrapNodeFunc(func(n *v1.Node) []*metrics.Metric {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(n.Labels, allowedLabels)
+				return []*metrics.Metric{{
+					LabelKeys:   labelKeys,
+					LabelValues: labelValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: descNodeAnnotationsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descNodeAnnotationsHelp,
+			GenerateFunc: wrapNodeFunc(func(n *v1.Node) []*metrics.Metric {
+				annotationKeys, annotationValues := kubeAnnotationsToPrometheusAnnotations(n.Annotations, allowedAnnotations)
+				return []*metrics.Metric{{
+					LabelKeys:   annotationKeys,
+					LabelValues: annotationValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_node_spec_unschedulable",
+			Type: metrics.MetricTypeGauge,
+			Help: "Whether a node can schedule new pods.",
+			GenerateFunc: wrapNodeFunc(func(n *v1.Node) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: boolFloat64(n.Spec.Unschedulable),
+				}}
+			}),
+		},
+		{
+			Name: "kube_node_spec_taint",
+			Type: metrics.MetricTypeGauge,
+			Help: "The taint of a cluster node.",
+			GenerateFunc: wrapNodeFunc(func(n *v1.Node) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				for _, taint := range n.Spec.Taints {
+					// Taints are applied to repel pods from nodes that do not have a corresponding
+					// toleration.  Many node conditions are optionally reflected as taints
+					// by the node controller in order to simplify scheduling constraints.
+					ms = append(ms, &metrics.Metric{
+						LabelKeys:   []string{"key", "value", "effect"},
+						LabelValues: []string{taint.Key, taint.Value, string(taint.Effect)},
+						Value:       1,
+					})
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_node_status_condition",
+			Type: metrics.MetricTypeGauge,
+			Help: "The condition of a cluster node.",
+			GenerateFunc: wrapNodeFunc(func(n *v1.Node) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				// Collect node conditions and while default to false.
+				for _, c := range n.Status.Conditions {
+					// This all-in-one metric family contains all conditions for extensibility.
+					// Third party plugin may report customized condition for cluster node
+					// (e.g. node-problem-detector), and Kubernetes may add new core
+					// conditions in future.
+					conditionMetrics := addConditionMetrics(c.Status, "status")
+
+					for _, m := range conditionMetrics {
+						m.LabelKeys = append([]string{"condition"}, m.LabelKeys...)
+						m.LabelValues = append([]string{string(c.Type)}, m.LabelValues...)
+					}
+
+					ms = append(ms, conditionMetrics...)
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_node_status_phase",
+			Type: metrics.MetricTypeGauge,
+			Help: "The phase the node is currently in.",
+			GenerateFunc: wrapNodeFunc(func(n *v1.Node) []*metrics.Metric {
+				p := n.Status.Phase
+
+				if p == "" {
+					return []*metrics.Metric{}
+				}
+
+				// Set current phase to 1, others to 0 if it is set.
+				ms := []*metrics.Metric{}
+				for _, phase := range []v1.NodePhase{v1.NodePending, v1.NodeRunning, v1.NodeTerminated} {
+					ms = append(ms, &metrics.Metric{
+						LabelKeys:   []string{"phase"},
+						LabelValues: []string{string(phase)},
+						Value:       boolFloat64(p == phase),
+					})
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_node_status_capacity",
+			Type: metrics.MetricTypeGauge,
+			Help: "The capacity for different resources of a node.",
+			GenerateFunc: wrapNodeFunc(func(n *v1.Node) []*metrics.Metric {
+				return nodeResourceMetrics(n.Status.Capacity)
+			}),
+		},
+		{
+			Name: "kube_node_status_allocatable",
+			Type: metrics.MetricTypeGauge,
+			Help: "The allocatable for different resources of a node that are available for scheduling.",
+			GenerateFunc: wrapNodeFunc(func(n *v1.Node) []*metrics.Metric {
+				return nodeResourceMetrics(n.Status.Allocatable)
+			}),
+		},
+	}
+}
+
 func createNodeListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
 	return cache.ListWatch{
 		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
@@ -143,140 +272,68 @@ func createNodeListWatch(kubeClient clientset.Interface, ns string) cache.ListWa
 	}
 }
 
-func nodeLabelsDesc(labelKeys []string) *metricFamilyDef {
-	return newMetricFamilyDef(
-		descNodeLabelsName,
-		descNodeLabelsHelp,
-		append(descNodeLabelsDefaultLabels, labelKeys...),
-		nil,
-	)
-}
+func wrapNodeFunc(f func(*v1.Node) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		node := obj.(*v1.Node)
 
-func generateNodeMetrics(disableNodeNonGenericResourceMetrics bool, obj interface{}) []*metrics.Metric {
-	ms := []*metrics.Metric{}
+		ms := f(node)
 
-	// TODO: Refactor
-	nPointer := obj.(*v1.Node)
-	n := *nPointer
-
-	addGauge := func(desc *metricFamilyDef, v float64, lv ...string) {
-		lv = append([]string{n.Name}, lv...)
-
-		m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, lv, v)
-		if err != nil {
-			panic(err)
+		for _, m := range ms {
+			m.LabelKeys = append(descNodeLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{node.Name}, m.LabelValues...)
 		}
 
-		ms = append(ms, m)
-	}
-	// NOTE: the instrumentation API requires providing label values in order of declaration
-	// in the metric descriptor. Be careful when making modifications.
-	addGauge(descNodeInfo, 1,
-		n.Status.NodeInfo.KernelVersion,
-		n.Status.NodeInfo.OSImage,
-		n.Status.NodeInfo.ContainerRuntimeVersion,
-		n.Status.NodeInfo.KubeletVersion,
-		n.Status.NodeInfo.KubeProxyVersion,
-		n.Spec.ProviderID,
-	)
-	if !n.CreationTimestamp.IsZero() {
-		addGauge(descNodeCreated, float64(n.CreationTimestamp.Unix()))
-	}
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(n.Labels)
-	addGauge(nodeLabelsDesc(labelKeys), 1, labelValues...)
-
-	addGauge(descNodeSpecUnschedulable, boolFloat64(n.Spec.Unschedulable))
-
-	// Collect node taints
-	for _, taint := range n.Spec.Taints {
-		// Taints are applied to repel pods from nodes that do not have a corresponding
-		// toleration.  Many node conditions are optionally reflected as taints
-		// by the node controller in order to simplify scheduling constraints.
-		addGauge(descNodeSpecTaint, 1, taint.Key, taint.Value, string(taint.Effect))
+		return ms
 	}
+}
 
-	// Collect node conditions and while default to false.
-	for _, c := range n.Status.Conditions {
-		// This all-in-one metric family contains all conditions for extensibility.
-		// Third party plugin may report customized condition for cluster node
-		// (e.g. node-problem-detector), and Kubernetes may add new core
-		// conditions in future.
-		ms = append(ms, addConditionMetrics(descNodeStatusCondition, c.Status, n.Name, string(c.Type))...)
-	}
+// nodeResourceMetric returns a metric for the given resource if it is set.
+func nodeResourceMetric(res v1.ResourceList, n v1.ResourceName) []*metrics.Metric {
+	ms := []*metrics.Metric{}
 
-	// Set current phase to 1, others to 0 if it is set.
-	if p := n.Status.Phase; p != "" {
-		addGauge(descNodeStatusPhase, boolFloat64(p == v1.NodePending), string(v1.NodePending))
-		addGauge(descNodeStatusPhase, boolFloat64(p == v1.NodeRunning), string(v1.NodeRunning))
-		addGauge(descNodeStatusPhase, boolFloat64(p == v1.NodeTerminated), string(v1.NodeTerminated))
+	if v, ok := res[n]; ok {
+		ms = append(ms, &metrics.Metric{
+			Value: float64(v.MilliValue()) / 1000,
+		})
 	}
 
-	if !disableNodeNonGenericResourceMetrics {
-		// Add capacity and allocatable resources if they are set.
-		addResource := func(d *metricFamilyDef, res v1.ResourceList, n v1.ResourceName) {
-			if v, ok := res[n]; ok {
-				addGauge(d, float64(v.MilliValue())/1000)
-			}
-		}
-
-		addResource(descNodeStatusCapacityCPU, n.Status.Capacity, v1.ResourceCPU)
-		addResource(descNodeStatusCapacityMemory, n.Status.Capacity, v1.ResourceMemory)
-		addResource(descNodeStatusCapacityPods, n.Status.Capacity, v1.ResourcePods)
-
-		addResource(descNodeStatusAllocatableCPU, n.Status.Allocatable, v1.ResourceCPU)
-		addResource(descNodeStatusAllocatableMemory, n.Status.Allocatable, v1.ResourceMemory)
-		addResource(descNodeStatusAllocatablePods, n.Status.Allocatable, v1.ResourcePods)
-	}
+	return ms
+}
 
-	capacity := n.Status.Capacity
-	allocatable := n.Status.Allocatable
+// nodeResourceMetrics returns one metric per resource of the given resource
+// list, labeled with the resource name and unit.
+func nodeResourceMetrics(res v1.ResourceList) []*metrics.Metric {
+	ms := []*metrics.Metric{}
 
-	for resourceName, val := range capacity {
-		switch resourceName {
-		case v1.ResourceCPU:
-			addGauge(descNodeStatusCapacity, float64(val.MilliValue())/1000, sanitizeLabelName(string(resourceName)), string(constant.UnitCore))
-		case v1.ResourceStorage:
-			fallthrough
-		case v1.ResourceEphemeralStorage:
-			fallthrough
-		case v1.ResourceMemory:
-			addGauge(descNodeStatusCapacity, float64(val.MilliValue())/1000, sanitizeLabelName(string(resourceName)), string(constant.UnitByte))
-		case v1.ResourcePods:
-			addGauge(descNodeStatusCapacity, float64(val.MilliValue())/1000, sanitizeLabelName(string(resourceName)), string(constant.UnitInteger))
-		default:
-			if helper.IsHugePageResourceName(resourceName) {
-				addGauge(descNodeStatusCapacity, float64(val.MilliValue())/1000, sanitizeLabelName(string(resourceName)), string(constant.UnitByte))
-			}
-			if helper.IsAttachableVolumeResourceName(resourceName) {
-				addGauge(descNodeStatusCapacity, float64(val.MilliValue())/1000, sanitizeLabelName(string(resourceName)), string(constant.UnitByte))
-			}
-			if helper.IsExtendedResourceName(resourceName) {
-				addGauge(descNodeStatusCapacity, float64(val.MilliValue())/1000, sanitizeLabelName(string(resourceName)), string(constant.UnitInteger))
-			}
-		}
+	addResource := func(resourceName v1.ResourceName, unit constant.ResourceUnit, val float64) {
+		ms = append(ms, &metrics.Metric{
+			LabelKeys:   []string{"resource", "unit"},
+			LabelValues: []string{sanitizeLabelName(string(resourceName)), string(unit)},
+			Value:       val,
+		})
 	}
 
-	for resourceName, val := range allocatable {
+	for resourceName, val := range res {
 		switch resourceName {
 		case v1.ResourceCPU:
-			addGauge(descNodeStatusAllocatable, float64(val.MilliValue())/1000, sanitizeLabelName(string(resourceName)), string(constant.UnitCore))
+			addResource(resourceName, constant.UnitCore, float64(val.MilliValue())/1000)
 		case v1.ResourceStorage:
 			fallthrough
 		case v1.ResourceEphemeralStorage:
 			fallthrough
 		case v1.ResourceMemory:
-			addGauge(descNodeStatusAllocatable, float64(val.MilliValue())/1000, sanitizeLabelName(string(resourceName)), string(constant.UnitByte))
+			addResource(resourceName, constant.UnitByte, float64(val.MilliValue())/1000)
 		case v1.ResourcePods:
-			addGauge(descNodeStatusAllocatable, float64(val.MilliValue())/1000, sanitizeLabelName(string(resourceName)), string(constant.UnitInteger))
+			addResource(resourceName, constant.UnitInteger, float64(val.MilliValue())/1000)
 		default:
 			if helper.IsHugePageResourceName(resourceName) {
-				addGauge(descNodeStatusAllocatable, float64(val.MilliValue())/1000, sanitizeLabelName(string(resourceName)), string(constant.UnitByte))
+				addResource(resourceName, constant.UnitByte, float64(val.MilliValue())/1000)
 			}
 			if helper.IsAttachableVolumeResourceName(resourceName) {
-				addGauge(descNodeStatusAllocatable, float64(val.MilliValue())/1000, sanitizeLabelName(string(resourceName)), string(constant.UnitByte))
+				addResource(resourceName, constant.UnitByte, float64(val.MilliValue())/1000)
 			}
 			if helper.IsExtendedResourceName(resourceName) {
-				addGauge(descNodeStatusAllocatable, float64(val.MilliValue())/1000, sanitizeLabelName(string(resourceName)), string(constant.UnitInteger))
+				addResource(resourceName, constant.UnitInteger, float64(val.MilliValue())/1000)
 			}
 		}
 	}
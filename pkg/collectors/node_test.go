@@ -23,7 +23,6 @@ import (
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/kube-state-metrics/pkg/metrics"
 )
 
 func TestNodeCollector(t *testing.T) {
@@ -83,6 +82,7 @@ func TestNodeCollector(t *testing.T) {
 			},
 			Want: `
 				kube_node_info{container_runtime_version="rkt",kernel_version="kernel",kubelet_version="kubelet",kubeproxy_version="kubeproxy",node="127.0.0.1",os_image="osimage",provider_id="provider://i-uniqueid"} 1
+				kube_node_annotations{node="127.0.0.1"} 1
 				kube_node_labels{node="127.0.0.1"} 1
 				kube_node_spec_unschedulable{node="127.0.0.1"} 0
 			`,
@@ -130,6 +130,7 @@ func TestNodeCollector(t *testing.T) {
 			Want: `
         kube_node_created{node="127.0.0.1"} 1.5e+09
         kube_node_info{container_runtime_version="rkt",kernel_version="kernel",kubelet_version="kubelet",kubeproxy_version="kubeproxy",node="127.0.0.1",os_image="osimage",provider_id="provider://i-randomidentifier"} 1
+        kube_node_annotations{node="127.0.0.1"} 1
         kube_node_labels{label_type="master",node="127.0.0.1"} 1
         kube_node_spec_unschedulable{node="127.0.0.1"} 1
         kube_node_status_allocatable_cpu_cores{node="127.0.0.1"} 3
@@ -303,9 +304,7 @@ func TestNodeCollector(t *testing.T) {
 		},
 	}
 	for i, c := range cases {
-		c.Func = func(obj interface{}) []*metrics.Metric {
-			return generateNodeMetrics(false, obj)
-		}
+		c.Func = composeMetricGenFuncs(append(nodeMetricFamilies(allKeys, allKeys), nodeNonGenericResourceMetricFamilies...))
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
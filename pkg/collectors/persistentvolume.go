@@ -32,26 +32,80 @@ var (
 	descPersistentVolumeLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
 	descPersistentVolumeLabelsDefaultLabels = []string{"persistentvolume"}
 
-	descPersistentVolumeLabels = newMetricFamilyDef(
-		descPersistentVolumeLabelsName,
-		descPersistentVolumeLabelsHelp,
-		descPersistentVolumeLabelsDefaultLabels,
-		nil,
-	)
-	descPersistentVolumeStatusPhase = newMetricFamilyDef(
-		"kube_persistentvolume_status_phase",
-		"The phase indicates if a volume is available, bound to a claim, or released by a claim.",
-		append(descPersistentVolumeLabelsDefaultLabels, "phase"),
-		nil,
-	)
-	descPersistentVolumeInfo = newMetricFamilyDef(
-		"kube_persistentvolume_info",
-		"Information about persistentvolume.",
-		append(descPersistentVolumeLabelsDefaultLabels, "storageclass"),
-		nil,
-	)
+	descPersistentVolumeAnnotationsName = "kube_persistentvolume_annotations"
+	descPersistentVolumeAnnotationsHelp = "Kubernetes annotations converted to Prometheus labels."
 )
 
+func persistentVolumeMetricFamilies(allowedLabels, allowedAnnotations keyAllowList) []metricFamilyDef {
+	return []metricFamilyDef{
+		{
+			Name: descPersistentVolumeLabelsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descPersistentVolumeLabelsHelp,
+			GenerateFunc: wrapPersistentVolumeFunc(func(p *v1.PersistentVolume) []*metrics.Metric {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(p.Labels, allowedLabels)
+				return []*metrics.Metric{{
+					LabelKeys:   labelKeys,
+					LabelValues: labelValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: descPersistentVolumeAnnotationsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descPersistentVolumeAnnotationsHelp,
+			GenerateFunc: wrapPersistentVolumeFunc(func(p *v1.PersistentVolume) []*metrics.Metric {
+				annotationKeys, annotationValues := kubeAnnotationsToPrometheusAnnotations(p.Annotations, allowedAnnotations)
+				return []*metrics.Metric{{
+					LabelKeys:   annotationKeys,
+					LabelValues: annotationValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_persistentvolume_status_phase",
+			Type: metrics.MetricTypeGauge,
+			Help: "The phase indicates if a volume is available, bound to a claim, or released by a claim.",
+			GenerateFunc: wrapPersistentVolumeFunc(func(p *v1.PersistentVolume) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				// Set current phase to 1, others to 0 if it is set.
+				if p := p.Status.Phase; p != "" {
+					for _, phase := range []v1.PersistentVolumePhase{
+						v1.VolumePending,
+						v1.VolumeAvailable,
+						v1.VolumeBound,
+						v1.VolumeReleased,
+						v1.VolumeFailed,
+					} {
+						ms = append(ms, &metrics.Metric{
+							LabelKeys:   []string{"phase"},
+							LabelValues: []string{string(phase)},
+							Value:       boolFloat64(p == phase),
+						})
+					}
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_persistentvolume_info",
+			Type: metrics.MetricTypeGauge,
+			Help: "Information about persistentvolume.",
+			GenerateFunc: wrapPersistentVolumeFunc(func(p *v1.PersistentVolume) []*metrics.Metric {
+				return []*metrics.Metric{{
+					LabelKeys:   []string{"storageclass"},
+					LabelValues: []string{p.Spec.StorageClassName},
+					Value:       1,
+				}}
+			}),
+		},
+	}
+}
+
 func createPersistentVolumeListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
 	return cache.ListWatch{
 		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
@@ -63,45 +117,17 @@ func createPersistentVolumeListWatch(kubeClient clientset.Interface, ns string)
 	}
 }
 
-func persistentVolumeLabelsDesc(labelKeys []string) *metricFamilyDef {
-	return newMetricFamilyDef(
-		descPersistentVolumeLabelsName,
-		descPersistentVolumeLabelsHelp,
-		append(descPersistentVolumeLabelsDefaultLabels, labelKeys...),
-		nil,
-	)
-}
-
-func generatePersistentVolumeMetrics(obj interface{}) []*metrics.Metric {
-	ms := []*metrics.Metric{}
-
-	// TODO: Refactor
-	pPointer := obj.(*v1.PersistentVolume)
-	p := *pPointer
+func wrapPersistentVolumeFunc(f func(*v1.PersistentVolume) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		persistentVolume := obj.(*v1.PersistentVolume)
 
-	addGauge := func(desc *metricFamilyDef, v float64, lv ...string) {
-		lv = append([]string{p.Name}, lv...)
+		ms := f(persistentVolume)
 
-		m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, lv, v)
-		if err != nil {
-			panic(err)
+		for _, m := range ms {
+			m.LabelKeys = append(descPersistentVolumeLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{persistentVolume.Name}, m.LabelValues...)
 		}
 
-		ms = append(ms, m)
+		return ms
 	}
-
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(p.Labels)
-	addGauge(persistentVolumeLabelsDesc(labelKeys), 1, labelValues...)
-
-	addGauge(descPersistentVolumeInfo, 1, p.Spec.StorageClassName)
-	// Set current phase to 1, others to 0 if it is set.
-	if p := p.Status.Phase; p != "" {
-		addGauge(descPersistentVolumeStatusPhase, boolFloat64(p == v1.VolumePending), string(v1.VolumePending))
-		addGauge(descPersistentVolumeStatusPhase, boolFloat64(p == v1.VolumeAvailable), string(v1.VolumeAvailable))
-		addGauge(descPersistentVolumeStatusPhase, boolFloat64(p == v1.VolumeBound), string(v1.VolumeBound))
-		addGauge(descPersistentVolumeStatusPhase, boolFloat64(p == v1.VolumeReleased), string(v1.VolumeReleased))
-		addGauge(descPersistentVolumeStatusPhase, boolFloat64(p == v1.VolumeFailed), string(v1.VolumeFailed))
-	}
-
-	return ms
 }
@@ -202,7 +202,7 @@ func TestPersistentVolumeCollector(t *testing.T) {
 		},
 	}
 	for i, c := range cases {
-		c.Func = generatePersistentVolumeMetrics
+		c.Func = composeMetricGenFuncs(persistentVolumeMetricFamilies(allKeys, allKeys))
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
@@ -32,32 +32,92 @@ var (
 	descPersistentVolumeClaimLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
 	descPersistentVolumeClaimLabelsDefaultLabels = []string{"namespace", "persistentvolumeclaim"}
 
-	descPersistentVolumeClaimLabels = newMetricFamilyDef(
-		descPersistentVolumeClaimLabelsName,
-		descPersistentVolumeClaimLabelsHelp,
-		descPersistentVolumeClaimLabelsDefaultLabels,
-		nil,
-	)
-	descPersistentVolumeClaimInfo = newMetricFamilyDef(
-		"kube_persistentvolumeclaim_info",
-		"Information about persistent volume claim.",
-		append(descPersistentVolumeClaimLabelsDefaultLabels, "storageclass", "volumename"),
-		nil,
-	)
-	descPersistentVolumeClaimStatusPhase = newMetricFamilyDef(
-		"kube_persistentvolumeclaim_status_phase",
-		"The phase the persistent volume claim is currently in.",
-		append(descPersistentVolumeClaimLabelsDefaultLabels, "phase"),
-		nil,
-	)
-	descPersistentVolumeClaimResourceRequestsStorage = newMetricFamilyDef(
-		"kube_persistentvolumeclaim_resource_requests_storage_bytes",
-		"The capacity of storage requested by the persistent volume claim.",
-		descPersistentVolumeClaimLabelsDefaultLabels,
-		nil,
-	)
+	descPersistentVolumeClaimAnnotationsName = "kube_persistentvolumeclaim_annotations"
+	descPersistentVolumeClaimAnnotationsHelp = "Kubernetes annotations converted to Prometheus labels."
 )
 
+func persistentVolumeClaimMetricFamilies(allowedLabels, allowedAnnotations keyAllowList) []metricFamilyDef {
+	return []metricFamilyDef{
+		{
+			Name: descPersistentVolumeClaimLabelsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descPersistentVolumeClaimLabelsHelp,
+			GenerateFunc: wrapPersistentVolumeClaimFunc(func(p *v1.PersistentVolumeClaim) []*metrics.Metric {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(p.Labels, allowedLabels)
+				return []*metrics.Metric{{
+					LabelKeys:   labelKeys,
+					LabelValues: labelValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: descPersistentVolumeClaimAnnotationsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descPersistentVolumeClaimAnnotationsHelp,
+			GenerateFunc: wrapPersistentVolumeClaimFunc(func(p *v1.PersistentVolumeClaim) []*metrics.Metric {
+				annotationKeys, annotationValues := kubeAnnotationsToPrometheusAnnotations(p.Annotations, allowedAnnotations)
+				return []*metrics.Metric{{
+					LabelKeys:   annotationKeys,
+					LabelValues: annotationValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_persistentvolumeclaim_info",
+			Type: metrics.MetricTypeGauge,
+			Help: "Information about persistent volume claim.",
+			GenerateFunc: wrapPersistentVolumeClaimFunc(func(p *v1.PersistentVolumeClaim) []*metrics.Metric {
+				storageClassName := getPersistentVolumeClaimClass(p)
+				volumeName := p.Spec.VolumeName
+				return []*metrics.Metric{{
+					LabelKeys:   []string{"storageclass", "volumename"},
+					LabelValues: []string{storageClassName, volumeName},
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_persistentvolumeclaim_status_phase",
+			Type: metrics.MetricTypeGauge,
+			Help: "The phase the persistent volume claim is currently in.",
+			GenerateFunc: wrapPersistentVolumeClaimFunc(func(p *v1.PersistentVolumeClaim) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				// Set current phase to 1, others to 0 if it is set.
+				if p := p.Status.Phase; p != "" {
+					for _, phase := range []v1.PersistentVolumeClaimPhase{v1.ClaimLost, v1.ClaimBound, v1.ClaimPending} {
+						ms = append(ms, &metrics.Metric{
+							LabelKeys:   []string{"phase"},
+							LabelValues: []string{string(phase)},
+							Value:       boolFloat64(p == phase),
+						})
+					}
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_persistentvolumeclaim_resource_requests_storage_bytes",
+			Type: metrics.MetricTypeGauge,
+			Help: "The capacity of storage requested by the persistent volume claim.",
+			GenerateFunc: wrapPersistentVolumeClaimFunc(func(p *v1.PersistentVolumeClaim) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				if storage, ok := p.Spec.Resources.Requests[v1.ResourceStorage]; ok {
+					ms = append(ms, &metrics.Metric{
+						Value: float64(storage.Value()),
+					})
+				}
+
+				return ms
+			}),
+		},
+	}
+}
+
 func createPersistentVolumeClaimListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
 	return cache.ListWatch{
 		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
@@ -68,13 +128,20 @@ func createPersistentVolumeClaimListWatch(kubeClient clientset.Interface, ns str
 		},
 	}
 }
-func persistentVolumeClaimLabelsDesc(labelKeys []string) *metricFamilyDef {
-	return newMetricFamilyDef(
-		descPersistentVolumeClaimLabelsName,
-		descPersistentVolumeClaimLabelsHelp,
-		append(descPersistentVolumeClaimLabelsDefaultLabels, labelKeys...),
-		nil,
-	)
+
+func wrapPersistentVolumeClaimFunc(f func(*v1.PersistentVolumeClaim) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		persistentVolumeClaim := obj.(*v1.PersistentVolumeClaim)
+
+		ms := f(persistentVolumeClaim)
+
+		for _, m := range ms {
+			m.LabelKeys = append(descPersistentVolumeClaimLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{persistentVolumeClaim.Namespace, persistentVolumeClaim.Name}, m.LabelValues...)
+		}
+
+		return ms
+	}
 }
 
 // getPersistentVolumeClaimClass returns StorageClassName. If no storage class was
@@ -92,42 +159,3 @@ func getPersistentVolumeClaimClass(claim *v1.PersistentVolumeClaim) string {
 	// Special non-empty string to indicate absence of storage class.
 	return "<none>"
 }
-
-func generatePersistentVolumeClaimMetrics(obj interface{}) []*metrics.Metric {
-	ms := []*metrics.Metric{}
-
-	// TODO: Refactor
-	pPointer := obj.(*v1.PersistentVolumeClaim)
-	p := *pPointer
-
-	addGauge := func(desc *metricFamilyDef, v float64, lv ...string) {
-		lv = append([]string{p.Namespace, p.Name}, lv...)
-
-		m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, lv, v)
-		if err != nil {
-			panic(err)
-		}
-
-		ms = append(ms, m)
-	}
-
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(p.Labels)
-	addGauge(persistentVolumeClaimLabelsDesc(labelKeys), 1, labelValues...)
-
-	storageClassName := getPersistentVolumeClaimClass(&p)
-	volumeName := p.Spec.VolumeName
-	addGauge(descPersistentVolumeClaimInfo, 1, storageClassName, volumeName)
-
-	// Set current phase to 1, others to 0 if it is set.
-	if p := p.Status.Phase; p != "" {
-		addGauge(descPersistentVolumeClaimStatusPhase, boolFloat64(p == v1.ClaimLost), string(v1.ClaimLost))
-		addGauge(descPersistentVolumeClaimStatusPhase, boolFloat64(p == v1.ClaimBound), string(v1.ClaimBound))
-		addGauge(descPersistentVolumeClaimStatusPhase, boolFloat64(p == v1.ClaimPending), string(v1.ClaimPending))
-	}
-
-	if storage, ok := p.Spec.Resources.Requests[v1.ResourceStorage]; ok {
-		addGauge(descPersistentVolumeClaimResourceRequestsStorage, float64(storage.Value()))
-	}
-
-	return ms
-}
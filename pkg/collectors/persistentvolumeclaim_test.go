@@ -115,7 +115,7 @@ func TestPersistentVolumeClaimCollector(t *testing.T) {
 		},
 	}
 	for i, c := range cases {
-		c.Func = generatePersistentVolumeClaimMetrics
+		c.Func = composeMetricGenFuncs(persistentVolumeClaimMetricFamilies(allKeys, allKeys))
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
@@ -32,469 +32,668 @@ import (
 	"k8s.io/kubernetes/pkg/util/node"
 )
 
-// TODO: Can't we use const instead?
 var (
 	descPodLabelsName          = "kube_pod_labels"
 	descPodLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
 	descPodLabelsDefaultLabels = []string{"namespace", "pod"}
+
+	descPodAnnotationsName     = "kube_pod_annotations"
+	descPodAnnotationsHelp     = "Kubernetes annotations converted to Prometheus labels."
 	containerWaitingReasons    = []string{"ContainerCreating", "CrashLoopBackOff", "ErrImagePull", "ImagePullBackOff"}
 	containerTerminatedReasons = []string{"OOMKilled", "Completed", "Error", "ContainerCannotRun"}
 
-	descPodInfo = newMetricFamilyDef(
-		"kube_pod_info",
-		"Information about pod.",
-		append(descPodLabelsDefaultLabels, "host_ip", "pod_ip", "uid", "node", "created_by_kind", "created_by_name"),
-		nil,
-	)
-	descPodStartTime = newMetricFamilyDef(
-		"kube_pod_start_time",
-		"Start time in unix timestamp for a pod.",
-		descPodLabelsDefaultLabels,
-		nil,
-	)
-	descPodCompletionTime = newMetricFamilyDef(
-		"kube_pod_completion_time",
-		"Completion time in unix timestamp for a pod.",
-		descPodLabelsDefaultLabels,
-		nil,
-	)
-	descPodOwner = newMetricFamilyDef(
-		"kube_pod_owner",
-		"Information about the Pod's owner.",
-		append(descPodLabelsDefaultLabels, "owner_kind", "owner_name", "owner_is_controller"),
-		nil,
-	)
-	descPodLabels = newMetricFamilyDef(
-		descPodLabelsName,
-		descPodLabelsHelp,
-		descPodLabelsDefaultLabels,
-		nil,
-	)
-	descPodCreated = newMetricFamilyDef(
-		"kube_pod_created",
-		"Unix creation timestamp",
-		descPodLabelsDefaultLabels,
-		nil,
-	)
-	descPodStatusScheduledTime = newMetricFamilyDef(
-		"kube_pod_status_scheduled_time",
-		"Unix timestamp when pod moved into scheduled status",
-		descPodLabelsDefaultLabels,
-		nil,
-	)
-	descPodStatusPhase = newMetricFamilyDef(
-		"kube_pod_status_phase",
-		"The pods current phase.",
-		append(descPodLabelsDefaultLabels, "phase"),
-		nil,
-	)
-	descPodStatusReady = newMetricFamilyDef(
-		"kube_pod_status_ready",
-		"Describes whether the pod is ready to serve requests.",
-		append(descPodLabelsDefaultLabels, "condition"),
-		nil,
-	)
-	descPodStatusScheduled = newMetricFamilyDef(
-		"kube_pod_status_scheduled",
-		"Describes the status of the scheduling process for the pod.",
-		append(descPodLabelsDefaultLabels, "condition"),
-		nil,
-	)
-	descPodContainerInfo = newMetricFamilyDef(
-		"kube_pod_container_info",
-		"Information about a container in a pod.",
-		append(descPodLabelsDefaultLabels, "container", "image", "image_id", "container_id"),
-		nil,
-	)
-	descPodContainerStatusWaiting = newMetricFamilyDef(
-		"kube_pod_container_status_waiting",
-		"Describes whether the container is currently in waiting state.",
-		append(descPodLabelsDefaultLabels, "container"),
-		nil,
-	)
-	descPodContainerStatusWaitingReason = newMetricFamilyDef(
-		"kube_pod_container_status_waiting_reason",
-		"Describes the reason the container is currently in waiting state.",
-		append(descPodLabelsDefaultLabels, "container", "reason"),
-		nil,
-	)
-	descPodContainerStatusRunning = newMetricFamilyDef(
-		"kube_pod_container_status_running",
-		"Describes whether the container is currently in running state.",
-		append(descPodLabelsDefaultLabels, "container"),
-		nil,
-	)
-	descPodContainerStatusTerminated = newMetricFamilyDef(
-		"kube_pod_container_status_terminated",
-		"Describes whether the container is currently in terminated state.",
-		append(descPodLabelsDefaultLabels, "container"),
-		nil,
-	)
-	descPodContainerStatusTerminatedReason = newMetricFamilyDef(
-		"kube_pod_container_status_terminated_reason",
-		"Describes the reason the container is currently in terminated state.",
-		append(descPodLabelsDefaultLabels, "container", "reason"),
-		nil,
-	)
-	descPodContainerStatusLastTerminatedReason = newMetricFamilyDef(
-		"kube_pod_container_status_last_terminated_reason",
-		"Describes the last reason the container was in terminated state.",
-		append(descPodLabelsDefaultLabels, "container", "reason"),
-		nil,
-	)
-
-	descPodContainerStatusReady = newMetricFamilyDef(
-		"kube_pod_container_status_ready",
-		"Describes whether the containers readiness check succeeded.",
-		append(descPodLabelsDefaultLabels, "container"),
-		nil,
-	)
-	descPodContainerStatusRestarts = newMetricFamilyDef(
-		"kube_pod_container_status_restarts_total",
-		"The number of container restarts per container.",
-		append(descPodLabelsDefaultLabels, "container"),
-		nil,
-	)
-	descPodContainerResourceRequests = newMetricFamilyDef(
-		"kube_pod_container_resource_requests",
-		"The number of requested request resource by a container.",
-		append(descPodLabelsDefaultLabels, "container", "node", "resource", "unit"),
-		nil,
-	)
-	descPodContainerResourceLimits = newMetricFamilyDef(
-		"kube_pod_container_resource_limits",
-		"The number of requested limit resource by a container.",
-		append(descPodLabelsDefaultLabels, "container", "node", "resource", "unit"),
-		nil,
-	)
-	descPodContainerResourceRequestsCPUCores = newMetricFamilyDef(
-		"kube_pod_container_resource_requests_cpu_cores",
-		"The number of requested cpu cores by a container.",
-		append(descPodLabelsDefaultLabels, "container", "node"),
-		nil,
-	)
-	descPodContainerResourceRequestsMemoryBytes = newMetricFamilyDef(
-		"kube_pod_container_resource_requests_memory_bytes",
-		"The number of requested memory bytes by a container.",
-		append(descPodLabelsDefaultLabels, "container", "node"),
-		nil,
-	)
-	descPodContainerResourceLimitsCPUCores = newMetricFamilyDef(
-		"kube_pod_container_resource_limits_cpu_cores",
-		"The limit on cpu cores to be used by a container.",
-		append(descPodLabelsDefaultLabels, "container", "node"),
-		nil,
-	)
-	descPodContainerResourceLimitsMemoryBytes = newMetricFamilyDef(
-		"kube_pod_container_resource_limits_memory_bytes",
-		"The limit on memory to be used by a container in bytes.",
-		append(descPodLabelsDefaultLabels, "container", "node"),
-		nil,
-	)
-	descPodSpecVolumesPersistentVolumeClaimsInfo = newMetricFamilyDef(
-		"kube_pod_spec_volumes_persistentvolumeclaims_info",
-		"Information about persistentvolumeclaim volumes in a pod.",
-		append(descPodLabelsDefaultLabels, "volume", "persistentvolumeclaim"),
-		nil,
-	)
-	descPodSpecVolumesPersistentVolumeClaimsReadOnly = newMetricFamilyDef(
-		"kube_pod_spec_volumes_persistentvolumeclaims_readonly",
-		"Describes whether a persistentvolumeclaim is mounted read only.",
-		append(descPodLabelsDefaultLabels, "volume", "persistentvolumeclaim"),
-		nil,
-	)
-)
+	// podNonGenericResourceMetricFamilies can be disabled via
+	// --disable-pod-non-generic-resource-metrics.
+	podNonGenericResourceMetricFamilies = []metricFamilyDef{
+		{
+			Name: "kube_pod_container_resource_requests_cpu_cores",
+			Type: metrics.MetricTypeGauge,
+			Help: "The number of requested cpu cores by a container.",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				for _, c := range p.Spec.Containers {
+					if cpu, ok := c.Resources.Requests[v1.ResourceCPU]; ok {
+						ms = append(ms, &metrics.Metric{
+							LabelKeys:   []string{"container", "node"},
+							LabelValues: []string{c.Name, p.Spec.NodeName},
+							Value:       float64(cpu.MilliValue()) / 1000,
+						})
+					}
+				}
 
-func createPodListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
-	return cache.ListWatch{
-		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
-			return kubeClient.CoreV1().Pods(ns).List(opts)
+				return ms
+			}),
 		},
-		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
-			return kubeClient.CoreV1().Pods(ns).Watch(opts)
+		{
+			Name: "kube_pod_container_resource_requests_memory_bytes",
+			Type: metrics.MetricTypeGauge,
+			Help: "The number of requested memory bytes by a container.",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				for _, c := range p.Spec.Containers {
+					if mem, ok := c.Resources.Requests[v1.ResourceMemory]; ok {
+						ms = append(ms, &metrics.Metric{
+							LabelKeys:   []string{"container", "node"},
+							LabelValues: []string{c.Name, p.Spec.NodeName},
+							Value:       float64(mem.Value()),
+						})
+					}
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_pod_container_resource_limits_cpu_cores",
+			Type: metrics.MetricTypeGauge,
+			Help: "The limit on cpu cores to be used by a container.",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				for _, c := range p.Spec.Containers {
+					if cpu, ok := c.Resources.Limits[v1.ResourceCPU]; ok {
+						ms = append(ms, &metrics.Metric{
+							LabelKeys:   []string{"container", "node"},
+							LabelValues: []string{c.Name, p.Spec.NodeName},
+							Value:       float64(cpu.MilliValue()) / 1000,
+						})
+					}
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_pod_container_resource_limits_memory_bytes",
+			Type: metrics.MetricTypeGauge,
+			Help: "The limit on memory to be used by a container in bytes.",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				for _, c := range p.Spec.Containers {
+					if mem, ok := c.Resources.Limits[v1.ResourceMemory]; ok {
+						ms = append(ms, &metrics.Metric{
+							LabelKeys:   []string{"container", "node"},
+							LabelValues: []string{c.Name, p.Spec.NodeName},
+							Value:       float64(mem.Value()),
+						})
+					}
+				}
+
+				return ms
+			}),
 		},
 	}
-}
+)
 
-// TODO: Not necessary without HELP and TYPE line
-// Describe implements the prometheus.Collector interface.
-// func (c *podCollector) Describe(ch chan<- *desc) {
-// 	ch <- descPodInfo
-// 	ch <- descPodStartTime
-// 	ch <- descPodCompletionTime
-// 	ch <- descPodOwner
-// 	ch <- descPodLabels
-// 	ch <- descPodCreated
-// 	ch <- descPodStatusScheduledTime
-// 	ch <- descPodStatusPhase
-// 	ch <- descPodStatusReady
-// 	ch <- descPodStatusScheduled
-// 	ch <- descPodContainerInfo
-// 	ch <- descPodContainerStatusWaiting
-// 	ch <- descPodContainerStatusWaitingReason
-// 	ch <- descPodContainerStatusRunning
-// 	ch <- descPodContainerStatusTerminated
-// 	ch <- descPodContainerStatusTerminatedReason
-// 	ch <- descPodContainerStatusReady
-// 	ch <- descPodContainerStatusRestarts
-// 	ch <- descPodSpecVolumesPersistentVolumeClaimsInfo
-// 	ch <- descPodSpecVolumesPersistentVolumeClaimsReadOnly
-// 	ch <- descPodContainerResourceRequests
-// 	ch <- descPodContainerResourceLimits
-// 	ch <- descPodContainerStatusLastTerminatedReason
-//
-// 	if !c.opts.DisablePodNonGenericResourceMetrics {
-// 		ch <- descPodContainerResourceRequestsCPUCores
-// 		ch <- descPodContainerResourceRequestsMemoryBytes
-// 		ch <- descPodContainerResourceLimitsCPUCores
-// 		ch <- descPodContainerResourceLimitsMemoryBytes
-// 	}
-// }
-
-func podLabelsDesc(labelKeys []string) *metricFamilyDef {
-	return newMetricFamilyDef(
-		descPodLabelsName,
-		descPodLabelsHelp,
-		append(descPodLabelsDefaultLabels, labelKeys...),
-		nil,
-	)
-}
+func podMetricFamilies(allowedLabels, allowedAnnotations keyAllowList) []metricFamilyDef {
+	return []metricFamilyDef{
+		{
+			Name: "kube_pod_info",
+			Type: metrics.MetricTypeGauge,
+			Help: "Information about pod.",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metrics.Metric {
+				createdBy := metav1.GetControllerOf(p)
+				createdByKind := "<none>"
+				createdByName := "<none>"
+				if createdBy != nil {
+					if createdBy.Kind != "" {
+						createdByKind = createdBy.Kind
+					}
+					if createdBy.Name != "" {
+						createdByName = createdBy.Name
+					}
+				}
 
-func generatePodMetrics(disablePodNonGenericResourceMetrics bool, obj interface{}) []*metrics.Metric {
-	ms := []*metrics.Metric{}
+				return []*metrics.Metric{{
+					LabelKeys:   []string{"host_ip", "pod_ip", "uid", "node", "created_by_kind", "created_by_name"},
+					LabelValues: []string{p.Status.HostIP, p.Status.PodIP, string(p.UID), p.Spec.NodeName, createdByKind, createdByName},
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_pod_start_time",
+			Type: metrics.MetricTypeGauge,
+			Help: "Start time in unix timestamp for a pod.",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				if p.Status.StartTime != nil {
+					ms = append(ms, &metrics.Metric{
+						Value: float64((*(p.Status.StartTime)).Unix()),
+					})
+				}
 
-	// TODO: Refactor
-	pPointer := obj.(*v1.Pod)
-	p := *pPointer
+				return ms
+			}),
+		},
+		{
+			Name: "kube_pod_completion_time",
+			Type: metrics.MetricTypeGauge,
+			Help: "Completion time in unix timestamp for a pod.",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				var lastFinishTime float64
+				for _, cs := range p.Status.ContainerStatuses {
+					if cs.State.Terminated != nil {
+						if lastFinishTime == 0 || lastFinishTime < float64(cs.State.Terminated.FinishedAt.Unix()) {
+							lastFinishTime = float64(cs.State.Terminated.FinishedAt.Unix())
+						}
+					}
+				}
 
-	nodeName := p.Spec.NodeName
-	addConstMetric := func(desc *metricFamilyDef, v float64, lv ...string) {
-		lv = append([]string{p.Namespace, p.Name}, lv...)
+				if lastFinishTime > 0 {
+					ms = append(ms, &metrics.Metric{
+						Value: lastFinishTime,
+					})
+				}
 
-		m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, lv, v)
-		if err != nil {
-			panic(err)
-		}
+				return ms
+			}),
+		},
+		{
+			Name: "kube_pod_owner",
+			Type: metrics.MetricTypeGauge,
+			Help: "Information about the Pod's owner.",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metrics.Metric {
+				owners := p.GetOwnerReferences()
+
+				if len(owners) == 0 {
+					return []*metrics.Metric{{
+						LabelKeys:   []string{"owner_kind", "owner_name", "owner_is_controller"},
+						LabelValues: []string{"<none>", "<none>", "<none>"},
+						Value:       1,
+					}}
+				}
 
-		ms = append(ms, m)
-	}
-	addGauge := func(desc *metricFamilyDef, v float64, lv ...string) {
-		addConstMetric(desc, v, lv...)
-	}
-	addCounter := func(desc *metricFamilyDef, v float64, lv ...string) {
-		addConstMetric(desc, v, lv...)
-	}
+				ms := make([]*metrics.Metric, len(owners))
 
-	createdBy := metav1.GetControllerOf(&p)
-	createdByKind := "<none>"
-	createdByName := "<none>"
-	if createdBy != nil {
-		if createdBy.Kind != "" {
-			createdByKind = createdBy.Kind
-		}
-		if createdBy.Name != "" {
-			createdByName = createdBy.Name
-		}
-	}
+				for i, owner := range owners {
+					ownerIsController := "false"
+					if owner.Controller != nil {
+						ownerIsController = strconv.FormatBool(*owner.Controller)
+					}
 
-	if p.Status.StartTime != nil {
-		addGauge(descPodStartTime, float64((*(p.Status.StartTime)).Unix()))
-	}
+					ms[i] = &metrics.Metric{
+						LabelKeys:   []string{"owner_kind", "owner_name", "owner_is_controller"},
+						LabelValues: []string{owner.Kind, owner.Name, ownerIsController},
+						Value:       1,
+					}
+				}
 
-	addGauge(descPodInfo, 1, p.Status.HostIP, p.Status.PodIP, string(p.UID), nodeName, createdByKind, createdByName)
-
-	owners := p.GetOwnerReferences()
-	if len(owners) == 0 {
-		addGauge(descPodOwner, 1, "<none>", "<none>", "<none>")
-	} else {
-		for _, owner := range owners {
-			if owner.Controller != nil {
-				addGauge(descPodOwner, 1, owner.Kind, owner.Name, strconv.FormatBool(*owner.Controller))
-			} else {
-				addGauge(descPodOwner, 1, owner.Kind, owner.Name, "false")
-			}
-		}
-	}
+				return ms
+			}),
+		},
+		{
+			Name: "kube_pod_spec_service_account",
+			Type: metrics.MetricTypeGauge,
+			Help: "The service account the pod runs as.",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metrics.Metric {
+				serviceAccount := p.Spec.ServiceAccountName
+				if serviceAccount == "" {
+					serviceAccount = "default"
+				}
 
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(p.Labels)
-	addGauge(podLabelsDesc(labelKeys), 1, labelValues...)
-
-	if phase := p.Status.Phase; phase != "" {
-		addGauge(descPodStatusPhase, boolFloat64(phase == v1.PodPending), string(v1.PodPending))
-		addGauge(descPodStatusPhase, boolFloat64(phase == v1.PodSucceeded), string(v1.PodSucceeded))
-		addGauge(descPodStatusPhase, boolFloat64(phase == v1.PodFailed), string(v1.PodFailed))
-		// This logic is directly copied from: https://github.com/kubernetes/kubernetes/blob/d39bfa0d138368bbe72b0eaf434501dcb4ec9908/pkg/printers/internalversion/printers.go#L597-L601
-		// For more info, please go to: https://github.com/kubernetes/kube-state-metrics/issues/410
-		addGauge(descPodStatusPhase, boolFloat64(phase == v1.PodRunning && !(p.DeletionTimestamp != nil && p.Status.Reason == node.NodeUnreachablePodReason)), string(v1.PodRunning))
-		addGauge(descPodStatusPhase, boolFloat64(phase == v1.PodUnknown || (p.DeletionTimestamp != nil && p.Status.Reason == node.NodeUnreachablePodReason)), string(v1.PodUnknown))
-	}
+				return []*metrics.Metric{{
+					LabelKeys:   []string{"serviceaccount"},
+					LabelValues: []string{serviceAccount},
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: descPodLabelsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descPodLabelsHelp,
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metrics.Metric {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(p.Labels, allowedLabels)
+				return []*metrics.Metric{{
+					LabelKeys:   labelKeys,
+					LabelValues: labelValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: descPodAnnotationsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descPodAnnotationsHelp,
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metrics.Metric {
+				annotationKeys, annotationValues := kubeAnnotationsToPrometheusAnnotations(p.Annotations, allowedAnnotations)
+				return []*metrics.Metric{{
+					LabelKeys:   annotationKeys,
+					LabelValues: annotationValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_pod_created",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				if !p.CreationTimestamp.IsZero() {
+					ms = append(ms, &metrics.Metric{
+						Value: float64(p.CreationTimestamp.Unix()),
+					})
+				}
 
-	if !p.CreationTimestamp.IsZero() {
-		addGauge(descPodCreated, float64(p.CreationTimestamp.Unix()))
-	}
+				return ms
+			}),
+		},
+		{
+			Name: "kube_pod_status_scheduled_time",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix timestamp when pod moved into scheduled status",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				for _, c := range p.Status.Conditions {
+					if c.Type == v1.PodScheduled && c.Status == v1.ConditionTrue {
+						ms = append(ms, &metrics.Metric{
+							Value: float64(c.LastTransitionTime.Unix()),
+						})
+					}
+				}
 
-	for _, c := range p.Status.Conditions {
-		switch c.Type {
-		case v1.PodReady:
-			ms = append(ms, addConditionMetrics(descPodStatusReady, c.Status, p.Namespace, p.Name)...)
-		case v1.PodScheduled:
-			ms = append(ms, addConditionMetrics(descPodStatusScheduled, c.Status, p.Namespace, p.Name)...)
-			if c.Status == v1.ConditionTrue {
-				addGauge(descPodStatusScheduledTime, float64(c.LastTransitionTime.Unix()))
-			}
-		}
-	}
+				return ms
+			}),
+		},
+		{
+			Name: "kube_pod_status_phase",
+			Type: metrics.MetricTypeGauge,
+			Help: "The pods current phase.",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metrics.Metric {
+				phase := p.Status.Phase
+				if phase == "" {
+					return []*metrics.Metric{}
+				}
 
-	waitingReason := func(cs v1.ContainerStatus, reason string) bool {
-		if cs.State.Waiting == nil {
-			return false
-		}
-		return cs.State.Waiting.Reason == reason
-	}
+				phases := []struct {
+					v bool
+					n string
+				}{
+					{phase == v1.PodPending, string(v1.PodPending)},
+					{phase == v1.PodSucceeded, string(v1.PodSucceeded)},
+					{phase == v1.PodFailed, string(v1.PodFailed)},
+					// This logic is directly copied from: https://github.com/kubernetes/kubernetes/blob/d39bfa0d138368bbe72b0eaf434501dcb4ec9908/pkg/printers/internalversion/printers.go#L597-L601
+					// For more info, please go to: https://github.com/kubernetes/kube-state-metrics/issues/410
+					{phase == v1.PodRunning && !(p.DeletionTimestamp != nil && p.Status.Reason == node.NodeUnreachablePodReason), string(v1.PodRunning)},
+					{phase == v1.PodUnknown || (p.DeletionTimestamp != nil && p.Status.Reason == node.NodeUnreachablePodReason), string(v1.PodUnknown)},
+				}
 
-	terminationReason := func(cs v1.ContainerStatus, reason string) bool {
-		if cs.State.Terminated == nil {
-			return false
-		}
-		return cs.State.Terminated.Reason == reason
-	}
+				ms := make([]*metrics.Metric, len(phases))
 
-	lastTerminationReason := func(cs v1.ContainerStatus, reason string) bool {
-		if cs.LastTerminationState.Terminated == nil {
-			return false
-		}
-		return cs.LastTerminationState.Terminated.Reason == reason
-	}
+				for i, p := range phases {
+					ms[i] = &metrics.Metric{
+						LabelKeys:   []string{"phase"},
+						LabelValues: []string{p.n},
+						Value:       boolFloat64(p.v),
+					}
+				}
 
-	var lastFinishTime float64
+				return ms
+			}),
+		},
+		{
+			Name: "kube_pod_status_ready",
+			Type: metrics.MetricTypeGauge,
+			Help: "Describes whether the pod is ready to serve requests.",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				for _, c := range p.Status.Conditions {
+					if c.Type == v1.PodReady {
+						ms = append(ms, addConditionMetrics(c.Status, "condition")...)
+					}
+				}
 
-	for _, cs := range p.Status.ContainerStatuses {
-		addGauge(descPodContainerInfo, 1,
-			cs.Name, cs.Image, cs.ImageID, cs.ContainerID,
-		)
-		addGauge(descPodContainerStatusWaiting, boolFloat64(cs.State.Waiting != nil), cs.Name)
-		for _, reason := range containerWaitingReasons {
-			addGauge(descPodContainerStatusWaitingReason, boolFloat64(waitingReason(cs, reason)), cs.Name, reason)
-		}
-		addGauge(descPodContainerStatusRunning, boolFloat64(cs.State.Running != nil), cs.Name)
-		addGauge(descPodContainerStatusTerminated, boolFloat64(cs.State.Terminated != nil), cs.Name)
-		for _, reason := range containerTerminatedReasons {
-			addGauge(descPodContainerStatusTerminatedReason, boolFloat64(terminationReason(cs, reason)), cs.Name, reason)
-		}
-		for _, reason := range containerTerminatedReasons {
-			addGauge(descPodContainerStatusLastTerminatedReason, boolFloat64(lastTerminationReason(cs, reason)), cs.Name, reason)
-		}
-		addGauge(descPodContainerStatusReady, boolFloat64(cs.Ready), cs.Name)
-		addCounter(descPodContainerStatusRestarts, float64(cs.RestartCount), cs.Name)
+				return ms
+			}),
+		},
+		{
+			Name: "kube_pod_status_scheduled",
+			Type: metrics.MetricTypeGauge,
+			Help: "Describes the status of the scheduling process for the pod.",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				for _, c := range p.Status.Conditions {
+					if c.Type == v1.PodScheduled {
+						ms = append(ms, addConditionMetrics(c.Status, "condition")...)
+					}
+				}
 
-		if cs.State.Terminated != nil {
-			if lastFinishTime == 0 || lastFinishTime < float64(cs.State.Terminated.FinishedAt.Unix()) {
-				lastFinishTime = float64(cs.State.Terminated.FinishedAt.Unix())
-			}
-		}
-	}
+				return ms
+			}),
+		},
+		{
+			Name: "kube_pod_container_info",
+			Type: metrics.MetricTypeGauge,
+			Help: "Information about a container in a pod.",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				for _, cs := range p.Status.ContainerStatuses {
+					ms = append(ms, &metrics.Metric{
+						LabelKeys:   []string{"container", "image", "image_id", "container_id"},
+						LabelValues: []string{cs.Name, cs.Image, cs.ImageID, cs.ContainerID},
+						Value:       1,
+					})
+				}
 
-	if lastFinishTime > 0 {
-		addGauge(descPodCompletionTime, lastFinishTime)
-	}
+				return ms
+			}),
+		},
+		{
+			Name: "kube_pod_container_status_waiting",
+			Type: metrics.MetricTypeGauge,
+			Help: "Describes whether the container is currently in waiting state.",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				for _, cs := range p.Status.ContainerStatuses {
+					ms = append(ms, &metrics.Metric{
+						LabelKeys:   []string{"container"},
+						LabelValues: []string{cs.Name},
+						Value:       boolFloat64(cs.State.Waiting != nil),
+					})
+				}
 
-	if !disablePodNonGenericResourceMetrics {
-		for _, c := range p.Spec.Containers {
-			req := c.Resources.Requests
-			lim := c.Resources.Limits
+				return ms
+			}),
+		},
+		{
+			Name: "kube_pod_container_status_waiting_reason",
+			Type: metrics.MetricTypeGauge,
+			Help: "Describes the reason the container is currently in waiting state.",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				for _, cs := range p.Status.ContainerStatuses {
+					for _, reason := range containerWaitingReasons {
+						ms = append(ms, &metrics.Metric{
+							LabelKeys:   []string{"container", "reason"},
+							LabelValues: []string{cs.Name, reason},
+							Value:       boolFloat64(waitingReason(cs, reason)),
+						})
+					}
+				}
 
-			if cpu, ok := req[v1.ResourceCPU]; ok {
-				addGauge(descPodContainerResourceRequestsCPUCores, float64(cpu.MilliValue())/1000,
-					c.Name, nodeName)
-			}
-			if mem, ok := req[v1.ResourceMemory]; ok {
-				addGauge(descPodContainerResourceRequestsMemoryBytes, float64(mem.Value()),
-					c.Name, nodeName)
-			}
+				return ms
+			}),
+		},
+		{
+			Name: "kube_pod_container_status_running",
+			Type: metrics.MetricTypeGauge,
+			Help: "Describes whether the container is currently in running state.",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				for _, cs := range p.Status.ContainerStatuses {
+					ms = append(ms, &metrics.Metric{
+						LabelKeys:   []string{"container"},
+						LabelValues: []string{cs.Name},
+						Value:       boolFloat64(cs.State.Running != nil),
+					})
+				}
 
-			if cpu, ok := lim[v1.ResourceCPU]; ok {
-				addGauge(descPodContainerResourceLimitsCPUCores, float64(cpu.MilliValue())/1000,
-					c.Name, nodeName)
-			}
+				return ms
+			}),
+		},
+		{
+			Name: "kube_pod_container_status_terminated",
+			Type: metrics.MetricTypeGauge,
+			Help: "Describes whether the container is currently in terminated state.",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				for _, cs := range p.Status.ContainerStatuses {
+					ms = append(ms, &metrics.Metric{
+						LabelKeys:   []string{"container"},
+						LabelValues: []string{cs.Name},
+						Value:       boolFloat64(cs.State.Terminated != nil),
+					})
+				}
 
-			if mem, ok := lim[v1.ResourceMemory]; ok {
-				addGauge(descPodContainerResourceLimitsMemoryBytes, float64(mem.Value()),
-					c.Name, nodeName)
-			}
-		}
-	}
+				return ms
+			}),
+		},
+		{
+			Name: "kube_pod_container_status_terminated_reason",
+			Type: metrics.MetricTypeGauge,
+			Help: "Describes the reason the container is currently in terminated state.",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				for _, cs := range p.Status.ContainerStatuses {
+					for _, reason := range containerTerminatedReasons {
+						ms = append(ms, &metrics.Metric{
+							LabelKeys:   []string{"container", "reason"},
+							LabelValues: []string{cs.Name, reason},
+							Value:       boolFloat64(terminationReason(cs, reason)),
+						})
+					}
+				}
 
-	for _, c := range p.Spec.Containers {
-		req := c.Resources.Requests
-		lim := c.Resources.Limits
-
-		for resourceName, val := range req {
-			switch resourceName {
-			case v1.ResourceCPU:
-				addGauge(descPodContainerResourceRequests, float64(val.MilliValue())/1000,
-					c.Name, nodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitCore))
-			case v1.ResourceStorage:
-				fallthrough
-			case v1.ResourceEphemeralStorage:
-				fallthrough
-			case v1.ResourceMemory:
-				addGauge(descPodContainerResourceRequests, float64(val.Value()),
-					c.Name, nodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitByte))
-			default:
-				if helper.IsHugePageResourceName(resourceName) {
-					addGauge(descPodContainerResourceRequests, float64(val.Value()),
-						c.Name, nodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitByte))
+				return ms
+			}),
+		},
+		{
+			Name: "kube_pod_container_status_last_terminated_reason",
+			Type: metrics.MetricTypeGauge,
+			Help: "Describes the last reason the container was in terminated state.",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				for _, cs := range p.Status.ContainerStatuses {
+					for _, reason := range containerTerminatedReasons {
+						ms = append(ms, &metrics.Metric{
+							LabelKeys:   []string{"container", "reason"},
+							LabelValues: []string{cs.Name, reason},
+							Value:       boolFloat64(lastTerminationReason(cs, reason)),
+						})
+					}
 				}
-				if helper.IsAttachableVolumeResourceName(resourceName) {
-					addGauge(descPodContainerResourceRequests, float64(val.Value()),
-						c.Name, nodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitByte))
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_pod_container_status_ready",
+			Type: metrics.MetricTypeGauge,
+			Help: "Describes whether the containers readiness check succeeded.",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				for _, cs := range p.Status.ContainerStatuses {
+					ms = append(ms, &metrics.Metric{
+						LabelKeys:   []string{"container"},
+						LabelValues: []string{cs.Name},
+						Value:       boolFloat64(cs.Ready),
+					})
 				}
-				if helper.IsExtendedResourceName(resourceName) {
-					addGauge(descPodContainerResourceRequests, float64(val.Value()),
-						c.Name, nodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitInteger))
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_pod_container_status_restarts_total",
+			Type: metrics.MetricTypeCounter,
+			Help: "The number of container restarts per container.",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				for _, cs := range p.Status.ContainerStatuses {
+					ms = append(ms, &metrics.Metric{
+						LabelKeys:   []string{"container"},
+						LabelValues: []string{cs.Name},
+						Value:       float64(cs.RestartCount),
+					})
 				}
-			}
-		}
 
-		for resourceName, val := range lim {
-			switch resourceName {
-			case v1.ResourceCPU:
-				addGauge(descPodContainerResourceLimits, float64(val.MilliValue())/1000,
-					c.Name, nodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitCore))
-			case v1.ResourceStorage:
-				fallthrough
-			case v1.ResourceEphemeralStorage:
-				fallthrough
-			case v1.ResourceMemory:
-				addGauge(descPodContainerResourceLimits, float64(val.Value()),
-					c.Name, nodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitByte))
-			default:
-				if helper.IsHugePageResourceName(resourceName) {
-					addGauge(descPodContainerResourceLimits, float64(val.Value()),
-						c.Name, nodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitByte))
+				return ms
+			}),
+		},
+		{
+			Name: "kube_pod_container_resource_requests",
+			Type: metrics.MetricTypeGauge,
+			Help: "The number of requested request resource by a container.",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				for _, c := range p.Spec.Containers {
+					ms = append(ms, podContainerResourceMetrics(c.Name, p.Spec.NodeName, c.Resources.Requests)...)
 				}
-				if helper.IsAttachableVolumeResourceName(resourceName) {
-					addGauge(descPodContainerResourceLimits, float64(val.Value()),
-						c.Name, nodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitByte))
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_pod_container_resource_limits",
+			Type: metrics.MetricTypeGauge,
+			Help: "The number of requested limit resource by a container.",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				for _, c := range p.Spec.Containers {
+					ms = append(ms, podContainerResourceMetrics(c.Name, p.Spec.NodeName, c.Resources.Limits)...)
 				}
-				if helper.IsExtendedResourceName(resourceName) {
-					addGauge(descPodContainerResourceLimits, float64(val.Value()),
-						c.Name, nodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitInteger))
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_pod_spec_volumes_persistentvolumeclaims_info",
+			Type: metrics.MetricTypeGauge,
+			Help: "Information about persistentvolumeclaim volumes in a pod.",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				for _, v := range p.Spec.Volumes {
+					if v.PersistentVolumeClaim != nil {
+						ms = append(ms, &metrics.Metric{
+							LabelKeys:   []string{"volume", "persistentvolumeclaim"},
+							LabelValues: []string{v.Name, v.PersistentVolumeClaim.ClaimName},
+							Value:       1,
+						})
+					}
 				}
-			}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_pod_spec_volumes_persistentvolumeclaims_readonly",
+			Type: metrics.MetricTypeGauge,
+			Help: "Describes whether a persistentvolumeclaim is mounted read only.",
+			GenerateFunc: wrapPodFunc(func(p *v1.Pod) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				for _, v := range p.Spec.Volumes {
+					if v.PersistentVolumeClaim != nil {
+						ms = append(ms, &metrics.Metric{
+							LabelKeys:   []string{"volume", "persistentvolumeclaim"},
+							LabelValues: []string{v.Name, v.PersistentVolumeClaim.ClaimName},
+							Value:       boolFloat64(v.PersistentVolumeClaim.ReadOnly),
+						})
+					}
+				}
+
+				return ms
+			}),
+		},
+	}
+}
+
+func createPodListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
+	return cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return kubeClient.CoreV1().Pods(ns).List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return kubeClient.CoreV1().Pods(ns).Watch(opts)
+		},
+	}
+}
+
+func wrapPodFunc(f func(*v1.Pod) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		pod := obj.(*v1.Pod)
+
+		ms := f(pod)
+
+		for _, m := range ms {
+			m.LabelKeys = append(descPodLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{pod.Namespace, pod.Name}, m.LabelValues...)
 		}
+
+		return ms
 	}
+}
+
+// podContainerResourceMetrics returns one metric per resource of the given
+// resource list of a container, labeled with the resource name and unit.
+func podContainerResourceMetrics(containerName, nodeName string, res v1.ResourceList) []*metrics.Metric {
+	ms := []*metrics.Metric{}
 
-	for _, v := range p.Spec.Volumes {
-		if v.PersistentVolumeClaim != nil {
-			addGauge(descPodSpecVolumesPersistentVolumeClaimsInfo, 1, v.Name, v.PersistentVolumeClaim.ClaimName)
-			readOnly := 0.0
-			if v.PersistentVolumeClaim.ReadOnly {
-				readOnly = 1.0
+	addResource := func(resourceName v1.ResourceName, unit constant.ResourceUnit, val float64) {
+		ms = append(ms, &metrics.Metric{
+			LabelKeys:   []string{"container", "node", "resource", "unit"},
+			LabelValues: []string{containerName, nodeName, sanitizeLabelName(string(resourceName)), string(unit)},
+			Value:       val,
+		})
+	}
+
+	for resourceName, val := range res {
+		switch resourceName {
+		case v1.ResourceCPU:
+			addResource(resourceName, constant.UnitCore, float64(val.MilliValue())/1000)
+		case v1.ResourceStorage:
+			fallthrough
+		case v1.ResourceEphemeralStorage:
+			fallthrough
+		case v1.ResourceMemory:
+			addResource(resourceName, constant.UnitByte, float64(val.Value()))
+		default:
+			if helper.IsHugePageResourceName(resourceName) {
+				addResource(resourceName, constant.UnitByte, float64(val.Value()))
+			}
+			if helper.IsAttachableVolumeResourceName(resourceName) {
+				addResource(resourceName, constant.UnitByte, float64(val.Value()))
+			}
+			if helper.IsExtendedResourceName(resourceName) {
+				addResource(resourceName, constant.UnitInteger, float64(val.Value()))
 			}
-			addGauge(descPodSpecVolumesPersistentVolumeClaimsReadOnly, readOnly, v.Name, v.PersistentVolumeClaim.ClaimName)
 		}
 	}
 
 	return ms
 }
+
+func waitingReason(cs v1.ContainerStatus, reason string) bool {
+	if cs.State.Waiting == nil {
+		return false
+	}
+	return cs.State.Waiting.Reason == reason
+}
+
+func terminationReason(cs v1.ContainerStatus, reason string) bool {
+	if cs.State.Terminated == nil {
+		return false
+	}
+	return cs.State.Terminated.Reason == reason
+}
+
+func lastTerminationReason(cs v1.ContainerStatus, reason string) bool {
+	if cs.LastTerminationState.Terminated == nil {
+		return false
+	}
+	return cs.LastTerminationState.Terminated.Reason == reason
+}
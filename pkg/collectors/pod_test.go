@@ -17,13 +17,16 @@ limitations under the License.
 package collectors
 
 import (
+	"io/ioutil"
+	"strconv"
 	"testing"
 	"time"
 
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/kube-state-metrics/pkg/metrics"
+	"k8s.io/apimachinery/pkg/types"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
 	"k8s.io/kubernetes/pkg/util/node"
 )
 
@@ -70,6 +73,8 @@ func TestPodCollector(t *testing.T) {
 	// # TYPE kube_pod_completion_time gauge
 	// # HELP kube_pod_owner Information about the Pod's owner.
 	// # TYPE kube_pod_owner gauge
+	// # HELP kube_pod_spec_service_account The service account the pod runs as.
+	// # TYPE kube_pod_spec_service_account gauge
 	// # HELP kube_pod_status_phase The pods current phase.
 	// # TYPE kube_pod_status_phase gauge
 	// # HELP kube_pod_status_ready Describes whether the pod is ready to serve requests.
@@ -535,6 +540,33 @@ kube_pod_container_status_last_terminated_reason{container="container7",namespac
 `,
 			MetricNames: []string{"kube_pod_created", "kube_pod_info", "kube_pod_start_time", "kube_pod_completion_time", "kube_pod_owner"},
 		},
+		{
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod1",
+					Namespace: "ns1",
+				},
+			},
+			Want: metadata + `
+				kube_pod_spec_service_account{namespace="ns1",pod="pod1",serviceaccount="default"} 1
+				`,
+			MetricNames: []string{"kube_pod_spec_service_account"},
+		},
+		{
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod2",
+					Namespace: "ns2",
+				},
+				Spec: v1.PodSpec{
+					ServiceAccountName: "builder",
+				},
+			},
+			Want: metadata + `
+				kube_pod_spec_service_account{namespace="ns2",pod="pod2",serviceaccount="builder"} 1
+				`,
+			MetricNames: []string{"kube_pod_spec_service_account"},
+		},
 		{
 			Obj: &v1.Pod{
 				ObjectMeta: metav1.ObjectMeta{
@@ -1006,11 +1038,103 @@ kube_pod_container_status_last_terminated_reason{container="container7",namespac
 	}
 
 	for i, c := range cases {
-		c.Func = func(obj interface{}) []*metrics.Metric {
-			return generatePodMetrics(false, obj)
-		}
+		c.Func = composeMetricGenFuncs(append(podMetricFamilies(allKeys, allKeys), podNonGenericResourceMetricFamilies...))
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
 	}
 }
+
+// newBenchmarkPodStore returns a store filled with the given number of pods,
+// each with two containers with resource requests and limits.
+func newBenchmarkPodStore(b *testing.B, podCount int) *metricsstore.MetricsStore {
+	families := append(podMetricFamilies(allKeys, allKeys), podNonGenericResourceMetricFamilies...)
+	s := metricsstore.NewMetricsStore(extractMetricFamilyHeaders(families), composeMetricGenFuncs(families))
+
+	for i := 0; i < podCount; i++ {
+		if err := s.Add(newBenchmarkPod(i)); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	return s
+}
+
+func newBenchmarkPod(index int) *v1.Pod {
+	i := strconv.Itoa(index)
+	resources := v1.ResourceRequirements{
+		Requests: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("200m"),
+			v1.ResourceMemory: resource.MustParse("100M"),
+		},
+		Limits: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("200m"),
+			v1.ResourceMemory: resource.MustParse("100M"),
+		},
+	}
+
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "pod" + i,
+			Namespace:         "ns" + strconv.Itoa(index%100),
+			UID:               types.UID("uid" + i),
+			CreationTimestamp: metav1.Unix(1501569018, 0),
+			Labels:            map[string]string{"app": "example" + i},
+		},
+		Spec: v1.PodSpec{
+			NodeName: "node" + strconv.Itoa(index%1000),
+			Containers: []v1.Container{
+				{Name: "container1", Resources: resources},
+				{Name: "container2", Resources: resources},
+			},
+		},
+		Status: v1.PodStatus{
+			Phase:  v1.PodRunning,
+			HostIP: "1.1.1.1",
+			PodIP:  "1.2.3.4",
+			Conditions: []v1.PodCondition{
+				{Type: v1.PodReady, Status: v1.ConditionTrue},
+				{Type: v1.PodScheduled, Status: v1.ConditionTrue},
+			},
+			ContainerStatuses: []v1.ContainerStatus{
+				{Name: "container1", Image: "k8s.gcr.io/hyperkube1", Ready: true},
+				{Name: "container2", Image: "k8s.gcr.io/hyperkube2", Ready: true},
+			},
+		},
+	}
+}
+
+// BenchmarkPodStoreAdd measures generating and storing the metrics of a
+// single pod.
+func BenchmarkPodStoreAdd(b *testing.B) {
+	families := append(podMetricFamilies(allKeys, allKeys), podNonGenericResourceMetricFamilies...)
+	s := metricsstore.NewMetricsStore(extractMetricFamilyHeaders(families), composeMetricGenFuncs(families))
+	pods := make([]*v1.Pod, 1000)
+	for i := range pods {
+		pods[i] = newBenchmarkPod(i)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := s.Add(pods[i%len(pods)]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkPodStoreWriteAll measures a single scrape of a store holding the
+// metrics of 100k pods.
+func BenchmarkPodStoreWriteAll(b *testing.B) {
+	s := newBenchmarkPodStore(b, 100000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := s.WriteAll(ioutil.Discard); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
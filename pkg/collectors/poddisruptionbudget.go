@@ -0,0 +1,127 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"k8s.io/kube-state-metrics/pkg/metrics"
+
+	"k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	descPodDisruptionBudgetLabelsDefaultLabels = []string{"namespace", "poddisruptionbudget"}
+
+	podDisruptionBudgetMetricFamilies = []metricFamilyDef{
+		{
+			Name: "kube_poddisruptionbudget_created",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapPodDisruptionBudgetFunc(func(p *v1beta1.PodDisruptionBudget) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				if !p.CreationTimestamp.IsZero() {
+					ms = append(ms, &metrics.Metric{
+						Value: float64(p.CreationTimestamp.Unix()),
+					})
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_poddisruptionbudget_status_current_healthy",
+			Type: metrics.MetricTypeGauge,
+			Help: "Current number of healthy pods",
+			GenerateFunc: wrapPodDisruptionBudgetFunc(func(p *v1beta1.PodDisruptionBudget) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(p.Status.CurrentHealthy),
+				}}
+			}),
+		},
+		{
+			Name: "kube_poddisruptionbudget_status_desired_healthy",
+			Type: metrics.MetricTypeGauge,
+			Help: "Minimum desired number of healthy pods",
+			GenerateFunc: wrapPodDisruptionBudgetFunc(func(p *v1beta1.PodDisruptionBudget) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(p.Status.DesiredHealthy),
+				}}
+			}),
+		},
+		{
+			Name: "kube_poddisruptionbudget_status_pod_disruptions_allowed",
+			Type: metrics.MetricTypeGauge,
+			Help: "Number of pod disruptions that are currently allowed",
+			GenerateFunc: wrapPodDisruptionBudgetFunc(func(p *v1beta1.PodDisruptionBudget) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(p.Status.PodDisruptionsAllowed),
+				}}
+			}),
+		},
+		{
+			Name: "kube_poddisruptionbudget_status_expected_pods",
+			Type: metrics.MetricTypeGauge,
+			Help: "Total number of pods counted by this disruption budget",
+			GenerateFunc: wrapPodDisruptionBudgetFunc(func(p *v1beta1.PodDisruptionBudget) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(p.Status.ExpectedPods),
+				}}
+			}),
+		},
+		{
+			Name: "kube_poddisruptionbudget_status_observed_generation",
+			Type: metrics.MetricTypeGauge,
+			Help: "Most recent generation observed when updating this PDB status",
+			GenerateFunc: wrapPodDisruptionBudgetFunc(func(p *v1beta1.PodDisruptionBudget) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(p.Status.ObservedGeneration),
+				}}
+			}),
+		},
+	}
+)
+
+func createPodDisruptionBudgetListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
+	return cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return kubeClient.PolicyV1beta1().PodDisruptionBudgets(ns).List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return kubeClient.PolicyV1beta1().PodDisruptionBudgets(ns).Watch(opts)
+		},
+	}
+}
+
+func wrapPodDisruptionBudgetFunc(f func(*v1beta1.PodDisruptionBudget) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		podDisruptionBudget := obj.(*v1beta1.PodDisruptionBudget)
+
+		ms := f(podDisruptionBudget)
+
+		for _, m := range ms {
+			m.LabelKeys = append(descPodDisruptionBudgetLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{podDisruptionBudget.Namespace, podDisruptionBudget.Name}, m.LabelValues...)
+		}
+
+		return ms
+	}
+}
@@ -31,54 +31,124 @@ import (
 
 var (
 	descReplicaSetLabelsDefaultLabels = []string{"namespace", "replicaset"}
-	descReplicaSetCreated             = newMetricFamilyDef(
-		"kube_replicaset_created",
-		"Unix creation timestamp",
-		descReplicaSetLabelsDefaultLabels,
-		nil,
-	)
-	descReplicaSetStatusReplicas = newMetricFamilyDef(
-		"kube_replicaset_status_replicas",
-		"The number of replicas per ReplicaSet.",
-		descReplicaSetLabelsDefaultLabels,
-		nil,
-	)
-	descReplicaSetStatusFullyLabeledReplicas = newMetricFamilyDef(
-		"kube_replicaset_status_fully_labeled_replicas",
-		"The number of fully labeled replicas per ReplicaSet.",
-		descReplicaSetLabelsDefaultLabels,
-		nil,
-	)
-	descReplicaSetStatusReadyReplicas = newMetricFamilyDef(
-		"kube_replicaset_status_ready_replicas",
-		"The number of ready replicas per ReplicaSet.",
-		descReplicaSetLabelsDefaultLabels,
-		nil,
-	)
-	descReplicaSetStatusObservedGeneration = newMetricFamilyDef(
-		"kube_replicaset_status_observed_generation",
-		"The generation observed by the ReplicaSet controller.",
-		descReplicaSetLabelsDefaultLabels,
-		nil,
-	)
-	descReplicaSetSpecReplicas = newMetricFamilyDef(
-		"kube_replicaset_spec_replicas",
-		"Number of desired pods for a ReplicaSet.",
-		descReplicaSetLabelsDefaultLabels,
-		nil,
-	)
-	descReplicaSetMetadataGeneration = newMetricFamilyDef(
-		"kube_replicaset_metadata_generation",
-		"Sequence number representing a specific generation of the desired state.",
-		descReplicaSetLabelsDefaultLabels,
-		nil,
-	)
-	descReplicaSetOwner = newMetricFamilyDef(
-		"kube_replicaset_owner",
-		"Information about the ReplicaSet's owner.",
-		append(descReplicaSetLabelsDefaultLabels, "owner_kind", "owner_name", "owner_is_controller"),
-		nil,
-	)
+
+	replicaSetMetricFamilies = []metricFamilyDef{
+		{
+			Name: "kube_replicaset_created",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapReplicaSetFunc(func(r *v1beta1.ReplicaSet) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				if !r.CreationTimestamp.IsZero() {
+					ms = append(ms, &metrics.Metric{
+						Value: float64(r.CreationTimestamp.Unix()),
+					})
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_replicaset_status_replicas",
+			Type: metrics.MetricTypeGauge,
+			Help: "The number of replicas per ReplicaSet.",
+			GenerateFunc: wrapReplicaSetFunc(func(r *v1beta1.ReplicaSet) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(r.Status.Replicas),
+				}}
+			}),
+		},
+		{
+			Name: "kube_replicaset_status_fully_labeled_replicas",
+			Type: metrics.MetricTypeGauge,
+			Help: "The number of fully labeled replicas per ReplicaSet.",
+			GenerateFunc: wrapReplicaSetFunc(func(r *v1beta1.ReplicaSet) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(r.Status.FullyLabeledReplicas),
+				}}
+			}),
+		},
+		{
+			Name: "kube_replicaset_status_ready_replicas",
+			Type: metrics.MetricTypeGauge,
+			Help: "The number of ready replicas per ReplicaSet.",
+			GenerateFunc: wrapReplicaSetFunc(func(r *v1beta1.ReplicaSet) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(r.Status.ReadyReplicas),
+				}}
+			}),
+		},
+		{
+			Name: "kube_replicaset_status_observed_generation",
+			Type: metrics.MetricTypeGauge,
+			Help: "The generation observed by the ReplicaSet controller.",
+			GenerateFunc: wrapReplicaSetFunc(func(r *v1beta1.ReplicaSet) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(r.Status.ObservedGeneration),
+				}}
+			}),
+		},
+		{
+			Name: "kube_replicaset_spec_replicas",
+			Type: metrics.MetricTypeGauge,
+			Help: "Number of desired pods for a ReplicaSet.",
+			GenerateFunc: wrapReplicaSetFunc(func(r *v1beta1.ReplicaSet) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				if r.Spec.Replicas != nil {
+					ms = append(ms, &metrics.Metric{
+						Value: float64(*r.Spec.Replicas),
+					})
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_replicaset_metadata_generation",
+			Type: metrics.MetricTypeGauge,
+			Help: "Sequence number representing a specific generation of the desired state.",
+			GenerateFunc: wrapReplicaSetFunc(func(r *v1beta1.ReplicaSet) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(r.ObjectMeta.Generation),
+				}}
+			}),
+		},
+		{
+			Name: "kube_replicaset_owner",
+			Type: metrics.MetricTypeGauge,
+			Help: "Information about the ReplicaSet's owner.",
+			GenerateFunc: wrapReplicaSetFunc(func(r *v1beta1.ReplicaSet) []*metrics.Metric {
+				owners := r.GetOwnerReferences()
+
+				if len(owners) == 0 {
+					return []*metrics.Metric{{
+						LabelKeys:   []string{"owner_kind", "owner_name", "owner_is_controller"},
+						LabelValues: []string{"<none>", "<none>", "<none>"},
+						Value:       1,
+					}}
+				}
+
+				ms := make([]*metrics.Metric, len(owners))
+
+				for i, owner := range owners {
+					ownerIsController := "false"
+					if owner.Controller != nil {
+						ownerIsController = strconv.FormatBool(*owner.Controller)
+					}
+
+					ms[i] = &metrics.Metric{
+						LabelKeys:   []string{"owner_kind", "owner_name", "owner_is_controller"},
+						LabelValues: []string{owner.Kind, owner.Name, ownerIsController},
+						Value:       1,
+					}
+				}
+
+				return ms
+			}),
+		},
+	}
 )
 
 func createReplicaSetListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
@@ -92,48 +162,17 @@ func createReplicaSetListWatch(kubeClient clientset.Interface, ns string) cache.
 	}
 }
 
-func generateReplicaSetMetrics(obj interface{}) []*metrics.Metric {
-	ms := []*metrics.Metric{}
-
-	// TODO: Refactor
-	rPointer := obj.(*v1beta1.ReplicaSet)
-	r := *rPointer
+func wrapReplicaSetFunc(f func(*v1beta1.ReplicaSet) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		replicaSet := obj.(*v1beta1.ReplicaSet)
 
-	addGauge := func(desc *metricFamilyDef, v float64, lv ...string) {
-		lv = append([]string{r.Namespace, r.Name}, lv...)
+		ms := f(replicaSet)
 
-		m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, lv, v)
-		if err != nil {
-			panic(err)
+		for _, m := range ms {
+			m.LabelKeys = append(descReplicaSetLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{replicaSet.Namespace, replicaSet.Name}, m.LabelValues...)
 		}
 
-		ms = append(ms, m)
-	}
-	if !r.CreationTimestamp.IsZero() {
-		addGauge(descReplicaSetCreated, float64(r.CreationTimestamp.Unix()))
+		return ms
 	}
-
-	owners := r.GetOwnerReferences()
-	if len(owners) == 0 {
-		addGauge(descReplicaSetOwner, 1, "<none>", "<none>", "<none>")
-	} else {
-		for _, owner := range owners {
-			if owner.Controller != nil {
-				addGauge(descReplicaSetOwner, 1, owner.Kind, owner.Name, strconv.FormatBool(*owner.Controller))
-			} else {
-				addGauge(descReplicaSetOwner, 1, owner.Kind, owner.Name, "false")
-			}
-		}
-	}
-
-	addGauge(descReplicaSetStatusReplicas, float64(r.Status.Replicas))
-	addGauge(descReplicaSetStatusFullyLabeledReplicas, float64(r.Status.FullyLabeledReplicas))
-	addGauge(descReplicaSetStatusReadyReplicas, float64(r.Status.ReadyReplicas))
-	addGauge(descReplicaSetStatusObservedGeneration, float64(r.Status.ObservedGeneration))
-	if r.Spec.Replicas != nil {
-		addGauge(descReplicaSetSpecReplicas, float64(*r.Spec.Replicas))
-	}
-	addGauge(descReplicaSetMetadataGeneration, float64(r.ObjectMeta.Generation))
-
-	return ms
 }
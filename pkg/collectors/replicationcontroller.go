@@ -30,54 +30,100 @@ import (
 var (
 	descReplicationControllerLabelsDefaultLabels = []string{"namespace", "replicationcontroller"}
 
-	descReplicationControllerCreated = newMetricFamilyDef(
-		"kube_replicationcontroller_created",
-		"Unix creation timestamp",
-		descReplicationControllerLabelsDefaultLabels,
-		nil,
-	)
-	descReplicationControllerStatusReplicas = newMetricFamilyDef(
-		"kube_replicationcontroller_status_replicas",
-		"The number of replicas per ReplicationController.",
-		descReplicationControllerLabelsDefaultLabels,
-		nil,
-	)
-	descReplicationControllerStatusFullyLabeledReplicas = newMetricFamilyDef(
-		"kube_replicationcontroller_status_fully_labeled_replicas",
-		"The number of fully labeled replicas per ReplicationController.",
-		descReplicationControllerLabelsDefaultLabels,
-		nil,
-	)
-	descReplicationControllerStatusReadyReplicas = newMetricFamilyDef(
-		"kube_replicationcontroller_status_ready_replicas",
-		"The number of ready replicas per ReplicationController.",
-		descReplicationControllerLabelsDefaultLabels,
-		nil,
-	)
-	descReplicationControllerStatusAvailableReplicas = newMetricFamilyDef(
-		"kube_replicationcontroller_status_available_replicas",
-		"The number of available replicas per ReplicationController.",
-		descReplicationControllerLabelsDefaultLabels,
-		nil,
-	)
-	descReplicationControllerStatusObservedGeneration = newMetricFamilyDef(
-		"kube_replicationcontroller_status_observed_generation",
-		"The generation observed by the ReplicationController controller.",
-		descReplicationControllerLabelsDefaultLabels,
-		nil,
-	)
-	descReplicationControllerSpecReplicas = newMetricFamilyDef(
-		"kube_replicationcontroller_spec_replicas",
-		"Number of desired pods for a ReplicationController.",
-		descReplicationControllerLabelsDefaultLabels,
-		nil,
-	)
-	descReplicationControllerMetadataGeneration = newMetricFamilyDef(
-		"kube_replicationcontroller_metadata_generation",
-		"Sequence number representing a specific generation of the desired state.",
-		descReplicationControllerLabelsDefaultLabels,
-		nil,
-	)
+	replicationControllerMetricFamilies = []metricFamilyDef{
+		{
+			Name: "kube_replicationcontroller_created",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapReplicationControllerFunc(func(r *v1.ReplicationController) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				if !r.CreationTimestamp.IsZero() {
+					ms = append(ms, &metrics.Metric{
+						Value: float64(r.CreationTimestamp.Unix()),
+					})
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_replicationcontroller_status_replicas",
+			Type: metrics.MetricTypeGauge,
+			Help: "The number of replicas per ReplicationController.",
+			GenerateFunc: wrapReplicationControllerFunc(func(r *v1.ReplicationController) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(r.Status.Replicas),
+				}}
+			}),
+		},
+		{
+			Name: "kube_replicationcontroller_status_fully_labeled_replicas",
+			Type: metrics.MetricTypeGauge,
+			Help: "The number of fully labeled replicas per ReplicationController.",
+			GenerateFunc: wrapReplicationControllerFunc(func(r *v1.ReplicationController) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(r.Status.FullyLabeledReplicas),
+				}}
+			}),
+		},
+		{
+			Name: "kube_replicationcontroller_status_ready_replicas",
+			Type: metrics.MetricTypeGauge,
+			Help: "The number of ready replicas per ReplicationController.",
+			GenerateFunc: wrapReplicationControllerFunc(func(r *v1.ReplicationController) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(r.Status.ReadyReplicas),
+				}}
+			}),
+		},
+		{
+			Name: "kube_replicationcontroller_status_available_replicas",
+			Type: metrics.MetricTypeGauge,
+			Help: "The number of available replicas per ReplicationController.",
+			GenerateFunc: wrapReplicationControllerFunc(func(r *v1.ReplicationController) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(r.Status.AvailableReplicas),
+				}}
+			}),
+		},
+		{
+			Name: "kube_replicationcontroller_status_observed_generation",
+			Type: metrics.MetricTypeGauge,
+			Help: "The generation observed by the ReplicationController controller.",
+			GenerateFunc: wrapReplicationControllerFunc(func(r *v1.ReplicationController) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(r.Status.ObservedGeneration),
+				}}
+			}),
+		},
+		{
+			Name: "kube_replicationcontroller_spec_replicas",
+			Type: metrics.MetricTypeGauge,
+			Help: "Number of desired pods for a ReplicationController.",
+			GenerateFunc: wrapReplicationControllerFunc(func(r *v1.ReplicationController) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				if r.Spec.Replicas != nil {
+					ms = append(ms, &metrics.Metric{
+						Value: float64(*r.Spec.Replicas),
+					})
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_replicationcontroller_metadata_generation",
+			Type: metrics.MetricTypeGauge,
+			Help: "Sequence number representing a specific generation of the desired state.",
+			GenerateFunc: wrapReplicationControllerFunc(func(r *v1.ReplicationController) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(r.ObjectMeta.Generation),
+				}}
+			}),
+		},
+	}
 )
 
 func createReplicationControllerListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
@@ -90,35 +136,18 @@ func createReplicationControllerListWatch(kubeClient clientset.Interface, ns str
 		},
 	}
 }
-func generateReplicationControllerMetrics(obj interface{}) []*metrics.Metric {
-	ms := []*metrics.Metric{}
 
-	// TODO: Refactor
-	rPointer := obj.(*v1.ReplicationController)
-	r := *rPointer
+func wrapReplicationControllerFunc(f func(*v1.ReplicationController) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		replicationController := obj.(*v1.ReplicationController)
 
-	addGauge := func(desc *metricFamilyDef, v float64, lv ...string) {
-		lv = append([]string{r.Namespace, r.Name}, lv...)
+		ms := f(replicationController)
 
-		m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, lv, v)
-		if err != nil {
-			panic(err)
+		for _, m := range ms {
+			m.LabelKeys = append(descReplicationControllerLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{replicationController.Namespace, replicationController.Name}, m.LabelValues...)
 		}
 
-		ms = append(ms, m)
-	}
-	if !r.CreationTimestamp.IsZero() {
-		addGauge(descReplicationControllerCreated, float64(r.CreationTimestamp.Unix()))
+		return ms
 	}
-	addGauge(descReplicationControllerStatusReplicas, float64(r.Status.Replicas))
-	addGauge(descReplicationControllerStatusFullyLabeledReplicas, float64(r.Status.FullyLabeledReplicas))
-	addGauge(descReplicationControllerStatusReadyReplicas, float64(r.Status.ReadyReplicas))
-	addGauge(descReplicationControllerStatusAvailableReplicas, float64(r.Status.AvailableReplicas))
-	addGauge(descReplicationControllerStatusObservedGeneration, float64(r.Status.ObservedGeneration))
-	if r.Spec.Replicas != nil {
-		addGauge(descReplicationControllerSpecReplicas, float64(*r.Spec.Replicas))
-	}
-	addGauge(descReplicationControllerMetadataGeneration, float64(r.ObjectMeta.Generation))
-
-	return ms
 }
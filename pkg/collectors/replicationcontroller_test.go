@@ -111,7 +111,7 @@ func TestReplicationControllerCollector(t *testing.T) {
 		},
 	}
 	for i, c := range cases {
-		c.Func = generateReplicationControllerMetrics
+		c.Func = composeMetricGenFuncs(replicationControllerMetricFamilies)
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
@@ -30,20 +30,49 @@ import (
 var (
 	descResourceQuotaLabelsDefaultLabels = []string{"resourcequota", "namespace"}
 
-	descResourceQuotaCreated = newMetricFamilyDef(
-		"kube_resourcequota_created",
-		"Unix creation timestamp",
-		descResourceQuotaLabelsDefaultLabels,
-		nil,
-	)
-	descResourceQuota = newMetricFamilyDef(
-		"kube_resourcequota",
-		"Information about resource quota.",
-		append(descResourceQuotaLabelsDefaultLabels,
-			"resource",
-			"type",
-		), nil,
-	)
+	resourceQuotaMetricFamilies = []metricFamilyDef{
+		{
+			Name: "kube_resourcequota_created",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapResourceQuotaFunc(func(r *v1.ResourceQuota) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				if !r.CreationTimestamp.IsZero() {
+					ms = append(ms, &metrics.Metric{
+						Value: float64(r.CreationTimestamp.Unix()),
+					})
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_resourcequota",
+			Type: metrics.MetricTypeGauge,
+			Help: "Information about resource quota.",
+			GenerateFunc: wrapResourceQuotaFunc(func(r *v1.ResourceQuota) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				for res, qty := range r.Status.Hard {
+					ms = append(ms, &metrics.Metric{
+						LabelKeys:   []string{"resource", "type"},
+						LabelValues: []string{string(res), "hard"},
+						Value:       float64(qty.MilliValue()) / 1000,
+					})
+				}
+				for res, qty := range r.Status.Used {
+					ms = append(ms, &metrics.Metric{
+						LabelKeys:   []string{"resource", "type"},
+						LabelValues: []string{string(res), "used"},
+						Value:       float64(qty.MilliValue()) / 1000,
+					})
+				}
+
+				return ms
+			}),
+		},
+	}
 )
 
 func createResourceQuotaListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
@@ -57,32 +86,17 @@ func createResourceQuotaListWatch(kubeClient clientset.Interface, ns string) cac
 	}
 }
 
-func generateResourceQuotaMetrics(obj interface{}) []*metrics.Metric {
-	ms := []*metrics.Metric{}
+func wrapResourceQuotaFunc(f func(*v1.ResourceQuota) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		resourceQuota := obj.(*v1.ResourceQuota)
 
-	// TODO: Refactor
-	rPointer := obj.(*v1.ResourceQuota)
-	r := *rPointer
+		ms := f(resourceQuota)
 
-	addGauge := func(desc *metricFamilyDef, v float64, lv ...string) {
-		lv = append([]string{r.Name, r.Namespace}, lv...)
-		m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, lv, v)
-		if err != nil {
-			panic(err)
+		for _, m := range ms {
+			m.LabelKeys = append(descResourceQuotaLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{resourceQuota.Name, resourceQuota.Namespace}, m.LabelValues...)
 		}
 
-		ms = append(ms, m)
-	}
-
-	if !r.CreationTimestamp.IsZero() {
-		addGauge(descResourceQuotaCreated, float64(r.CreationTimestamp.Unix()))
+		return ms
 	}
-	for res, qty := range r.Status.Hard {
-		addGauge(descResourceQuota, float64(qty.MilliValue())/1000, string(res), "hard")
-	}
-	for res, qty := range r.Status.Used {
-		addGauge(descResourceQuota, float64(qty.MilliValue())/1000, string(res), "used")
-	}
-
-	return ms
 }
@@ -132,7 +132,7 @@ func TestResourceQuotaCollector(t *testing.T) {
 		},
 	}
 	for i, c := range cases {
-		c.Func = generateResourceQuotaMetrics
+		c.Func = composeMetricGenFuncs(resourceQuotaMetricFamilies)
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
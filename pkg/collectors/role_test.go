@@ -0,0 +1,75 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+	"time"
+
+	rbac "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRoleCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_role_labels Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_role_labels gauge
+		# HELP kube_role_annotations Kubernetes annotations converted to Prometheus labels.
+		# TYPE kube_role_annotations gauge
+		# HELP kube_role_created Unix creation timestamp
+		# TYPE kube_role_created gauge
+		# HELP kube_role_rules Number of policy rules of the role.
+		# TYPE kube_role_rules gauge
+	`
+
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &rbac.Role{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "pod-reader",
+					Namespace:         "ns1",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+					Labels: map[string]string{
+						"app": "foobar",
+					},
+				},
+				Rules: []rbac.PolicyRule{
+					{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list"}},
+					{APIGroups: []string{""}, Resources: []string{"pods/log"}, Verbs: []string{"get"}},
+				},
+			},
+			Want: `
+				kube_role_created{namespace="ns1",role="pod-reader"} 1.5e+09
+				kube_role_labels{label_app="foobar",namespace="ns1",role="pod-reader"} 1
+				kube_role_rules{namespace="ns1",role="pod-reader"} 2
+			`,
+			MetricNames: []string{
+				"kube_role_created",
+				"kube_role_labels",
+				"kube_role_rules",
+			},
+		},
+	}
+	for i, c := range cases {
+		c.Func = composeMetricGenFuncs(roleMetricFamilies(allKeys, allKeys))
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
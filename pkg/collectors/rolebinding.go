@@ -0,0 +1,139 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"k8s.io/kube-state-metrics/pkg/metrics"
+
+	rbac "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	descRoleBindingLabelsName          = "kube_rolebinding_labels"
+	descRoleBindingLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descRoleBindingLabelsDefaultLabels = []string{"namespace", "rolebinding"}
+
+	descRoleBindingAnnotationsName = "kube_rolebinding_annotations"
+	descRoleBindingAnnotationsHelp = "Kubernetes annotations converted to Prometheus labels."
+)
+
+func roleBindingMetricFamilies(allowedLabels, allowedAnnotations keyAllowList) []metricFamilyDef {
+	return []metricFamilyDef{
+		{
+			Name: descRoleBindingLabelsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descRoleBindingLabelsHelp,
+			GenerateFunc: wrapRoleBindingFunc(func(r *rbac.RoleBinding) []*metrics.Metric {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(r.Labels, allowedLabels)
+				return []*metrics.Metric{{
+					LabelKeys:   labelKeys,
+					LabelValues: labelValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: descRoleBindingAnnotationsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descRoleBindingAnnotationsHelp,
+			GenerateFunc: wrapRoleBindingFunc(func(r *rbac.RoleBinding) []*metrics.Metric {
+				annotationKeys, annotationValues := kubeAnnotationsToPrometheusAnnotations(r.Annotations, allowedAnnotations)
+				return []*metrics.Metric{{
+					LabelKeys:   annotationKeys,
+					LabelValues: annotationValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_rolebinding_created",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapRoleBindingFunc(func(r *rbac.RoleBinding) []*metrics.Metric {
+				return createdMetric(r.CreationTimestamp)
+			}),
+		},
+		{
+			Name: "kube_rolebinding_info",
+			Type: metrics.MetricTypeGauge,
+			Help: "Information about rolebinding.",
+			GenerateFunc: wrapRoleBindingFunc(func(r *rbac.RoleBinding) []*metrics.Metric {
+				return []*metrics.Metric{{
+					LabelKeys:   []string{"roleref_kind", "roleref_name"},
+					LabelValues: []string{r.RoleRef.Kind, r.RoleRef.Name},
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_rolebinding_subject",
+			Type: metrics.MetricTypeGauge,
+			Help: "Subjects bound to the referenced role by the rolebinding.",
+			GenerateFunc: wrapRoleBindingFunc(func(r *rbac.RoleBinding) []*metrics.Metric {
+				return subjectMetrics(r.Subjects, r.RoleRef)
+			}),
+		},
+	}
+}
+
+func createRoleBindingListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
+	return cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return kubeClient.RbacV1().RoleBindings(ns).List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return kubeClient.RbacV1().RoleBindings(ns).Watch(opts)
+		},
+	}
+}
+
+func wrapRoleBindingFunc(f func(*rbac.RoleBinding) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		roleBinding := obj.(*rbac.RoleBinding)
+
+		ms := f(roleBinding)
+
+		for _, m := range ms {
+			m.LabelKeys = append(descRoleBindingLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{roleBinding.Namespace, roleBinding.Name}, m.LabelValues...)
+		}
+
+		return ms
+	}
+}
+
+// subjectMetrics returns one metric per subject bound to the referenced role,
+// shared by role bindings and cluster role bindings. The namespace of a subject
+// is only set for service accounts.
+func subjectMetrics(subjects []rbac.Subject, roleRef rbac.RoleRef) []*metrics.Metric {
+	ms := make([]*metrics.Metric, len(subjects))
+
+	for i, s := range subjects {
+		ms[i] = &metrics.Metric{
+			LabelKeys:   []string{"subject_kind", "subject_name", "subject_namespace", "roleref_kind", "roleref_name"},
+			LabelValues: []string{s.Kind, s.Name, s.Namespace, roleRef.Kind, roleRef.Name},
+			Value:       1,
+		}
+	}
+
+	return ms
+}
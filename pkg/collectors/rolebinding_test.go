@@ -0,0 +1,92 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+	"time"
+
+	rbac "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRoleBindingCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_rolebinding_labels Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_rolebinding_labels gauge
+		# HELP kube_rolebinding_annotations Kubernetes annotations converted to Prometheus labels.
+		# TYPE kube_rolebinding_annotations gauge
+		# HELP kube_rolebinding_created Unix creation timestamp
+		# TYPE kube_rolebinding_created gauge
+		# HELP kube_rolebinding_info Information about rolebinding.
+		# TYPE kube_rolebinding_info gauge
+		# HELP kube_rolebinding_subject Subjects bound to the referenced role by the rolebinding.
+		# TYPE kube_rolebinding_subject gauge
+	`
+
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &rbac.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "read-pods",
+					Namespace:         "ns1",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+				},
+				Subjects: []rbac.Subject{
+					{Kind: rbac.UserKind, APIGroup: rbac.GroupName, Name: "jane"},
+					{Kind: rbac.ServiceAccountKind, Name: "reader", Namespace: "ns2"},
+				},
+				RoleRef: rbac.RoleRef{APIGroup: rbac.GroupName, Kind: "Role", Name: "pod-reader"},
+			},
+			Want: `
+				kube_rolebinding_created{namespace="ns1",rolebinding="read-pods"} 1.5e+09
+				kube_rolebinding_info{namespace="ns1",rolebinding="read-pods",roleref_kind="Role",roleref_name="pod-reader"} 1
+				kube_rolebinding_subject{namespace="ns1",rolebinding="read-pods",roleref_kind="Role",roleref_name="pod-reader",subject_kind="ServiceAccount",subject_name="reader",subject_namespace="ns2"} 1
+				kube_rolebinding_subject{namespace="ns1",rolebinding="read-pods",roleref_kind="Role",roleref_name="pod-reader",subject_kind="User",subject_name="jane",subject_namespace=""} 1
+			`,
+			MetricNames: []string{
+				"kube_rolebinding_created",
+				"kube_rolebinding_info",
+				"kube_rolebinding_subject",
+			},
+		},
+		{
+			Obj: &rbac.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "no-subjects",
+					Namespace: "ns1",
+				},
+				RoleRef: rbac.RoleRef{APIGroup: rbac.GroupName, Kind: "ClusterRole", Name: "view"},
+			},
+			Want: `
+				kube_rolebinding_info{namespace="ns1",rolebinding="no-subjects",roleref_kind="ClusterRole",roleref_name="view"} 1
+			`,
+			MetricNames: []string{
+				"kube_rolebinding_info",
+				"kube_rolebinding_subject",
+			},
+		},
+	}
+	for i, c := range cases {
+		c.Func = composeMetricGenFuncs(roleBindingMetricFamilies(allKeys, allKeys))
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
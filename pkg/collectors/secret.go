@@ -32,42 +32,91 @@ var (
 	descSecretLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
 	descSecretLabelsDefaultLabels = []string{"namespace", "secret"}
 
-	descSecretInfo = newMetricFamilyDef(
-		"kube_secret_info",
-		"Information about secret.",
-		descSecretLabelsDefaultLabels,
-		nil,
-	)
-
-	descSecretType = newMetricFamilyDef(
-		"kube_secret_type",
-		"Type about secret.",
-		append(descSecretLabelsDefaultLabels, "type"),
-		nil,
-	)
-
-	descSecretLabels = newMetricFamilyDef(
-		descSecretLabelsName,
-		descSecretLabelsHelp,
-		descSecretLabelsDefaultLabels,
-		nil,
-	)
-
-	descSecretCreated = newMetricFamilyDef(
-		"kube_secret_created",
-		"Unix creation timestamp",
-		descSecretLabelsDefaultLabels,
-		nil,
-	)
-
-	descSecretMetadataResourceVersion = newMetricFamilyDef(
-		"kube_secret_metadata_resource_version",
-		"Resource version representing a specific version of secret.",
-		append(descSecretLabelsDefaultLabels, "resource_version"),
-		nil,
-	)
+	descSecretAnnotationsName = "kube_secret_annotations"
+	descSecretAnnotationsHelp = "Kubernetes annotations converted to Prometheus labels."
 )
 
+func secretMetricFamilies(allowedLabels, allowedAnnotations keyAllowList) []metricFamilyDef {
+	return []metricFamilyDef{
+		{
+			Name: "kube_secret_info",
+			Type: metrics.MetricTypeGauge,
+			Help: "Information about secret.",
+			GenerateFunc: wrapSecretFunc(func(s *v1.Secret) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: 1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_secret_type",
+			Type: metrics.MetricTypeGauge,
+			Help: "Type about secret.",
+			GenerateFunc: wrapSecretFunc(func(s *v1.Secret) []*metrics.Metric {
+				return []*metrics.Metric{{
+					LabelKeys:   []string{"type"},
+					LabelValues: []string{string(s.Type)},
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: descSecretLabelsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descSecretLabelsHelp,
+			GenerateFunc: wrapSecretFunc(func(s *v1.Secret) []*metrics.Metric {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(s.Labels, allowedLabels)
+				return []*metrics.Metric{{
+					LabelKeys:   labelKeys,
+					LabelValues: labelValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: descSecretAnnotationsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descSecretAnnotationsHelp,
+			GenerateFunc: wrapSecretFunc(func(s *v1.Secret) []*metrics.Metric {
+				annotationKeys, annotationValues := kubeAnnotationsToPrometheusAnnotations(s.Annotations, allowedAnnotations)
+				return []*metrics.Metric{{
+					LabelKeys:   annotationKeys,
+					LabelValues: annotationValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_secret_created",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapSecretFunc(func(s *v1.Secret) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				if !s.CreationTimestamp.IsZero() {
+					ms = append(ms, &metrics.Metric{
+						Value: float64(s.CreationTimestamp.Unix()),
+					})
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_secret_metadata_resource_version",
+			Type: metrics.MetricTypeGauge,
+			Help: "Resource version representing a specific version of secret.",
+			GenerateFunc: wrapSecretFunc(func(s *v1.Secret) []*metrics.Metric {
+				return []*metrics.Metric{{
+					LabelKeys:   []string{"resource_version"},
+					LabelValues: []string{string(s.ObjectMeta.ResourceVersion)},
+					Value:       1,
+				}}
+			}),
+		},
+	}
+}
+
 func createSecretListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
 	return cache.ListWatch{
 		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
@@ -78,44 +127,18 @@ func createSecretListWatch(kubeClient clientset.Interface, ns string) cache.List
 		},
 	}
 }
-func secretLabelsDesc(labelKeys []string) *metricFamilyDef {
-	return newMetricFamilyDef(
-		descSecretLabelsName,
-		descSecretLabelsHelp,
-		append(descSecretLabelsDefaultLabels, labelKeys...),
-		nil,
-	)
-}
 
-func generateSecretMetrics(obj interface{}) []*metrics.Metric {
-	ms := []*metrics.Metric{}
+func wrapSecretFunc(f func(*v1.Secret) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		secret := obj.(*v1.Secret)
 
-	// TODO: Refactor
-	sPointer := obj.(*v1.Secret)
-	s := *sPointer
+		ms := f(secret)
 
-	addConstMetric := func(desc *metricFamilyDef, v float64, lv ...string) {
-		lv = append([]string{s.Namespace, s.Name}, lv...)
-		m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, lv, v)
-		if err != nil {
-			panic(err)
+		for _, m := range ms {
+			m.LabelKeys = append(descSecretLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{secret.Namespace, secret.Name}, m.LabelValues...)
 		}
 
-		ms = append(ms, m)
+		return ms
 	}
-	addGauge := func(desc *metricFamilyDef, v float64, lv ...string) {
-		addConstMetric(desc, v, lv...)
-	}
-	addGauge(descSecretInfo, 1)
-
-	addGauge(descSecretType, 1, string(s.Type))
-	if !s.CreationTimestamp.IsZero() {
-		addGauge(descSecretCreated, float64(s.CreationTimestamp.Unix()))
-	}
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(s.Labels)
-	addGauge(secretLabelsDesc(labelKeys), 1, labelValues...)
-
-	addGauge(descSecretMetadataResourceVersion, 1, string(s.ObjectMeta.ResourceVersion))
-
-	return ms
 }
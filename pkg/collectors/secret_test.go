@@ -101,7 +101,7 @@ func TestSecretCollector(t *testing.T) {
 		},
 	}
 	for i, c := range cases {
-		c.Func = generateSecretMetrics
+		c.Func = composeMetricGenFuncs(secretMetricFamilies(allKeys, allKeys))
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
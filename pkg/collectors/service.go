@@ -32,35 +32,81 @@ var (
 	descServiceLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
 	descServiceLabelsDefaultLabels = []string{"namespace", "service"}
 
-	descServiceInfo = newMetricFamilyDef(
-		"kube_service_info",
-		"Information about service.",
-		append(descServiceLabelsDefaultLabels, "cluster_ip"),
-		nil,
-	)
-
-	descServiceCreated = newMetricFamilyDef(
-		"kube_service_created",
-		"Unix creation timestamp",
-		descServiceLabelsDefaultLabels,
-		nil,
-	)
-
-	descServiceSpecType = newMetricFamilyDef(
-		"kube_service_spec_type",
-		"Type about service.",
-		append(descServiceLabelsDefaultLabels, "type"),
-		nil,
-	)
-
-	descServiceLabels = newMetricFamilyDef(
-		descServiceLabelsName,
-		descServiceLabelsHelp,
-		descServiceLabelsDefaultLabels,
-		nil,
-	)
+	descServiceAnnotationsName = "kube_service_annotations"
+	descServiceAnnotationsHelp = "Kubernetes annotations converted to Prometheus labels."
 )
 
+func serviceMetricFamilies(allowedLabels, allowedAnnotations keyAllowList) []metricFamilyDef {
+	return []metricFamilyDef{
+		{
+			Name: "kube_service_info",
+			Type: metrics.MetricTypeGauge,
+			Help: "Information about service.",
+			GenerateFunc: wrapServiceFunc(func(s *v1.Service) []*metrics.Metric {
+				return []*metrics.Metric{{
+					LabelKeys:   []string{"cluster_ip"},
+					LabelValues: []string{s.Spec.ClusterIP},
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_service_created",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapServiceFunc(func(s *v1.Service) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				if !s.CreationTimestamp.IsZero() {
+					ms = append(ms, &metrics.Metric{
+						Value: float64(s.CreationTimestamp.Unix()),
+					})
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_service_spec_type",
+			Type: metrics.MetricTypeGauge,
+			Help: "Type about service.",
+			GenerateFunc: wrapServiceFunc(func(s *v1.Service) []*metrics.Metric {
+				return []*metrics.Metric{{
+					LabelKeys:   []string{"type"},
+					LabelValues: []string{string(s.Spec.Type)},
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: descServiceLabelsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descServiceLabelsHelp,
+			GenerateFunc: wrapServiceFunc(func(s *v1.Service) []*metrics.Metric {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(s.Labels, allowedLabels)
+				return []*metrics.Metric{{
+					LabelKeys:   labelKeys,
+					LabelValues: labelValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: descServiceAnnotationsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descServiceAnnotationsHelp,
+			GenerateFunc: wrapServiceFunc(func(s *v1.Service) []*metrics.Metric {
+				annotationKeys, annotationValues := kubeAnnotationsToPrometheusAnnotations(s.Annotations, allowedAnnotations)
+				return []*metrics.Metric{{
+					LabelKeys:   annotationKeys,
+					LabelValues: annotationValues,
+					Value:       1,
+				}}
+			}),
+		},
+	}
+}
+
 func createServiceListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
 	return cache.ListWatch{
 		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
@@ -72,43 +118,17 @@ func createServiceListWatch(kubeClient clientset.Interface, ns string) cache.Lis
 	}
 }
 
-func serviceLabelsDesc(labelKeys []string) *metricFamilyDef {
-	return newMetricFamilyDef(
-		descServiceLabelsName,
-		descServiceLabelsHelp,
-		append(descServiceLabelsDefaultLabels, labelKeys...),
-		nil,
-	)
-}
-
-func generateServiceMetrics(obj interface{}) []*metrics.Metric {
-	ms := []*metrics.Metric{}
+func wrapServiceFunc(f func(*v1.Service) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		service := obj.(*v1.Service)
 
-	// TODO: Refactor
-	sPointer := obj.(*v1.Service)
-	s := *sPointer
+		ms := f(service)
 
-	addConstMetric := func(desc *metricFamilyDef, v float64, lv ...string) {
-		lv = append([]string{s.Namespace, s.Name}, lv...)
-
-		m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, lv, v)
-		if err != nil {
-			panic(err)
+		for _, m := range ms {
+			m.LabelKeys = append(descServiceLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{service.Namespace, service.Name}, m.LabelValues...)
 		}
 
-		ms = append(ms, m)
-	}
-	addGauge := func(desc *metricFamilyDef, v float64, lv ...string) {
-		addConstMetric(desc, v, lv...)
+		return ms
 	}
-	addGauge(descServiceSpecType, 1, string(s.Spec.Type))
-
-	addGauge(descServiceInfo, 1, s.Spec.ClusterIP)
-	if !s.CreationTimestamp.IsZero() {
-		addGauge(descServiceCreated, float64(s.CreationTimestamp.Unix()))
-	}
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(s.Labels)
-	addGauge(serviceLabelsDesc(labelKeys), 1, labelValues...)
-
-	return ms
 }
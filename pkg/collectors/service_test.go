@@ -34,6 +34,8 @@ func TestServiceCollector(t *testing.T) {
 		# TYPE kube_service_created gauge
 		# HELP kube_service_labels Kubernetes labels converted to Prometheus labels.
 		# TYPE kube_service_labels gauge
+		# HELP kube_service_annotations Kubernetes annotations converted to Prometheus labels.
+		# TYPE kube_service_annotations gauge
 		# HELP kube_service_spec_type Type about service.
 		# TYPE kube_service_spec_type gauge
 	`
@@ -76,6 +78,9 @@ func TestServiceCollector(t *testing.T) {
 					Labels: map[string]string{
 						"app": "example2",
 					},
+					Annotations: map[string]string{
+						"owner": "team-a",
+					},
 				},
 				Spec: v1.ServiceSpec{
 					ClusterIP: "1.2.3.5",
@@ -83,6 +88,7 @@ func TestServiceCollector(t *testing.T) {
 				},
 			},
 			Want: `
+				kube_service_annotations{annotation_owner="team-a",namespace="default",service="test-service2"} 1
 				kube_service_created{namespace="default",service="test-service2"} 1.5e+09
 				kube_service_info{cluster_ip="1.2.3.5",namespace="default",service="test-service2"} 1
 				kube_service_labels{label_app="example2",namespace="default",service="test-service2"} 1
@@ -105,6 +111,7 @@ func TestServiceCollector(t *testing.T) {
 				},
 			},
 			Want: `
+				kube_service_annotations{namespace="default",service="test-service3"} 1
 				kube_service_created{namespace="default",service="test-service3"} 1.5e+09
 				kube_service_info{cluster_ip="1.2.3.6",namespace="default",service="test-service3"} 1		
 				kube_service_labels{label_app="example3",namespace="default",service="test-service3"} 1
@@ -126,6 +133,7 @@ func TestServiceCollector(t *testing.T) {
 				},
 			},
 			Want: `	
+				kube_service_annotations{namespace="default",service="test-service4"} 1
 				kube_service_created{namespace="default",service="test-service4"} 1.5e+09		
 				kube_service_info{cluster_ip="",namespace="default",service="test-service4"} 1
 				kube_service_labels{label_app="example4",namespace="default",service="test-service4"} 1
@@ -134,7 +142,7 @@ func TestServiceCollector(t *testing.T) {
 		},
 	}
 	for i, c := range cases {
-		c.Func = generateServiceMetrics
+		c.Func = composeMetricGenFuncs(serviceMetricFamilies(allKeys, allKeys))
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
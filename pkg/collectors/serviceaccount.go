@@ -0,0 +1,161 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"k8s.io/kube-state-metrics/pkg/metrics"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	descServiceAccountLabelsName          = "kube_serviceaccount_labels"
+	descServiceAccountLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descServiceAccountLabelsDefaultLabels = []string{"namespace", "serviceaccount"}
+
+	descServiceAccountAnnotationsName = "kube_serviceaccount_annotations"
+	descServiceAccountAnnotationsHelp = "Kubernetes annotations converted to Prometheus labels."
+)
+
+func serviceAccountMetricFamilies(allowedLabels, allowedAnnotations keyAllowList) []metricFamilyDef {
+	return []metricFamilyDef{
+		{
+			Name: "kube_serviceaccount_info",
+			Type: metrics.MetricTypeGauge,
+			Help: "Information about serviceaccount.",
+			GenerateFunc: wrapServiceAccountFunc(func(s *v1.ServiceAccount) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: 1,
+				}}
+			}),
+		},
+		{
+			Name: descServiceAccountLabelsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descServiceAccountLabelsHelp,
+			GenerateFunc: wrapServiceAccountFunc(func(s *v1.ServiceAccount) []*metrics.Metric {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(s.Labels, allowedLabels)
+				return []*metrics.Metric{{
+					LabelKeys:   labelKeys,
+					LabelValues: labelValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: descServiceAccountAnnotationsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descServiceAccountAnnotationsHelp,
+			GenerateFunc: wrapServiceAccountFunc(func(s *v1.ServiceAccount) []*metrics.Metric {
+				annotationKeys, annotationValues := kubeAnnotationsToPrometheusAnnotations(s.Annotations, allowedAnnotations)
+				return []*metrics.Metric{{
+					LabelKeys:   annotationKeys,
+					LabelValues: annotationValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_serviceaccount_created",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapServiceAccountFunc(func(s *v1.ServiceAccount) []*metrics.Metric {
+				return createdMetric(s.CreationTimestamp)
+			}),
+		},
+		{
+			Name: "kube_serviceaccount_automount_token",
+			Type: metrics.MetricTypeGauge,
+			Help: "Whether the serviceaccount token is mounted into pods by default.",
+			GenerateFunc: wrapServiceAccountFunc(func(s *v1.ServiceAccount) []*metrics.Metric {
+				// Tokens are mounted unless explicitly disabled.
+				automount := s.AutomountServiceAccountToken == nil || *s.AutomountServiceAccountToken
+
+				return []*metrics.Metric{{
+					Value: boolFloat64(automount),
+				}}
+			}),
+		},
+		{
+			Name: "kube_serviceaccount_secret",
+			Type: metrics.MetricTypeGauge,
+			Help: "Secret referenced by the serviceaccount.",
+			GenerateFunc: wrapServiceAccountFunc(func(s *v1.ServiceAccount) []*metrics.Metric {
+				ms := make([]*metrics.Metric, len(s.Secrets))
+
+				for i, secret := range s.Secrets {
+					ms[i] = &metrics.Metric{
+						LabelKeys:   []string{"secret"},
+						LabelValues: []string{secret.Name},
+						Value:       1,
+					}
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_serviceaccount_image_pull_secret",
+			Type: metrics.MetricTypeGauge,
+			Help: "Image pull secret referenced by the serviceaccount.",
+			GenerateFunc: wrapServiceAccountFunc(func(s *v1.ServiceAccount) []*metrics.Metric {
+				ms := make([]*metrics.Metric, len(s.ImagePullSecrets))
+
+				for i, secret := range s.ImagePullSecrets {
+					ms[i] = &metrics.Metric{
+						LabelKeys:   []string{"secret"},
+						LabelValues: []string{secret.Name},
+						Value:       1,
+					}
+				}
+
+				return ms
+			}),
+		},
+	}
+}
+
+func createServiceAccountListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
+	return cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return kubeClient.CoreV1().ServiceAccounts(ns).List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return kubeClient.CoreV1().ServiceAccounts(ns).Watch(opts)
+		},
+	}
+}
+
+func wrapServiceAccountFunc(f func(*v1.ServiceAccount) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		serviceAccount := obj.(*v1.ServiceAccount)
+
+		ms := f(serviceAccount)
+
+		for _, m := range ms {
+			m.LabelKeys = append(descServiceAccountLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{serviceAccount.Namespace, serviceAccount.Name}, m.LabelValues...)
+		}
+
+		return ms
+	}
+}
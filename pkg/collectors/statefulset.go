@@ -32,74 +32,163 @@ var (
 	descStatefulSetLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
 	descStatefulSetLabelsDefaultLabels = []string{"namespace", "statefulset"}
 
-	descStatefulSetCreated = newMetricFamilyDef(
-		"kube_statefulset_created",
-		"Unix creation timestamp",
-		descStatefulSetLabelsDefaultLabels,
-		nil,
-	)
-	descStatefulSetStatusReplicas = newMetricFamilyDef(
-		"kube_statefulset_status_replicas",
-		"The number of replicas per StatefulSet.",
-		descStatefulSetLabelsDefaultLabels,
-		nil,
-	)
-	descStatefulSetStatusReplicasCurrent = newMetricFamilyDef(
-		"kube_statefulset_status_replicas_current",
-		"The number of current replicas per StatefulSet.",
-		descStatefulSetLabelsDefaultLabels,
-		nil,
-	)
-	descStatefulSetStatusReplicasReady = newMetricFamilyDef(
-		"kube_statefulset_status_replicas_ready",
-		"The number of ready replicas per StatefulSet.",
-		descStatefulSetLabelsDefaultLabels,
-		nil,
-	)
-	descStatefulSetStatusReplicasUpdated = newMetricFamilyDef(
-		"kube_statefulset_status_replicas_updated",
-		"The number of updated replicas per StatefulSet.",
-		descStatefulSetLabelsDefaultLabels,
-		nil,
-	)
-	descStatefulSetStatusObservedGeneration = newMetricFamilyDef(
-		"kube_statefulset_status_observed_generation",
-		"The generation observed by the StatefulSet controller.",
-		descStatefulSetLabelsDefaultLabels,
-		nil,
-	)
-	descStatefulSetSpecReplicas = newMetricFamilyDef(
-		"kube_statefulset_replicas",
-		"Number of desired pods for a StatefulSet.",
-		descStatefulSetLabelsDefaultLabels,
-		nil,
-	)
-	descStatefulSetMetadataGeneration = newMetricFamilyDef(
-		"kube_statefulset_metadata_generation",
-		"Sequence number representing a specific generation of the desired state for the StatefulSet.",
-		descStatefulSetLabelsDefaultLabels,
-		nil,
-	)
-	descStatefulSetLabels = newMetricFamilyDef(
-		descStatefulSetLabelsName,
-		descStatefulSetLabelsHelp,
-		descStatefulSetLabelsDefaultLabels,
-		nil,
-	)
-	descStatefulSetCurrentRevision = newMetricFamilyDef(
-		"kube_statefulset_status_current_revision",
-		"Indicates the version of the StatefulSet used to generate Pods in the sequence [0,currentReplicas).",
-		append(descStatefulSetLabelsDefaultLabels, "revision"),
-		nil,
-	)
-	descStatefulSetUpdateRevision = newMetricFamilyDef(
-		"kube_statefulset_status_update_revision",
-		"Indicates the version of the StatefulSet used to generate Pods in the sequence [replicas-updatedReplicas,replicas)",
-		append(descStatefulSetLabelsDefaultLabels, "revision"),
-		nil,
-	)
+	descStatefulSetAnnotationsName = "kube_statefulset_annotations"
+	descStatefulSetAnnotationsHelp = "Kubernetes annotations converted to Prometheus labels."
 )
 
+func statefulSetMetricFamilies(allowedLabels, allowedAnnotations keyAllowList) []metricFamilyDef {
+	return []metricFamilyDef{
+		{
+			Name: "kube_statefulset_created",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapStatefulSetFunc(func(s *v1beta1.StatefulSet) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				if !s.CreationTimestamp.IsZero() {
+					ms = append(ms, &metrics.Metric{
+						Value: float64(s.CreationTimestamp.Unix()),
+					})
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_statefulset_status_replicas",
+			Type: metrics.MetricTypeGauge,
+			Help: "The number of replicas per StatefulSet.",
+			GenerateFunc: wrapStatefulSetFunc(func(s *v1beta1.StatefulSet) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(s.Status.Replicas),
+				}}
+			}),
+		},
+		{
+			Name: "kube_statefulset_status_replicas_current",
+			Type: metrics.MetricTypeGauge,
+			Help: "The number of current replicas per StatefulSet.",
+			GenerateFunc: wrapStatefulSetFunc(func(s *v1beta1.StatefulSet) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(s.Status.CurrentReplicas),
+				}}
+			}),
+		},
+		{
+			Name: "kube_statefulset_status_replicas_ready",
+			Type: metrics.MetricTypeGauge,
+			Help: "The number of ready replicas per StatefulSet.",
+			GenerateFunc: wrapStatefulSetFunc(func(s *v1beta1.StatefulSet) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(s.Status.ReadyReplicas),
+				}}
+			}),
+		},
+		{
+			Name: "kube_statefulset_status_replicas_updated",
+			Type: metrics.MetricTypeGauge,
+			Help: "The number of updated replicas per StatefulSet.",
+			GenerateFunc: wrapStatefulSetFunc(func(s *v1beta1.StatefulSet) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(s.Status.UpdatedReplicas),
+				}}
+			}),
+		},
+		{
+			Name: "kube_statefulset_status_observed_generation",
+			Type: metrics.MetricTypeGauge,
+			Help: "The generation observed by the StatefulSet controller.",
+			GenerateFunc: wrapStatefulSetFunc(func(s *v1beta1.StatefulSet) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				if s.Status.ObservedGeneration != nil {
+					ms = append(ms, &metrics.Metric{
+						Value: float64(*s.Status.ObservedGeneration),
+					})
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_statefulset_replicas",
+			Type: metrics.MetricTypeGauge,
+			Help: "Number of desired pods for a StatefulSet.",
+			GenerateFunc: wrapStatefulSetFunc(func(s *v1beta1.StatefulSet) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				if s.Spec.Replicas != nil {
+					ms = append(ms, &metrics.Metric{
+						Value: float64(*s.Spec.Replicas),
+					})
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_statefulset_metadata_generation",
+			Type: metrics.MetricTypeGauge,
+			Help: "Sequence number representing a specific generation of the desired state for the StatefulSet.",
+			GenerateFunc: wrapStatefulSetFunc(func(s *v1beta1.StatefulSet) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: float64(s.ObjectMeta.Generation),
+				}}
+			}),
+		},
+		{
+			Name: descStatefulSetLabelsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descStatefulSetLabelsHelp,
+			GenerateFunc: wrapStatefulSetFunc(func(s *v1beta1.StatefulSet) []*metrics.Metric {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(s.Labels, allowedLabels)
+				return []*metrics.Metric{{
+					LabelKeys:   labelKeys,
+					LabelValues: labelValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: descStatefulSetAnnotationsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descStatefulSetAnnotationsHelp,
+			GenerateFunc: wrapStatefulSetFunc(func(s *v1beta1.StatefulSet) []*metrics.Metric {
+				annotationKeys, annotationValues := kubeAnnotationsToPrometheusAnnotations(s.Annotations, allowedAnnotations)
+				return []*metrics.Metric{{
+					LabelKeys:   annotationKeys,
+					LabelValues: annotationValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_statefulset_status_current_revision",
+			Type: metrics.MetricTypeGauge,
+			Help: "Indicates the version of the StatefulSet used to generate Pods in the sequence [0,currentReplicas).",
+			GenerateFunc: wrapStatefulSetFunc(func(s *v1beta1.StatefulSet) []*metrics.Metric {
+				return []*metrics.Metric{{
+					LabelKeys:   []string{"revision"},
+					LabelValues: []string{s.Status.CurrentRevision},
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_statefulset_status_update_revision",
+			Type: metrics.MetricTypeGauge,
+			Help: "Indicates the version of the StatefulSet used to generate Pods in the sequence [replicas-updatedReplicas,replicas)",
+			GenerateFunc: wrapStatefulSetFunc(func(s *v1beta1.StatefulSet) []*metrics.Metric {
+				return []*metrics.Metric{{
+					LabelKeys:   []string{"revision"},
+					LabelValues: []string{s.Status.UpdateRevision},
+					Value:       1,
+				}}
+			}),
+		},
+	}
+}
+
 func createStatefulSetListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
 	return cache.ListWatch{
 		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
@@ -111,51 +200,17 @@ func createStatefulSetListWatch(kubeClient clientset.Interface, ns string) cache
 	}
 }
 
-func statefulSetLabelsDesc(labelKeys []string) *metricFamilyDef {
-	return newMetricFamilyDef(
-		descStatefulSetLabelsName,
-		descStatefulSetLabelsHelp,
-		append(descStatefulSetLabelsDefaultLabels, labelKeys...),
-		nil,
-	)
-}
-
-func generateStatefulSetMetrics(obj interface{}) []*metrics.Metric {
-	ms := []*metrics.Metric{}
+func wrapStatefulSetFunc(f func(*v1beta1.StatefulSet) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		statefulSet := obj.(*v1beta1.StatefulSet)
 
-	// TODO: Refactor
-	sPointer := obj.(*v1beta1.StatefulSet)
-	s := *sPointer
+		ms := f(statefulSet)
 
-	addGauge := func(desc *metricFamilyDef, v float64, lv ...string) {
-		lv = append([]string{s.Namespace, s.Name}, lv...)
-		m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, lv, v)
-		if err != nil {
-			panic(err)
+		for _, m := range ms {
+			m.LabelKeys = append(descStatefulSetLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{statefulSet.Namespace, statefulSet.Name}, m.LabelValues...)
 		}
 
-		ms = append(ms, m)
-	}
-	if !s.CreationTimestamp.IsZero() {
-		addGauge(descStatefulSetCreated, float64(s.CreationTimestamp.Unix()))
+		return ms
 	}
-	addGauge(descStatefulSetStatusReplicas, float64(s.Status.Replicas))
-	addGauge(descStatefulSetStatusReplicasCurrent, float64(s.Status.CurrentReplicas))
-	addGauge(descStatefulSetStatusReplicasReady, float64(s.Status.ReadyReplicas))
-	addGauge(descStatefulSetStatusReplicasUpdated, float64(s.Status.UpdatedReplicas))
-	if s.Status.ObservedGeneration != nil {
-		addGauge(descStatefulSetStatusObservedGeneration, float64(*s.Status.ObservedGeneration))
-	}
-
-	if s.Spec.Replicas != nil {
-		addGauge(descStatefulSetSpecReplicas, float64(*s.Spec.Replicas))
-	}
-	addGauge(descStatefulSetMetadataGeneration, float64(s.ObjectMeta.Generation))
-
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(s.Labels)
-	addGauge(statefulSetLabelsDesc(labelKeys), 1, labelValues...)
-
-	addGauge(descStatefulSetCurrentRevision, 1, s.Status.CurrentRevision)
-	addGauge(descStatefulSetUpdateRevision, 1, s.Status.UpdateRevision)
-	return ms
 }
@@ -0,0 +1,104 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"sync"
+	"time"
+)
+
+// CollectorStatus is the sync status of a collector, see Collector.Status.
+type CollectorStatus struct {
+	Name string `json:"name"`
+	// Synced is true once each reflector of the collector completed its
+	// initial list.
+	Synced        bool       `json:"synced"`
+	LastEventTime *time.Time `json:"lastEventTime,omitempty"`
+	LastError     string     `json:"lastError,omitempty"`
+	LastErrorTime *time.Time `json:"lastErrorTime,omitempty"`
+}
+
+// Name returns the name of the collector, e.g. "pods".
+func (c *Collector) Name() string {
+	return c.name
+}
+
+// Status returns the sync status of the collector. Collectors without any
+// reflector, like the events collector with its metric blacklisted, are
+// always synced.
+func (c *Collector) Status() CollectorStatus {
+	status := CollectorStatus{Name: c.name, Synced: true}
+
+	for _, r := range c.reflectorSets {
+		if !r.synced() {
+			status.Synced = false
+		}
+
+		r.status.mutex.Lock()
+		if !r.status.lastEventTime.IsZero() && (status.LastEventTime == nil || r.status.lastEventTime.After(*status.LastEventTime)) {
+			t := r.status.lastEventTime
+			status.LastEventTime = &t
+		}
+		if r.status.lastError != "" && (status.LastErrorTime == nil || r.status.lastErrorTime.After(*status.LastErrorTime)) {
+			t := r.status.lastErrorTime
+			status.LastError = r.status.lastError
+			status.LastErrorTime = &t
+		}
+		r.status.mutex.Unlock()
+	}
+
+	return status
+}
+
+// reflectorStatus records the last event and the last error of the
+// reflectors of a reflectorSet.
+type reflectorStatus struct {
+	mutex         sync.Mutex
+	lastEventTime time.Time
+	lastError     string
+	lastErrorTime time.Time
+	now           func() time.Time
+}
+
+func newReflectorStatus() *reflectorStatus {
+	return &reflectorStatus{now: time.Now}
+}
+
+// observeEvent records an event received by a reflector, and the error
+// returned by the store for it, if any.
+func (s *reflectorStatus) observeEvent(err error) error {
+	s.mutex.Lock()
+	s.lastEventTime = s.now()
+	s.mutex.Unlock()
+
+	return s.observeError(err)
+}
+
+// observeError records the given error, if any, and returns it.
+func (s *reflectorStatus) observeError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.lastError = err.Error()
+	s.lastErrorTime = s.now()
+
+	return err
+}
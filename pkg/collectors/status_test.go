@@ -0,0 +1,119 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+// TestCollectorStatus ensures that collectors only report themselves as
+// synced once their initial list completed, and report list errors.
+func TestCollectorStatus(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset(
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "ns1", UID: "uid-1"}},
+	)
+
+	listPods := make(chan struct{})
+	kubeClient.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		<-listPods
+		return false, nil, nil
+	})
+	kubeClient.PrependReactor("list", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("secrets is forbidden")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := NewBuilder(ctx, options.NewOptions())
+	b.WithNamespaces(options.DefaultNamespaces)
+	b.WithKubeClient(kubeClient)
+	pods := b.buildCollector("pods", b.buildPodCollector)
+	secrets := b.buildCollector("secrets", b.buildSecretCollector)
+
+	status := waitForStatus(t, secrets, func(s CollectorStatus) bool { return s.LastError != "" })
+	if status.Name != "secrets" || status.Synced || status.LastErrorTime == nil || !strings.Contains(status.LastError, "secrets is forbidden") {
+		t.Errorf("expected secrets collector to report the list error, got %+v", status)
+	}
+
+	if status := pods.Status(); status.Name != "pods" || status.Synced || status.LastEventTime != nil {
+		t.Errorf("expected pods collector not to be synced before the list completed, got %+v", status)
+	}
+
+	close(listPods)
+	status = waitForStatus(t, pods, func(s CollectorStatus) bool { return s.Synced })
+	if status.LastEventTime == nil || status.LastError != "" {
+		t.Errorf("expected pods collector to report its last event, got %+v", status)
+	}
+}
+
+// TestCollectorStatusNamespaceSelector ensures that collectors following the
+// namespace selector are not synced before the selected namespaces are known.
+func TestCollectorStatusNamespaceSelector(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset(
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1", Labels: map[string]string{"monitoring": "enabled"}}},
+	)
+
+	listNamespaces := make(chan struct{})
+	kubeClient.PrependReactor("list", "namespaces", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		<-listNamespaces
+		return false, nil, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := NewBuilder(ctx, options.NewOptions())
+	b.WithEnabledCollectors(options.CollectorSet{"pods": struct{}{}})
+	b.WithNamespaceSelector(labels.SelectorFromSet(labels.Set{"monitoring": "enabled"}))
+	b.WithKubeClient(kubeClient)
+	pods := b.Build()[0]
+
+	time.Sleep(100 * time.Millisecond)
+	if pods.Status().Synced {
+		t.Error("expected pods collector not to be synced before the namespaces were listed")
+	}
+
+	close(listNamespaces)
+	waitForStatus(t, pods, func(s CollectorStatus) bool { return s.Synced })
+}
+
+func waitForStatus(t *testing.T, c *Collector, done func(CollectorStatus) bool) CollectorStatus {
+	var status CollectorStatus
+	for i := 0; i < 100; i++ {
+		status = c.Status()
+		if done(status) {
+			return status
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	t.Fatalf("timed out waiting for status of %s, got %+v", c.Name(), status)
+	return status
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"k8s.io/kube-state-metrics/pkg/metrics"
+
+	"k8s.io/api/core/v1"
+	storage "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	descStorageClassLabelsName          = "kube_storageclass_labels"
+	descStorageClassLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descStorageClassLabelsDefaultLabels = []string{"storageclass"}
+
+	descStorageClassAnnotationsName = "kube_storageclass_annotations"
+	descStorageClassAnnotationsHelp = "Kubernetes annotations converted to Prometheus labels."
+
+	// Defaults applied by the API server if the fields are unset.
+	defaultReclaimPolicy     = v1.PersistentVolumeReclaimDelete
+	defaultVolumeBindingMode = storage.VolumeBindingImmediate
+
+	// isDefaultStorageClassAnnotations mark the default storage class of a
+	// cluster, the beta annotation still being honored.
+	isDefaultStorageClassAnnotations = []string{
+		"storageclass.kubernetes.io/is-default-class",
+		"storageclass.beta.kubernetes.io/is-default-class",
+	}
+)
+
+func storageClassMetricFamilies(allowedLabels, allowedAnnotations keyAllowList) []metricFamilyDef {
+	return []metricFamilyDef{
+		{
+			Name: "kube_storageclass_info",
+			Type: metrics.MetricTypeGauge,
+			Help: "Information about storageclass.",
+			GenerateFunc: wrapStorageClassFunc(func(s *storage.StorageClass) []*metrics.Metric {
+				reclaimPolicy := defaultReclaimPolicy
+				if s.ReclaimPolicy != nil {
+					reclaimPolicy = *s.ReclaimPolicy
+				}
+
+				volumeBindingMode := defaultVolumeBindingMode
+				if s.VolumeBindingMode != nil {
+					volumeBindingMode = *s.VolumeBindingMode
+				}
+
+				return []*metrics.Metric{{
+					LabelKeys:   []string{"provisioner", "reclaim_policy", "volume_binding_mode"},
+					LabelValues: []string{s.Provisioner, string(reclaimPolicy), string(volumeBindingMode)},
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: descStorageClassLabelsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descStorageClassLabelsHelp,
+			GenerateFunc: wrapStorageClassFunc(func(s *storage.StorageClass) []*metrics.Metric {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(s.Labels, allowedLabels)
+				return []*metrics.Metric{{
+					LabelKeys:   labelKeys,
+					LabelValues: labelValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: descStorageClassAnnotationsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descStorageClassAnnotationsHelp,
+			GenerateFunc: wrapStorageClassFunc(func(s *storage.StorageClass) []*metrics.Metric {
+				annotationKeys, annotationValues := kubeAnnotationsToPrometheusAnnotations(s.Annotations, allowedAnnotations)
+				return []*metrics.Metric{{
+					LabelKeys:   annotationKeys,
+					LabelValues: annotationValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_storageclass_created",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapStorageClassFunc(func(s *storage.StorageClass) []*metrics.Metric {
+				return createdMetric(s.CreationTimestamp)
+			}),
+		},
+		{
+			Name: "kube_storageclass_allow_volume_expansion",
+			Type: metrics.MetricTypeGauge,
+			Help: "Whether volumes of the storageclass can be expanded.",
+			GenerateFunc: wrapStorageClassFunc(func(s *storage.StorageClass) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: boolFloat64(s.AllowVolumeExpansion != nil && *s.AllowVolumeExpansion),
+				}}
+			}),
+		},
+		{
+			Name: "kube_storageclass_default",
+			Type: metrics.MetricTypeGauge,
+			Help: "Whether the storageclass is annotated as the default storageclass of the cluster.",
+			GenerateFunc: wrapStorageClassFunc(func(s *storage.StorageClass) []*metrics.Metric {
+				isDefault := false
+				for _, a := range isDefaultStorageClassAnnotations {
+					if s.Annotations[a] == "true" {
+						isDefault = true
+					}
+				}
+
+				return []*metrics.Metric{{
+					Value: boolFloat64(isDefault),
+				}}
+			}),
+		},
+	}
+}
+
+func createStorageClassListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
+	return cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return kubeClient.StorageV1().StorageClasses().List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return kubeClient.StorageV1().StorageClasses().Watch(opts)
+		},
+	}
+}
+
+func wrapStorageClassFunc(f func(*storage.StorageClass) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		storageClass := obj.(*storage.StorageClass)
+
+		ms := f(storageClass)
+
+		for _, m := range ms {
+			m.LabelKeys = append(descStorageClassLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{storageClass.Name}, m.LabelValues...)
+		}
+
+		return ms
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/api/core/v1"
+	storage "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStorageClassCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_storageclass_info Information about storageclass.
+		# TYPE kube_storageclass_info gauge
+		# HELP kube_storageclass_labels Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_storageclass_labels gauge
+		# HELP kube_storageclass_annotations Kubernetes annotations converted to Prometheus labels.
+		# TYPE kube_storageclass_annotations gauge
+		# HELP kube_storageclass_created Unix creation timestamp
+		# TYPE kube_storageclass_created gauge
+		# HELP kube_storageclass_allow_volume_expansion Whether volumes of the storageclass can be expanded.
+		# TYPE kube_storageclass_allow_volume_expansion gauge
+		# HELP kube_storageclass_default Whether the storageclass is annotated as the default storageclass of the cluster.
+		# TYPE kube_storageclass_default gauge
+	`
+
+	retain := v1.PersistentVolumeReclaimRetain
+	waitForFirstConsumer := storage.VolumeBindingWaitForFirstConsumer
+	allowVolumeExpansion := true
+
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &storage.StorageClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "standard",
+				},
+				Provisioner: "kubernetes.io/gce-pd",
+			},
+			Want: `
+				kube_storageclass_allow_volume_expansion{storageclass="standard"} 0
+				kube_storageclass_default{storageclass="standard"} 0
+				kube_storageclass_info{provisioner="kubernetes.io/gce-pd",reclaim_policy="Delete",storageclass="standard",volume_binding_mode="Immediate"} 1
+				kube_storageclass_labels{storageclass="standard"} 1
+			`,
+			MetricNames: []string{
+				"kube_storageclass_allow_volume_expansion",
+				"kube_storageclass_default",
+				"kube_storageclass_created",
+				"kube_storageclass_info",
+				"kube_storageclass_labels",
+			},
+		},
+		{
+			Obj: &storage.StorageClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "fast",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+					Labels: map[string]string{
+						"tier": "ssd",
+					},
+					Annotations: map[string]string{
+						"storageclass.kubernetes.io/is-default-class": "true",
+					},
+				},
+				Provisioner:          "ebs.csi.aws.com",
+				ReclaimPolicy:        &retain,
+				VolumeBindingMode:    &waitForFirstConsumer,
+				AllowVolumeExpansion: &allowVolumeExpansion,
+			},
+			Want: `
+				kube_storageclass_allow_volume_expansion{storageclass="fast"} 1
+				kube_storageclass_created{storageclass="fast"} 1.5e+09
+				kube_storageclass_default{storageclass="fast"} 1
+				kube_storageclass_info{provisioner="ebs.csi.aws.com",reclaim_policy="Retain",storageclass="fast",volume_binding_mode="WaitForFirstConsumer"} 1
+				kube_storageclass_labels{label_tier="ssd",storageclass="fast"} 1
+			`,
+			MetricNames: []string{
+				"kube_storageclass_allow_volume_expansion",
+				"kube_storageclass_default",
+				"kube_storageclass_created",
+				"kube_storageclass_info",
+				"kube_storageclass_labels",
+			},
+		},
+		{
+			Obj: &storage.StorageClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "legacy-default",
+					Annotations: map[string]string{
+						"storageclass.beta.kubernetes.io/is-default-class": "true",
+					},
+				},
+			},
+			Want: `
+				kube_storageclass_default{storageclass="legacy-default"} 1
+			`,
+			MetricNames: []string{"kube_storageclass_default"},
+		},
+	}
+	for i, c := range cases {
+		c.Func = composeMetricGenFuncs(storageClassMetricFamilies(allKeys, allKeys))
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
@@ -31,19 +31,19 @@ type generateMetricsTestCase struct {
 	Obj         interface{}
 	MetricNames []string
 	Want        string
-	Func        func(interface{}) []*metrics.Metric
+	Func        func(interface{}) []metrics.Family
 }
 
 func (testCase *generateMetricsTestCase) run() error {
-	metrics := testCase.Func(testCase.Obj)
-	metrics = filterMetrics(metrics, testCase.MetricNames)
+	metricFamilies := testCase.Func(testCase.Obj)
 
 	out := ""
 
-	for _, m := range metrics {
-		out += string(*m)
+	for _, f := range metricFamilies {
+		out += f.String()
 	}
 
+	out = filterMetrics(out, testCase.MetricNames)
 	out = removeUnusedWhitespace(out)
 	out = sortByLine(out)
 
@@ -63,23 +63,23 @@ func sortByLine(s string) string {
 	return strings.Join(split, "\n")
 }
 
-func filterMetrics(ms []*metrics.Metric, names []string) []*metrics.Metric {
+func filterMetrics(s string, names []string) string {
 	// In case the test case is based on all returned metrics, MetricNames does
 	// not need to me defined.
 	if names == nil {
-		return ms
+		return s
 	}
-	filtered := []*metrics.Metric{}
+	filtered := []string{}
 
 	regexps := []*regexp.Regexp{}
 	for _, n := range names {
 		regexps = append(regexps, regexp.MustCompile(fmt.Sprintf("^%v", n)))
 	}
 
-	for _, m := range ms {
+	for _, m := range strings.Split(s, "\n") {
 		drop := true
 		for _, r := range regexps {
-			if r.MatchString(string(*m)) {
+			if r.MatchString(m) {
 				drop = false
 				break
 			}
@@ -88,7 +88,7 @@ func filterMetrics(ms []*metrics.Metric, names []string) []*metrics.Metric {
 			filtered = append(filtered, m)
 		}
 	}
-	return filtered
+	return strings.Join(filtered, "\n")
 }
 
 func removeUnusedWhitespace(s string) string {
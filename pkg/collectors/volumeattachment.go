@@ -0,0 +1,162 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"k8s.io/kube-state-metrics/pkg/metrics"
+
+	storage "k8s.io/api/storage/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	descVolumeAttachmentLabelsName          = "kube_volumeattachment_labels"
+	descVolumeAttachmentLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descVolumeAttachmentLabelsDefaultLabels = []string{"volumeattachment"}
+
+	descVolumeAttachmentAnnotationsName = "kube_volumeattachment_annotations"
+	descVolumeAttachmentAnnotationsHelp = "Kubernetes annotations converted to Prometheus labels."
+)
+
+func volumeAttachmentMetricFamilies(allowedLabels, allowedAnnotations keyAllowList) []metricFamilyDef {
+	return []metricFamilyDef{
+		{
+			Name: "kube_volumeattachment_info",
+			Type: metrics.MetricTypeGauge,
+			Help: "Information about volumeattachment.",
+			GenerateFunc: wrapVolumeAttachmentFunc(func(v *storage.VolumeAttachment) []*metrics.Metric {
+				return []*metrics.Metric{{
+					LabelKeys:   []string{"attacher", "node"},
+					LabelValues: []string{v.Spec.Attacher, v.Spec.NodeName},
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: descVolumeAttachmentLabelsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descVolumeAttachmentLabelsHelp,
+			GenerateFunc: wrapVolumeAttachmentFunc(func(v *storage.VolumeAttachment) []*metrics.Metric {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(v.Labels, allowedLabels)
+				return []*metrics.Metric{{
+					LabelKeys:   labelKeys,
+					LabelValues: labelValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: descVolumeAttachmentAnnotationsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descVolumeAttachmentAnnotationsHelp,
+			GenerateFunc: wrapVolumeAttachmentFunc(func(v *storage.VolumeAttachment) []*metrics.Metric {
+				annotationKeys, annotationValues := kubeAnnotationsToPrometheusAnnotations(v.Annotations, allowedAnnotations)
+				return []*metrics.Metric{{
+					LabelKeys:   annotationKeys,
+					LabelValues: annotationValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_volumeattachment_created",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapVolumeAttachmentFunc(func(v *storage.VolumeAttachment) []*metrics.Metric {
+				return createdMetric(v.CreationTimestamp)
+			}),
+		},
+		{
+			Name: "kube_volumeattachment_spec_source_persistentvolume",
+			Type: metrics.MetricTypeGauge,
+			Help: "PersistentVolume source reference.",
+			GenerateFunc: wrapVolumeAttachmentFunc(func(v *storage.VolumeAttachment) []*metrics.Metric {
+				ms := []*metrics.Metric{}
+
+				if pv := v.Spec.Source.PersistentVolumeName; pv != nil {
+					ms = append(ms, &metrics.Metric{
+						LabelKeys:   []string{"volumename"},
+						LabelValues: []string{*pv},
+						Value:       1,
+					})
+				}
+
+				return ms
+			}),
+		},
+		{
+			Name: "kube_volumeattachment_status_attached",
+			Type: metrics.MetricTypeGauge,
+			Help: "Whether the volume is successfully attached.",
+			GenerateFunc: wrapVolumeAttachmentFunc(func(v *storage.VolumeAttachment) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: boolFloat64(v.Status.Attached),
+				}}
+			}),
+		},
+		{
+			Name: "kube_volumeattachment_status_attach_error",
+			Type: metrics.MetricTypeGauge,
+			Help: "Whether the last attach operation failed.",
+			GenerateFunc: wrapVolumeAttachmentFunc(func(v *storage.VolumeAttachment) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: boolFloat64(v.Status.AttachError != nil),
+				}}
+			}),
+		},
+		{
+			Name: "kube_volumeattachment_status_detach_error",
+			Type: metrics.MetricTypeGauge,
+			Help: "Whether the last detach operation failed.",
+			GenerateFunc: wrapVolumeAttachmentFunc(func(v *storage.VolumeAttachment) []*metrics.Metric {
+				return []*metrics.Metric{{
+					Value: boolFloat64(v.Status.DetachError != nil),
+				}}
+			}),
+		},
+	}
+}
+
+func createVolumeAttachmentListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
+	return cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return kubeClient.StorageV1beta1().VolumeAttachments().List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return kubeClient.StorageV1beta1().VolumeAttachments().Watch(opts)
+		},
+	}
+}
+
+func wrapVolumeAttachmentFunc(f func(*storage.VolumeAttachment) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		volumeAttachment := obj.(*storage.VolumeAttachment)
+
+		ms := f(volumeAttachment)
+
+		for _, m := range ms {
+			m.LabelKeys = append(descVolumeAttachmentLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{volumeAttachment.Name}, m.LabelValues...)
+		}
+
+		return ms
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+	"time"
+
+	storage "k8s.io/api/storage/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestVolumeAttachmentCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_volumeattachment_info Information about volumeattachment.
+		# TYPE kube_volumeattachment_info gauge
+		# HELP kube_volumeattachment_labels Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_volumeattachment_labels gauge
+		# HELP kube_volumeattachment_annotations Kubernetes annotations converted to Prometheus labels.
+		# TYPE kube_volumeattachment_annotations gauge
+		# HELP kube_volumeattachment_created Unix creation timestamp
+		# TYPE kube_volumeattachment_created gauge
+		# HELP kube_volumeattachment_spec_source_persistentvolume PersistentVolume source reference.
+		# TYPE kube_volumeattachment_spec_source_persistentvolume gauge
+		# HELP kube_volumeattachment_status_attached Whether the volume is successfully attached.
+		# TYPE kube_volumeattachment_status_attached gauge
+		# HELP kube_volumeattachment_status_attach_error Whether the last attach operation failed.
+		# TYPE kube_volumeattachment_status_attach_error gauge
+		# HELP kube_volumeattachment_status_detach_error Whether the last detach operation failed.
+		# TYPE kube_volumeattachment_status_detach_error gauge
+	`
+
+	pv := "pvc-0123"
+
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &storage.VolumeAttachment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "csi-attached",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+				},
+				Spec: storage.VolumeAttachmentSpec{
+					Attacher: "ebs.csi.aws.com",
+					NodeName: "node1",
+					Source: storage.VolumeAttachmentSource{
+						PersistentVolumeName: &pv,
+					},
+				},
+				Status: storage.VolumeAttachmentStatus{
+					Attached: true,
+				},
+			},
+			Want: `
+				kube_volumeattachment_created{volumeattachment="csi-attached"} 1.5e+09
+				kube_volumeattachment_info{attacher="ebs.csi.aws.com",node="node1",volumeattachment="csi-attached"} 1
+				kube_volumeattachment_spec_source_persistentvolume{volumeattachment="csi-attached",volumename="pvc-0123"} 1
+				kube_volumeattachment_status_attach_error{volumeattachment="csi-attached"} 0
+				kube_volumeattachment_status_attached{volumeattachment="csi-attached"} 1
+				kube_volumeattachment_status_detach_error{volumeattachment="csi-attached"} 0
+			`,
+			MetricNames: []string{
+				"kube_volumeattachment_created",
+				"kube_volumeattachment_info",
+				"kube_volumeattachment_spec_source_persistentvolume",
+				"kube_volumeattachment_status_attach_error",
+				"kube_volumeattachment_status_attached",
+				"kube_volumeattachment_status_detach_error",
+			},
+		},
+		{
+			Obj: &storage.VolumeAttachment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "csi-stuck",
+				},
+				Spec: storage.VolumeAttachmentSpec{
+					Attacher: "ebs.csi.aws.com",
+					NodeName: "node2",
+				},
+				Status: storage.VolumeAttachmentStatus{
+					AttachError: &storage.VolumeError{Message: "timed out"},
+					DetachError: &storage.VolumeError{Message: "timed out"},
+				},
+			},
+			Want: `
+				kube_volumeattachment_info{attacher="ebs.csi.aws.com",node="node2",volumeattachment="csi-stuck"} 1
+				kube_volumeattachment_status_attach_error{volumeattachment="csi-stuck"} 1
+				kube_volumeattachment_status_attached{volumeattachment="csi-stuck"} 0
+				kube_volumeattachment_status_detach_error{volumeattachment="csi-stuck"} 1
+			`,
+			MetricNames: []string{
+				"kube_volumeattachment_created",
+				"kube_volumeattachment_info",
+				"kube_volumeattachment_spec_source_persistentvolume",
+				"kube_volumeattachment_status_attach_error",
+				"kube_volumeattachment_status_attached",
+				"kube_volumeattachment_status_detach_error",
+			},
+		},
+	}
+	for i, c := range cases {
+		c.Func = composeMetricGenFuncs(volumeAttachmentMetricFamilies(allKeys, allKeys))
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"strconv"
+
+	"k8s.io/kube-state-metrics/pkg/metrics"
+
+	admissionregistration "k8s.io/api/admissionregistration/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	descValidatingWebhookConfigurationLabelsName          = "kube_validatingwebhookconfiguration_labels"
+	descValidatingWebhookConfigurationLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descValidatingWebhookConfigurationLabelsDefaultLabels = []string{"validatingwebhookconfiguration"}
+
+	descValidatingWebhookConfigurationAnnotationsName = "kube_validatingwebhookconfiguration_annotations"
+	descValidatingWebhookConfigurationAnnotationsHelp = "Kubernetes annotations converted to Prometheus labels."
+
+	descMutatingWebhookConfigurationLabelsName          = "kube_mutatingwebhookconfiguration_labels"
+	descMutatingWebhookConfigurationLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descMutatingWebhookConfigurationLabelsDefaultLabels = []string{"mutatingwebhookconfiguration"}
+
+	descMutatingWebhookConfigurationAnnotationsName = "kube_mutatingwebhookconfiguration_annotations"
+	descMutatingWebhookConfigurationAnnotationsHelp = "Kubernetes annotations converted to Prometheus labels."
+
+	// Applied by the API server if a webhook has no failure policy, side
+	// effects or timeout set.
+	defaultWebhookFailurePolicy  = admissionregistration.Ignore
+	defaultWebhookSideEffects    = admissionregistration.SideEffectClassUnknown
+	defaultWebhookTimeoutSeconds = int32(30)
+)
+
+func validatingWebhookConfigurationMetricFamilies(allowedLabels, allowedAnnotations keyAllowList) []metricFamilyDef {
+	return []metricFamilyDef{
+		{
+			Name: descValidatingWebhookConfigurationLabelsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descValidatingWebhookConfigurationLabelsHelp,
+			GenerateFunc: wrapValidatingWebhookConfigurationFunc(func(w *admissionregistration.ValidatingWebhookConfiguration) []*metrics.Metric {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(w.Labels, allowedLabels)
+				return []*metrics.Metric{{
+					LabelKeys:   labelKeys,
+					LabelValues: labelValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: descValidatingWebhookConfigurationAnnotationsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descValidatingWebhookConfigurationAnnotationsHelp,
+			GenerateFunc: wrapValidatingWebhookConfigurationFunc(func(w *admissionregistration.ValidatingWebhookConfiguration) []*metrics.Metric {
+				annotationKeys, annotationValues := kubeAnnotationsToPrometheusAnnotations(w.Annotations, allowedAnnotations)
+				return []*metrics.Metric{{
+					LabelKeys:   annotationKeys,
+					LabelValues: annotationValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_validatingwebhookconfiguration_created",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapValidatingWebhookConfigurationFunc(func(w *admissionregistration.ValidatingWebhookConfiguration) []*metrics.Metric {
+				return createdMetric(w.CreationTimestamp)
+			}),
+		},
+		{
+			Name: "kube_validatingwebhookconfiguration_webhook",
+			Type: metrics.MetricTypeGauge,
+			Help: "Information about a webhook of the validatingwebhookconfiguration.",
+			GenerateFunc: wrapValidatingWebhookConfigurationFunc(func(w *admissionregistration.ValidatingWebhookConfiguration) []*metrics.Metric {
+				return webhookMetrics(w.Webhooks)
+			}),
+		},
+	}
+}
+
+func mutatingWebhookConfigurationMetricFamilies(allowedLabels, allowedAnnotations keyAllowList) []metricFamilyDef {
+	return []metricFamilyDef{
+		{
+			Name: descMutatingWebhookConfigurationLabelsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descMutatingWebhookConfigurationLabelsHelp,
+			GenerateFunc: wrapMutatingWebhookConfigurationFunc(func(w *admissionregistration.MutatingWebhookConfiguration) []*metrics.Metric {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(w.Labels, allowedLabels)
+				return []*metrics.Metric{{
+					LabelKeys:   labelKeys,
+					LabelValues: labelValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: descMutatingWebhookConfigurationAnnotationsName,
+			Type: metrics.MetricTypeGauge,
+			Help: descMutatingWebhookConfigurationAnnotationsHelp,
+			GenerateFunc: wrapMutatingWebhookConfigurationFunc(func(w *admissionregistration.MutatingWebhookConfiguration) []*metrics.Metric {
+				annotationKeys, annotationValues := kubeAnnotationsToPrometheusAnnotations(w.Annotations, allowedAnnotations)
+				return []*metrics.Metric{{
+					LabelKeys:   annotationKeys,
+					LabelValues: annotationValues,
+					Value:       1,
+				}}
+			}),
+		},
+		{
+			Name: "kube_mutatingwebhookconfiguration_created",
+			Type: metrics.MetricTypeGauge,
+			Help: "Unix creation timestamp",
+			GenerateFunc: wrapMutatingWebhookConfigurationFunc(func(w *admissionregistration.MutatingWebhookConfiguration) []*metrics.Metric {
+				return createdMetric(w.CreationTimestamp)
+			}),
+		},
+		{
+			Name: "kube_mutatingwebhookconfiguration_webhook",
+			Type: metrics.MetricTypeGauge,
+			Help: "Information about a webhook of the mutatingwebhookconfiguration.",
+			GenerateFunc: wrapMutatingWebhookConfigurationFunc(func(w *admissionregistration.MutatingWebhookConfiguration) []*metrics.Metric {
+				return webhookMetrics(w.Webhooks)
+			}),
+		},
+	}
+}
+
+// webhookMetrics returns one metric per webhook, shared by the validating and
+// mutating webhook configurations. A webhook either targets an in-cluster
+// service or an URL, the labels of the other being left empty.
+func webhookMetrics(webhooks []admissionregistration.Webhook) []*metrics.Metric {
+	ms := make([]*metrics.Metric, len(webhooks))
+
+	for i, w := range webhooks {
+		failurePolicy := defaultWebhookFailurePolicy
+		if w.FailurePolicy != nil {
+			failurePolicy = *w.FailurePolicy
+		}
+		sideEffects := defaultWebhookSideEffects
+		if w.SideEffects != nil {
+			sideEffects = *w.SideEffects
+		}
+		timeoutSeconds := defaultWebhookTimeoutSeconds
+		if w.TimeoutSeconds != nil {
+			timeoutSeconds = *w.TimeoutSeconds
+		}
+
+		// The API server defaults an unset selector to the empty selector,
+		// both matching all namespaces.
+		namespaceSelector := w.NamespaceSelector != nil &&
+			(len(w.NamespaceSelector.MatchLabels) > 0 || len(w.NamespaceSelector.MatchExpressions) > 0)
+
+		var serviceNamespace, serviceName, servicePath, url string
+		if s := w.ClientConfig.Service; s != nil {
+			serviceNamespace, serviceName = s.Namespace, s.Name
+			if s.Path != nil {
+				servicePath = *s.Path
+			}
+		}
+		if w.ClientConfig.URL != nil {
+			url = *w.ClientConfig.URL
+		}
+
+		ms[i] = &metrics.Metric{
+			LabelKeys:   []string{"webhook", "failure_policy", "side_effects", "timeout_seconds", "namespace_selector", "service_namespace", "service_name", "service_path", "url"},
+			LabelValues: []string{w.Name, string(failurePolicy), string(sideEffects), strconv.Itoa(int(timeoutSeconds)), strconv.FormatBool(namespaceSelector), serviceNamespace, serviceName, servicePath, url},
+			Value:       1,
+		}
+	}
+
+	return ms
+}
+
+func createValidatingWebhookConfigurationListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
+	return cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return kubeClient.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations().List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return kubeClient.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations().Watch(opts)
+		},
+	}
+}
+
+func createMutatingWebhookConfigurationListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
+	return cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return kubeClient.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return kubeClient.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Watch(opts)
+		},
+	}
+}
+
+func wrapValidatingWebhookConfigurationFunc(f func(*admissionregistration.ValidatingWebhookConfiguration) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		webhookConfiguration := obj.(*admissionregistration.ValidatingWebhookConfiguration)
+
+		ms := f(webhookConfiguration)
+
+		for _, m := range ms {
+			m.LabelKeys = append(descValidatingWebhookConfigurationLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{webhookConfiguration.Name}, m.LabelValues...)
+		}
+
+		return ms
+	}
+}
+
+func wrapMutatingWebhookConfigurationFunc(f func(*admissionregistration.MutatingWebhookConfiguration) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		webhookConfiguration := obj.(*admissionregistration.MutatingWebhookConfiguration)
+
+		ms := f(webhookConfiguration)
+
+		for _, m := range ms {
+			m.LabelKeys = append(descMutatingWebhookConfigurationLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{webhookConfiguration.Name}, m.LabelValues...)
+		}
+
+		return ms
+	}
+}
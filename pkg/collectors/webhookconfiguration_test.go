@@ -0,0 +1,164 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+	"time"
+
+	admissionregistration "k8s.io/api/admissionregistration/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidatingWebhookConfigurationCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_validatingwebhookconfiguration_labels Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_validatingwebhookconfiguration_labels gauge
+		# HELP kube_validatingwebhookconfiguration_annotations Kubernetes annotations converted to Prometheus labels.
+		# TYPE kube_validatingwebhookconfiguration_annotations gauge
+		# HELP kube_validatingwebhookconfiguration_created Unix creation timestamp
+		# TYPE kube_validatingwebhookconfiguration_created gauge
+		# HELP kube_validatingwebhookconfiguration_webhook Information about a webhook of the validatingwebhookconfiguration.
+		# TYPE kube_validatingwebhookconfiguration_webhook gauge
+	`
+
+	fail := admissionregistration.Fail
+	sideEffects := admissionregistration.SideEffectClassNone
+	timeout := int32(5)
+	path := "/validate"
+	url := "https://policy.example.com/validate"
+
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &admissionregistration.ValidatingWebhookConfiguration{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "policy",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+				},
+				Webhooks: []admissionregistration.Webhook{
+					{
+						Name:           "pods.policy.example.com",
+						FailurePolicy:  &fail,
+						SideEffects:    &sideEffects,
+						TimeoutSeconds: &timeout,
+						ClientConfig: admissionregistration.WebhookClientConfig{
+							Service: &admissionregistration.ServiceReference{
+								Namespace: "policy-system",
+								Name:      "policy-webhook",
+								Path:      &path,
+							},
+						},
+						NamespaceSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"policy": "enabled"},
+						},
+					},
+					{
+						Name: "external.policy.example.com",
+						ClientConfig: admissionregistration.WebhookClientConfig{
+							URL: &url,
+						},
+						NamespaceSelector: &metav1.LabelSelector{},
+					},
+				},
+			},
+			Want: `
+				kube_validatingwebhookconfiguration_created{validatingwebhookconfiguration="policy"} 1.5e+09
+				kube_validatingwebhookconfiguration_webhook{failure_policy="Fail",namespace_selector="true",service_name="policy-webhook",service_namespace="policy-system",service_path="/validate",side_effects="None",timeout_seconds="5",url="",validatingwebhookconfiguration="policy",webhook="pods.policy.example.com"} 1
+				kube_validatingwebhookconfiguration_webhook{failure_policy="Ignore",namespace_selector="false",service_name="",service_namespace="",service_path="",side_effects="Unknown",timeout_seconds="30",url="https://policy.example.com/validate",validatingwebhookconfiguration="policy",webhook="external.policy.example.com"} 1
+			`,
+			MetricNames: []string{
+				"kube_validatingwebhookconfiguration_created",
+				"kube_validatingwebhookconfiguration_webhook",
+			},
+		},
+	}
+	for i, c := range cases {
+		c.Func = composeMetricGenFuncs(validatingWebhookConfigurationMetricFamilies(allKeys, allKeys))
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
+
+func TestMutatingWebhookConfigurationCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_mutatingwebhookconfiguration_labels Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_mutatingwebhookconfiguration_labels gauge
+		# HELP kube_mutatingwebhookconfiguration_annotations Kubernetes annotations converted to Prometheus labels.
+		# TYPE kube_mutatingwebhookconfiguration_annotations gauge
+		# HELP kube_mutatingwebhookconfiguration_created Unix creation timestamp
+		# TYPE kube_mutatingwebhookconfiguration_created gauge
+		# HELP kube_mutatingwebhookconfiguration_webhook Information about a webhook of the mutatingwebhookconfiguration.
+		# TYPE kube_mutatingwebhookconfiguration_webhook gauge
+	`
+
+	fail := admissionregistration.Fail
+	sideEffects := admissionregistration.SideEffectClassSome
+
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &admissionregistration.MutatingWebhookConfiguration{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "sidecar-injector",
+					Labels: map[string]string{
+						"app": "injector",
+					},
+				},
+				Webhooks: []admissionregistration.Webhook{
+					{
+						Name:          "sidecar.example.com",
+						FailurePolicy: &fail,
+						SideEffects:   &sideEffects,
+						ClientConfig: admissionregistration.WebhookClientConfig{
+							Service: &admissionregistration.ServiceReference{
+								Namespace: "injector",
+								Name:      "sidecar-injector",
+							},
+						},
+					},
+				},
+			},
+			Want: `
+				kube_mutatingwebhookconfiguration_labels{label_app="injector",mutatingwebhookconfiguration="sidecar-injector"} 1
+				kube_mutatingwebhookconfiguration_webhook{failure_policy="Fail",mutatingwebhookconfiguration="sidecar-injector",namespace_selector="false",service_name="sidecar-injector",service_namespace="injector",service_path="",side_effects="Some",timeout_seconds="30",url="",webhook="sidecar.example.com"} 1
+			`,
+			MetricNames: []string{
+				"kube_mutatingwebhookconfiguration_labels",
+				"kube_mutatingwebhookconfiguration_webhook",
+			},
+		},
+		{
+			Obj: &admissionregistration.MutatingWebhookConfiguration{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "empty",
+				},
+			},
+			Want:        ``,
+			MetricNames: []string{"kube_mutatingwebhookconfiguration_webhook"},
+		},
+	}
+	for i, c := range cases {
+		c.Func = composeMetricGenFuncs(mutatingWebhookConfigurationMetricFamilies(allKeys, allKeys))
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
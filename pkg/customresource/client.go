@@ -0,0 +1,48 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package customresource
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/runtime/serializer/json"
+	"k8s.io/client-go/rest"
+)
+
+// NewClient returns a REST client able to list and watch arbitrary custom
+// resources as unstructured objects, based on the given config.
+func NewClient(config *rest.Config) (rest.Interface, error) {
+	c := *config
+
+	c.APIPath = ""
+	c.GroupVersion = nil
+	c.AcceptContentTypes = "application/json"
+	c.ContentType = "application/json"
+	c.NegotiatedSerializer = serializer.NegotiatedSerializerWrapper(
+		runtime.SerializerInfo{
+			Serializer: unstructured.UnstructuredJSONScheme,
+			StreamSerializer: &runtime.StreamSerializerInfo{
+				Serializer:    unstructured.UnstructuredJSONScheme,
+				Framer:        json.Framer,
+				EncodesAsText: true,
+			},
+		},
+	)
+
+	return rest.UnversionedRESTClientFor(&c)
+}
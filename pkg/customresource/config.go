@@ -0,0 +1,378 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package customresource
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+const (
+	// MetricNamePrefix is the prefix of all custom resource metric names.
+	MetricNamePrefix = "kube_crd_"
+
+	metricTypeGauge   = "gauge"
+	metricTypeCounter = "counter"
+)
+
+var (
+	metricNameRE = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	labelNameRE  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// Config declares the custom resources to expose metrics for.
+type Config struct {
+	Resources []Resource `json:"resources"`
+}
+
+// Resource declares a custom resource kind and the metrics generated for
+// each of its objects.
+type Resource struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+	// Resource is the plural resource name used in the API path, e.g.
+	// "certificates".
+	Resource   string   `json:"resource"`
+	Namespaced bool     `json:"namespaced"`
+	Metrics    []Metric `json:"metrics"`
+}
+
+// Metric declares a single metric family of a custom resource.
+type Metric struct {
+	Name string `json:"name"`
+	Help string `json:"help"`
+	// Type is either gauge or counter, defaults to gauge.
+	Type string `json:"type"`
+	// ValuePath is a JSONPath expression selecting the value of the metric.
+	// If empty, the metric has the value 1. Numbers, booleans, numeric
+	// strings, "True"/"False" condition statuses and RFC 3339 timestamps
+	// are supported. If the path does not exist, no metric is generated.
+	ValuePath string  `json:"valuePath"`
+	Labels    []Label `json:"labels"`
+
+	valuePath *jsonPath
+}
+
+// Label declares a label of a custom resource metric.
+type Label struct {
+	Name string `json:"name"`
+	// Path is a JSONPath expression selecting the label value. If the path
+	// does not exist, the label value is empty.
+	Path string `json:"path"`
+
+	path *jsonPath
+}
+
+// LoadConfig reads and validates the custom resource config file at the
+// given path.
+func LoadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read custom resource config: %v", err)
+	}
+
+	return ParseConfig(data)
+}
+
+// ParseConfig parses and validates a YAML or JSON custom resource config.
+func ParseConfig(data []byte) (*Config, error) {
+	c := &Config{}
+
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("failed to parse custom resource config: %v", err)
+	}
+
+	// Metric names are derived from the kind only, so two resources sharing
+	// a kind would expose the same metric families.
+	owners := map[string]string{}
+	for i := range c.Resources {
+		r := &c.Resources[i]
+		if err := r.init(); err != nil {
+			return nil, err
+		}
+
+		for _, m := range r.Metrics {
+			name := r.MetricName(m)
+			if owner, ok := owners[name]; ok {
+				return nil, fmt.Errorf("custom resource %s: metric %s is already exposed by custom resource %s", r, name, owner)
+			}
+			owners[name] = r.String()
+		}
+	}
+
+	return c, nil
+}
+
+func (r *Resource) init() error {
+	if r.Version == "" || r.Kind == "" || r.Resource == "" {
+		return fmt.Errorf("custom resource %q: version, kind and resource are required", r.Kind)
+	}
+
+	// The object name label is named after the kind, which must not clash
+	// with the namespace label of namespaced resources.
+	if r.Namespaced && strings.ToLower(r.Kind) == "namespace" {
+		return fmt.Errorf("custom resource %s: namespaced resources of kind %s are not supported, the object name label would collide with the namespace label", r.Kind, r.Kind)
+	}
+
+	if len(r.Metrics) == 0 {
+		return fmt.Errorf("custom resource %s: no metrics configured", r.Kind)
+	}
+
+	names := map[string]struct{}{}
+
+	for i := range r.Metrics {
+		m := &r.Metrics[i]
+
+		if !metricNameRE.MatchString(m.Name) {
+			return fmt.Errorf("custom resource %s: invalid metric name %q", r.Kind, m.Name)
+		}
+		if _, ok := names[m.Name]; ok {
+			return fmt.Errorf("custom resource %s: duplicate metric name %q", r.Kind, m.Name)
+		}
+		names[m.Name] = struct{}{}
+
+		switch m.Type {
+		case "":
+			m.Type = metricTypeGauge
+		case metricTypeGauge, metricTypeCounter:
+		default:
+			return fmt.Errorf("custom resource %s: metric %s has invalid type %q", r.Kind, m.Name, m.Type)
+		}
+
+		if m.ValuePath != "" {
+			p, err := newJSONPath(m.ValuePath)
+			if err != nil {
+				return fmt.Errorf("custom resource %s: metric %s: %v", r.Kind, m.Name, err)
+			}
+			m.valuePath = p
+		}
+
+		labels := map[string]struct{}{}
+		for _, l := range r.DefaultLabelKeys() {
+			labels[l] = struct{}{}
+		}
+
+		for j := range m.Labels {
+			l := &m.Labels[j]
+
+			if !labelNameRE.MatchString(l.Name) {
+				return fmt.Errorf("custom resource %s: metric %s has invalid label name %q", r.Kind, m.Name, l.Name)
+			}
+			if _, ok := labels[l.Name]; ok {
+				return fmt.Errorf("custom resource %s: metric %s has duplicate label %q", r.Kind, m.Name, l.Name)
+			}
+			labels[l.Name] = struct{}{}
+
+			p, err := newJSONPath(l.Path)
+			if err != nil {
+				return fmt.Errorf("custom resource %s: metric %s: label %s: %v", r.Kind, m.Name, l.Name, err)
+			}
+			l.path = p
+		}
+	}
+
+	return nil
+}
+
+// String returns the group, version and kind of the resource, e.g.
+// "cert-manager.io/v1alpha1, Kind=Certificate".
+func (r *Resource) String() string {
+	if r.Group == "" {
+		return r.Version + ", Kind=" + r.Kind
+	}
+
+	return r.Group + "/" + r.Version + ", Kind=" + r.Kind
+}
+
+// APIPath returns the API path of the resource within the given namespace.
+// The namespace is ignored for cluster scoped resources.
+func (r *Resource) APIPath(namespace string) string {
+	segments := []string{"/apis", r.Group, r.Version}
+	if r.Group == "" {
+		segments = []string{"/api", r.Version}
+	}
+
+	if r.Namespaced && namespace != "" {
+		segments = append(segments, "namespaces", namespace)
+	}
+
+	return strings.Join(append(segments, r.Resource), "/")
+}
+
+// DefaultLabelKeys returns the label keys every metric of the resource
+// starts with: the namespace for namespaced resources and the lower case
+// kind, holding the object name.
+func (r *Resource) DefaultLabelKeys() []string {
+	kind := strings.ToLower(r.Kind)
+
+	if r.Namespaced {
+		return []string{"namespace", kind}
+	}
+
+	return []string{kind}
+}
+
+// MetricName returns the full name of the given metric of the resource, e.g.
+// kube_crd_certificate_ready.
+func (r *Resource) MetricName(m Metric) string {
+	return MetricNamePrefix + strings.ToLower(r.Kind) + "_" + m.Name
+}
+
+// Value returns the value of the metric for the given object. The second
+// return value is false if the value path does not exist on the object.
+func (m *Metric) Value(obj map[string]interface{}) (float64, bool, error) {
+	if m.valuePath == nil {
+		return 1, true, nil
+	}
+
+	results, err := m.valuePath.find(obj)
+	if err != nil {
+		return 0, false, err
+	}
+
+	switch len(results) {
+	case 0:
+		return 0, false, nil
+	case 1:
+		v, err := toFloat64(results[0])
+		return v, err == nil, err
+	default:
+		return 0, false, fmt.Errorf("value path %q of metric %s returned %d values, expected one", m.ValuePath, m.Name, len(results))
+	}
+}
+
+// LabelValues returns the values of the configured labels of the metric for
+// the given object, in the same order as the labels.
+func (m *Metric) LabelValues(obj map[string]interface{}) ([]string, error) {
+	values := make([]string, len(m.Labels))
+
+	for i, l := range m.Labels {
+		results, err := l.path.find(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		strs := make([]string, len(results))
+		for j, r := range results {
+			strs[j] = fmt.Sprint(r.Interface())
+		}
+		values[i] = strings.Join(strs, ",")
+	}
+
+	return values, nil
+}
+
+// LabelKeys returns the names of the configured labels of the metric.
+func (m *Metric) LabelKeys() []string {
+	keys := make([]string, len(m.Labels))
+
+	for i, l := range m.Labels {
+		keys[i] = l.Name
+	}
+
+	return keys
+}
+
+// jsonPath wraps a parsed JSONPath expression. Evaluating a JSONPath
+// expression is not safe for concurrent use, as it keeps state between
+// evaluation steps.
+type jsonPath struct {
+	mutex sync.Mutex
+	path  *jsonpath.JSONPath
+}
+
+func newJSONPath(expression string) (*jsonPath, error) {
+	if !strings.HasPrefix(expression, "{") {
+		expression = "{" + expression + "}"
+	}
+
+	p := jsonpath.New("").AllowMissingKeys(true)
+	if err := p.Parse(expression); err != nil {
+		return nil, fmt.Errorf("failed to parse JSONPath %q: %v", expression, err)
+	}
+
+	return &jsonPath{path: p}, nil
+}
+
+func (p *jsonPath) find(obj map[string]interface{}) ([]reflect.Value, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	results, err := p.path.FindResults(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	values := []reflect.Value{}
+	for _, r := range results {
+		for _, v := range r {
+			if v.Kind() == reflect.Interface {
+				v = v.Elem()
+			}
+			if v.IsValid() {
+				values = append(values, v)
+			}
+		}
+	}
+
+	return values, nil
+}
+
+func toFloat64(v reflect.Value) (float64, error) {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), nil
+	case reflect.Bool:
+		if v.Bool() {
+			return 1, nil
+		}
+		return 0, nil
+	case reflect.String:
+		s := v.String()
+
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f, nil
+		}
+		if b, err := strconv.ParseBool(s); err == nil {
+			if b {
+				return 1, nil
+			}
+			return 0, nil
+		}
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			return float64(t.Unix()), nil
+		}
+
+		return 0, fmt.Errorf("failed to convert %q to a metric value", s)
+	default:
+		return 0, fmt.Errorf("failed to convert value of kind %s to a metric value", v.Kind())
+	}
+}
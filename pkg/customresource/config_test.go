@@ -0,0 +1,279 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package customresource
+
+import (
+	"testing"
+)
+
+const testConfig = `
+resources:
+- group: cert-manager.io
+  version: v1alpha1
+  kind: Certificate
+  resource: certificates
+  namespaced: true
+  metrics:
+  - name: ready
+    help: Whether the certificate is ready.
+    valuePath: '{.status.conditions[?(@.type=="Ready")].status}'
+    labels:
+    - name: issuer
+      path: '{.spec.issuerRef.name}'
+  - name: expiration_timestamp_seconds
+    help: Expiration time of the certificate.
+    valuePath: .status.notAfter
+  - name: renewals_total
+    type: counter
+    valuePath: .status.renewals
+- version: v1
+  kind: Widget
+  resource: widgets
+  metrics:
+  - name: info
+`
+
+func TestParseConfig(t *testing.T) {
+	c, err := ParseConfig([]byte(testConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(c.Resources) != 2 {
+		t.Fatalf("expected 2 resources, got %d", len(c.Resources))
+	}
+
+	certificate := c.Resources[0]
+	if got, want := certificate.MetricName(certificate.Metrics[0]), "kube_crd_certificate_ready"; got != want {
+		t.Errorf("expected metric name %q, got %q", want, got)
+	}
+	if got, want := certificate.Metrics[0].Type, "gauge"; got != want {
+		t.Errorf("expected default type %q, got %q", want, got)
+	}
+	if got, want := certificate.Metrics[2].Type, "counter"; got != want {
+		t.Errorf("expected type %q, got %q", want, got)
+	}
+	if got, want := certificate.APIPath("ns1"), "/apis/cert-manager.io/v1alpha1/namespaces/ns1/certificates"; got != want {
+		t.Errorf("expected API path %q, got %q", want, got)
+	}
+	if got, want := certificate.APIPath(""), "/apis/cert-manager.io/v1alpha1/certificates"; got != want {
+		t.Errorf("expected API path %q, got %q", want, got)
+	}
+
+	widget := c.Resources[1]
+	if got, want := widget.APIPath("ns1"), "/api/v1/widgets"; got != want {
+		t.Errorf("expected API path %q of cluster scoped resource, got %q", want, got)
+	}
+}
+
+func TestParseConfigInvalid(t *testing.T) {
+	tests := []struct {
+		Desc   string
+		Config string
+	}{
+		{
+			Desc:   "invalid YAML",
+			Config: "resources: [",
+		},
+		{
+			Desc: "missing resource",
+			Config: `
+resources:
+- version: v1
+  kind: Widget
+  metrics:
+  - name: info`,
+		},
+		{
+			Desc: "no metrics",
+			Config: `
+resources:
+- version: v1
+  kind: Widget
+  resource: widgets`,
+		},
+		{
+			Desc: "invalid metric name",
+			Config: `
+resources:
+- version: v1
+  kind: Widget
+  resource: widgets
+  metrics:
+  - name: info-total`,
+		},
+		{
+			Desc: "duplicate metric name",
+			Config: `
+resources:
+- version: v1
+  kind: Widget
+  resource: widgets
+  metrics:
+  - name: info
+  - name: info`,
+		},
+		{
+			Desc: "invalid metric type",
+			Config: `
+resources:
+- version: v1
+  kind: Widget
+  resource: widgets
+  metrics:
+  - name: info
+    type: histogram`,
+		},
+		{
+			Desc: "invalid value path",
+			Config: `
+resources:
+- version: v1
+  kind: Widget
+  resource: widgets
+  metrics:
+  - name: info
+    valuePath: '{.status[}'`,
+		},
+		{
+			Desc: "same kind in different groups",
+			Config: `
+resources:
+- group: certmanager.k8s.io
+  version: v1alpha1
+  kind: Certificate
+  resource: certificates
+  metrics:
+  - name: info
+- group: example.com
+  version: v1
+  kind: Certificate
+  resource: certificates
+  metrics:
+  - name: info`,
+		},
+		{
+			Desc: "same resource listed twice",
+			Config: `
+resources:
+- version: v1
+  kind: Widget
+  resource: widgets
+  metrics:
+  - name: info
+- version: v1
+  kind: Widget
+  resource: widgets
+  metrics:
+  - name: info`,
+		},
+		{
+			Desc: "label colliding with default labels",
+			Config: `
+resources:
+- version: v1
+  kind: Widget
+  resource: widgets
+  namespaced: true
+  metrics:
+  - name: info
+    labels:
+    - name: namespace
+      path: .metadata.namespace`,
+		},
+		{
+			Desc: "namespaced kind colliding with the namespace label",
+			Config: `
+resources:
+- group: example.com
+  version: v1
+  kind: Namespace
+  resource: namespaces
+  namespaced: true
+  metrics:
+  - name: info`,
+		},
+	}
+
+	for _, test := range tests {
+		if _, err := ParseConfig([]byte(test.Config)); err == nil {
+			t.Errorf("%s: expected ParseConfig() to fail", test.Desc)
+		}
+	}
+}
+
+func TestMetricValue(t *testing.T) {
+	c, err := ParseConfig([]byte(testConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ready, expiration, renewals := c.Resources[0].Metrics[0], c.Resources[0].Metrics[1], c.Resources[0].Metrics[2]
+	info := c.Resources[1].Metrics[0]
+
+	obj := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"issuerRef": map[string]interface{}{"name": "letsencrypt"},
+		},
+		"status": map[string]interface{}{
+			"notAfter": "2018-10-01T00:00:00Z",
+			"renewals": int64(3),
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Issuing", "status": "False"},
+				map[string]interface{}{"type": "Ready", "status": "True"},
+			},
+		},
+	}
+
+	tests := []struct {
+		Desc   string
+		Metric Metric
+		Obj    map[string]interface{}
+		Value  float64
+		Found  bool
+	}{
+		{"condition status", ready, obj, 1, true},
+		{"timestamp", expiration, obj, 1538352000, true},
+		// Lease renew and acquire times are serialized with microseconds.
+		{"timestamp with fractional seconds", expiration, map[string]interface{}{"status": map[string]interface{}{"notAfter": "2018-10-01T00:00:00.123456Z"}}, 1538352000, true},
+		{"integer", renewals, obj, 3, true},
+		{"missing path", expiration, map[string]interface{}{}, 0, false},
+		{"no value path", info, map[string]interface{}{}, 1, true},
+	}
+
+	for _, test := range tests {
+		v, found, err := test.Metric.Value(test.Obj)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.Desc, err)
+			continue
+		}
+		if v != test.Value || found != test.Found {
+			t.Errorf("%s: expected (%v, %v), got (%v, %v)", test.Desc, test.Value, test.Found, v, found)
+		}
+	}
+
+	labelValues, err := ready.LabelValues(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(labelValues) != 1 || labelValues[0] != "letsencrypt" {
+		t.Errorf("expected label values [letsencrypt], got %v", labelValues)
+	}
+
+	if _, _, err := expiration.Value(map[string]interface{}{"status": map[string]interface{}{"notAfter": "soon"}}); err == nil {
+		t.Error("expected an error for a value that can not be converted")
+	}
+}
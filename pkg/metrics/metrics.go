@@ -17,127 +17,167 @@ limitations under the License.
 package metrics
 
 import (
-	"errors"
 	"fmt"
-	"sort"
+	"strconv"
 	"strings"
+)
 
-	"github.com/prometheus/client_golang/prometheus"
-	dto "github.com/prometheus/client_model/go"
+// MetricType represents the type of a metric family, as exposed in the TYPE
+// line of the Prometheus text exposition format.
+type MetricType string
 
-	"k8s.io/kube-state-metrics/pkg/options"
+const (
+	// MetricTypeGauge represents a metric family of gauges.
+	MetricTypeGauge MetricType = "gauge"
+	// MetricTypeCounter represents a metric family of counters.
+	MetricTypeCounter MetricType = "counter"
 )
 
-// Metric represents a single line entry in the /metrics export format
-type Metric string
+// Metric represents a single time series of a metric family. The metric name
+// is defined by the surrounding Family.
+type Metric struct {
+	LabelKeys   []string
+	LabelValues []string
+	Value       float64
+}
 
-// NewMetric returns a new Metric
-func NewMetric(name string, labelKeys []string, labelValues []string, value float64) (*Metric, error) {
-	if len(labelKeys) != len(labelValues) {
-		return nil, errors.New("expected labelKeys to be of same length as labelValues")
-	}
+// Family represents all time series of a single metric family generated for
+// one Kubernetes object.
+type Family struct {
+	Name    string
+	Metrics []*Metric
+}
 
-	m := ""
+// String returns the given Family in the Prometheus text exposition format,
+// one line per metric.
+func (f Family) String() string {
+	return string(f.AppendTo(make([]byte, 0, f.Len())))
+}
 
-	m = m + name
+// Len returns the exact number of bytes the text exposition format of the
+// Family occupies. It allows callers to allocate a buffer of the right size
+// before calling AppendTo.
+func (f Family) Len() int {
+	l := 0
 
-	m = m + labelsToString(labelKeys, labelValues)
+	for _, m := range f.Metrics {
+		if len(m.LabelKeys) != len(m.LabelValues) {
+			panic(fmt.Sprintf("metric %v: expected labelKeys to be of same length as labelValues", f.Name))
+		}
 
-	m = m + fmt.Sprintf(" %v", value)
+		l += len(f.Name)
 
-	m = m + "\n"
+		if len(m.LabelKeys) > 0 {
+			// Curly braces plus one '=', two '"' and one ',' per label,
+			// minus the trailing comma.
+			l += 2 + 4*len(m.LabelKeys) - 1
+			for i := range m.LabelKeys {
+				l += len(m.LabelKeys[i]) + escapedLen(m.LabelValues[i])
+			}
+		}
 
-	metric := Metric(m)
+		var buf [32]byte
+		// Space and new line character.
+		l += 2 + len(strconv.AppendFloat(buf[:0], m.Value, 'g', -1, 64))
+	}
 
-	return &metric, nil
+	return l
 }
 
-func labelsToString(keys, values []string) string {
-	if len(keys) > 0 {
-		labels := []string{}
-		for i := 0; i < len(keys); i++ {
-			labels = append(
-				labels,
-				fmt.Sprintf(`%s="%s"`, keys[i], escapeString(values[i])),
-			)
+// AppendTo appends the text exposition format of the Family to b, one line
+// per metric with labels sorted by name, and returns the extended buffer.
+func (f Family) AppendTo(b []byte) []byte {
+	for _, m := range f.Metrics {
+		if len(m.LabelKeys) != len(m.LabelValues) {
+			panic(fmt.Sprintf("metric %v: expected labelKeys to be of same length as labelValues", f.Name))
 		}
-		// TODO: Do labels need to be sorted. As of now, this is only needed to
-		// make output deterministic between test runs.
-		sort.Strings(labels)
-		return "{" + strings.Join(labels, ",") + "}"
+
+		b = append(b, f.Name...)
+		b = appendLabels(b, m.LabelKeys, m.LabelValues)
+		b = append(b, ' ')
+		b = strconv.AppendFloat(b, m.Value, 'g', -1, 64)
+		b = append(b, '\n')
 	}
 
-	return ""
+	return b
 }
 
-var (
-	escapeWithDoubleQuote = strings.NewReplacer("\\", `\\`, "\n", `\n`, "\"", `\"`)
-)
-
-// escapeString replaces '\' by '\\', new line character by '\n', and - if
-// includeDoubleQuote is true - '"' by '\"'.
-// TODO: Taken from github.com/prometheus/common/expfmt/text_create.go, should be better referenced?
-func escapeString(v string) string {
-	return escapeWithDoubleQuote.Replace(v)
-}
+func appendLabels(b []byte, keys, values []string) []byte {
+	if len(keys) == 0 {
+		return b
+	}
 
-// MetricFamilyDesc represents the HELP and TYPE string above a metric family list
-type MetricFamilyDesc string
+	// Sort a permutation instead of the labels themselves, as label slices
+	// are shared with the metric family definitions.
+	var buf [16]int
+	order := buf[:0]
+	for i := range keys {
+		order = append(order, i)
+	}
+	// Insertion sort, as metrics have few labels.
+	for i := 1; i < len(order); i++ {
+		for j := i; j > 0 && keys[order[j]] < keys[order[j-1]]; j-- {
+			order[j], order[j-1] = order[j-1], order[j]
+		}
+	}
 
-type gathererFunc func() ([]*dto.MetricFamily, error)
+	b = append(b, '{')
+	for n, i := range order {
+		if n > 0 {
+			b = append(b, ',')
+		}
+		b = append(b, keys[i]...)
+		b = append(b, '=', '"')
+		b = appendEscaped(b, values[i])
+		b = append(b, '"')
+	}
 
-func (f gathererFunc) Gather() ([]*dto.MetricFamily, error) {
-	return f()
+	return append(b, '}')
 }
 
-// FilteredGatherer wraps a prometheus.Gatherer to filter metrics based on a
-// white or blacklist. Whitelist and blacklist are mutually exclusive.
-// TODO: Bring white and blacklisting back
-func FilteredGatherer(r prometheus.Gatherer, whitelist options.MetricSet, blacklist options.MetricSet) prometheus.Gatherer {
-	whitelistEnabled := !whitelist.IsEmpty()
-	blacklistEnabled := !blacklist.IsEmpty()
-
-	if whitelistEnabled {
-		return gathererFunc(func() ([]*dto.MetricFamily, error) {
-			metricFamilies, err := r.Gather()
-			if err != nil {
-				return nil, err
-			}
+var escapeHelp = strings.NewReplacer("\\", `\\`, "\n", `\n`)
 
-			newMetricFamilies := []*dto.MetricFamily{}
-			for _, metricFamily := range metricFamilies {
-				// deferencing this string may be a performance bottleneck
-				name := *metricFamily.Name
-				_, onWhitelist := whitelist[name]
-				if onWhitelist {
-					newMetricFamilies = append(newMetricFamilies, metricFamily)
-				}
-			}
+// escapedLen returns the length of v after replacing '\' by '\\', new line
+// character by '\n' and '"' by '\"'.
+func escapedLen(v string) int {
+	l := len(v)
 
-			return newMetricFamilies, nil
-		})
+	for i := 0; i < len(v); i++ {
+		switch v[i] {
+		case '\\', '\n', '"':
+			l++
+		}
 	}
 
-	if blacklistEnabled {
-		return gathererFunc(func() ([]*dto.MetricFamily, error) {
-			metricFamilies, err := r.Gather()
-			if err != nil {
-				return nil, err
-			}
-
-			newMetricFamilies := []*dto.MetricFamily{}
-			for _, metricFamily := range metricFamilies {
-				name := *metricFamily.Name
-				_, onBlacklist := blacklist[name]
-				if onBlacklist {
-					continue
-				}
-				newMetricFamilies = append(newMetricFamilies, metricFamily)
-			}
+	return l
+}
 
-			return newMetricFamilies, nil
-		})
+// appendEscaped appends v to b, replacing '\' by '\\', new line character by
+// '\n' and '"' by '\"'.
+// TODO: Taken from github.com/prometheus/common/expfmt/text_create.go, should be better referenced?
+func appendEscaped(b []byte, v string) []byte {
+	for i := 0; i < len(v); i++ {
+		switch v[i] {
+		case '\\':
+			b = append(b, '\\', '\\')
+		case '\n':
+			b = append(b, '\\', 'n')
+		case '"':
+			b = append(b, '\\', '"')
+		default:
+			b = append(b, v[i])
+		}
 	}
 
-	return r
+	return b
+}
+
+// MetricFamilyDesc represents the HELP and TYPE string above a metric family list
+type MetricFamilyDesc string
+
+// NewMetricFamilyDesc returns the HELP and TYPE lines of the metric family
+// with the given name, help text and type.
+func NewMetricFamilyDesc(name, help string, t MetricType) MetricFamilyDesc {
+	return MetricFamilyDesc("# HELP " + name + " " + escapeHelp.Replace(help) + "\n" +
+		"# TYPE " + name + " " + string(t) + "\n")
 }
@@ -1,31 +1,94 @@
 package metricsstore
 
 import (
+	"hash/fnv"
+	"io"
 	"sync"
 
 	"k8s.io/kube-state-metrics/pkg/metrics"
 
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
 )
 
 // MetricsStore implements the k8s.io/kubernetes/client-go/tools/cache.Store
 // interface. Instead of storing entire Kubernetes objects, it stores metrics
 // generated based on them.
 type MetricsStore struct {
-	mutex   sync.RWMutex
-	metrics map[string][]*metrics.Metric
+	mutex sync.RWMutex
+	// metrics is a map indexed by Kubernetes object UID, containing the
+	// rendered metric families of the object. The families are in the same
+	// order as the headers.
+	metrics map[types.UID]objectMetrics
+	// headers contains the HELP and TYPE lines of each metric family.
+	headers []string
+
+	generateMetricsFunc func(interface{}) []metrics.Family
+
+	// shard and totalShards determine the objects kept by the store, see
+	// WithSharding. They are guarded by mutex.
+	shard       int32
+	totalShards int
+}
 
-	generateMetricsFunc func(interface{}) []*metrics.Metric
+// objectMetrics holds all metric families of a single Kubernetes object in the
+// text exposition format as one contiguous blob. familyEnds contains the
+// offset in blob at which each metric family ends.
+type objectMetrics struct {
+	blob       []byte
+	familyEnds []int
 }
 
 // NewMetricsStore returns a new MetricsStore
-func NewMetricsStore(generateFunc func(interface{}) []*metrics.Metric) *MetricsStore {
+func NewMetricsStore(headers []string, generateFunc func(interface{}) []metrics.Family) *MetricsStore {
 	return &MetricsStore{
 		generateMetricsFunc: generateFunc,
-		metrics:             map[string][]*metrics.Metric{},
+		headers:             headers,
+		metrics:             map[types.UID]objectMetrics{},
+		totalShards:         1,
+	}
+}
+
+// WithSharding configures the store to only keep objects whose UID hashes to
+// the given shard, out of totalShards. Objects of other shards are neither
+// generated nor stored. The sharding can be changed at any time, in which case
+// the metrics of objects no longer in the shard are removed. Objects which
+// newly belong to the shard are only picked up once they are added again.
+func (s *MetricsStore) WithSharding(shard int32, totalShards int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.shard = shard
+	s.totalShards = totalShards
+
+	for uid := range s.metrics {
+		if !s.isInShard(uid) {
+			delete(s.metrics, uid)
+		}
 	}
 }
 
+// isInShard returns whether the object with the given UID belongs to the
+// shard of the store. The caller has to hold the mutex.
+func (s *MetricsStore) isInShard(uid types.UID) bool {
+	return IsInShard(uid, s.shard, s.totalShards)
+}
+
+// IsInShard returns whether the object with the given UID belongs to the given
+// shard, out of totalShards. All objects belong to the single shard if
+// totalShards is at most 1.
+func IsInShard(uid types.UID, shard int32, totalShards int) bool {
+	if totalShards <= 1 {
+		return true
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(uid))
+
+	return h.Sum64()%uint64(totalShards) == uint64(shard)
+}
+
 // Implementing k8s.io/kubernetes/client-go/tools/cache.Store interface
 
 // TODO: Proper comments on all functions below.
@@ -35,10 +98,38 @@ func (s *MetricsStore) Add(obj interface{}) error {
 		return err
 	}
 
+	s.mutex.RLock()
+	inShard := s.isInShard(o.GetUID())
+	s.mutex.RUnlock()
+	if !inShard {
+		return nil
+	}
+
+	families := s.generateMetricsFunc(obj)
+
+	size := 0
+	for _, f := range families {
+		size += f.Len()
+	}
+
+	m := objectMetrics{
+		blob:       make([]byte, 0, size),
+		familyEnds: make([]int, len(families)),
+	}
+	for i, f := range families {
+		m.blob = f.AppendTo(m.blob)
+		m.familyEnds[i] = len(m.blob)
+	}
+
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	s.metrics[o.GetName()] = s.generateMetricsFunc(obj)
+	// The sharding could have changed while the metrics were generated.
+	if !s.isInShard(o.GetUID()) {
+		return nil
+	}
+
+	s.metrics[o.GetUID()] = m
 
 	return nil
 }
@@ -48,7 +139,13 @@ func (s *MetricsStore) Update(obj interface{}) error {
 	return s.Add(obj)
 }
 
+// Delete removes the metrics of the given object from the store. Besides
+// Kubernetes objects it accepts cache.DeletedFinalStateUnknown tombstones, in
+// which case the last known state of the object is deleted.
 func (s *MetricsStore) Delete(obj interface{}) error {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
 
 	o, err := meta.Accessor(obj)
 	if err != nil {
@@ -58,7 +155,7 @@ func (s *MetricsStore) Delete(obj interface{}) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	delete(s.metrics, o.GetName())
+	delete(s.metrics, o.GetUID())
 
 	return nil
 }
@@ -86,7 +183,7 @@ func (s *MetricsStore) GetByKey(key string) (item interface{}, exists bool, err
 // TODO: What is 'name' for?
 func (s *MetricsStore) Replace(list []interface{}, name string) error {
 	s.mutex.Lock()
-	s.metrics = map[string][]*metrics.Metric{}
+	s.metrics = map[types.UID]objectMetrics{}
 	s.mutex.Unlock()
 
 	for _, o := range list {
@@ -103,15 +200,29 @@ func (s *MetricsStore) Resync() error {
 	return nil
 }
 
-func (s *MetricsStore) GetAll() []*metrics.Metric {
-	m := []*metrics.Metric{}
-
+// WriteAll writes all metrics of the store to the given writer, grouped by
+// metric family. Each family is preceded by its HELP and TYPE lines. The
+// stored blobs are written directly, without copying them.
+func (s *MetricsStore) WriteAll(w io.Writer) error {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
-	for _, metrics := range s.metrics {
-		m = append(m, metrics...)
+	for i, header := range s.headers {
+		if _, err := io.WriteString(w, header); err != nil {
+			return err
+		}
+
+		for _, m := range s.metrics {
+			start := 0
+			if i > 0 {
+				start = m.familyEnds[i-1]
+			}
+
+			if _, err := w.Write(m.blob[start:m.familyEnds[i]]); err != nil {
+				return err
+			}
+		}
 	}
 
-	return m
+	return nil
 }
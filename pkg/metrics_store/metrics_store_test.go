@@ -0,0 +1,120 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricsstore
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kube-state-metrics/pkg/metrics"
+)
+
+func TestWriteAllGroupsByFamily(t *testing.T) {
+	headers := []string{
+		string(metrics.NewMetricFamilyDesc("kube_test_a", "Test a.", metrics.MetricTypeGauge)),
+		string(metrics.NewMetricFamilyDesc("kube_test_b", "Test b.", metrics.MetricTypeCounter)),
+	}
+	genFunc := func(obj interface{}) []metrics.Family {
+		o := obj.(*v1.ConfigMap)
+		return []metrics.Family{
+			{
+				Name: "kube_test_a",
+				Metrics: []*metrics.Metric{
+					{LabelKeys: []string{"name"}, LabelValues: []string{o.Name}, Value: 1},
+				},
+			},
+			{
+				Name: "kube_test_b",
+				Metrics: []*metrics.Metric{
+					{LabelKeys: []string{"name"}, LabelValues: []string{o.Name}, Value: 2},
+				},
+			},
+		}
+	}
+
+	s := NewMetricsStore(headers, genFunc)
+	for _, name := range []string{"one", "two"} {
+		if err := s.Add(&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID(name)}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := s.WriteAll(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+
+	expectedPrefixes := []string{
+		"# HELP kube_test_a Test a.",
+		"# TYPE kube_test_a gauge",
+		"kube_test_a{",
+		"kube_test_a{",
+		"# HELP kube_test_b Test b.",
+		"# TYPE kube_test_b counter",
+		"kube_test_b{",
+		"kube_test_b{",
+	}
+
+	if len(lines) != len(expectedPrefixes) {
+		t.Fatalf("expected %d lines but got %d:\n%s", len(expectedPrefixes), len(lines), strings.Join(lines, "\n"))
+	}
+
+	for i, l := range lines {
+		if !strings.HasPrefix(l, expectedPrefixes[i]) {
+			t.Errorf("expected line %d to start with %q but got %q", i, expectedPrefixes[i], l)
+		}
+	}
+}
+
+func TestAddReshardedDuringGeneration(t *testing.T) {
+	uid := types.UID("")
+	for i := 0; (&MetricsStore{shard: 1, totalShards: 2}).isInShard(uid); i++ {
+		uid = types.UID(fmt.Sprintf("uid-%d", i))
+	}
+
+	var s *MetricsStore
+	genFunc := func(obj interface{}) []metrics.Family {
+		// Move the object out of the shard of the store.
+		s.WithSharding(1, 2)
+		return []metrics.Family{
+			{
+				Name: "kube_test_a",
+				Metrics: []*metrics.Metric{
+					{LabelKeys: []string{"uid"}, LabelValues: []string{string(uid)}, Value: 1},
+				},
+			},
+		}
+	}
+
+	s = NewMetricsStore(nil, genFunc)
+	s.WithSharding(0, 2)
+
+	if err := s.Add(&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{UID: uid}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s.metrics) != 0 {
+		t.Errorf("expected object moved out of the shard not to be stored, got %d objects", len(s.metrics))
+	}
+}
@@ -42,5 +42,31 @@ var (
 		"endpoints":                struct{}{},
 		"secrets":                  struct{}{},
 		"configmaps":               struct{}{},
+		"poddisruptionbudgets":     struct{}{},
+	}
+	// OptInCollectors are available, but only enabled if explicitly
+	// requested by --collectors.
+	OptInCollectors = CollectorSet{
+		"events":                          struct{}{},
+		"roles":                           struct{}{},
+		"clusterroles":                    struct{}{},
+		"rolebindings":                    struct{}{},
+		"clusterrolebindings":             struct{}{},
+		"ingresses":                       struct{}{},
+		"storageclasses":                  struct{}{},
+		"volumeattachments":               struct{}{},
+		"certificatesigningrequests":      struct{}{},
+		"networkpolicies":                 struct{}{},
+		"validatingwebhookconfigurations": struct{}{},
+		"mutatingwebhookconfigurations":   struct{}{},
+		"serviceaccounts":                 struct{}{},
+		"leases":                          struct{}{},
 	}
 )
+
+// isAvailableCollector returns whether a collector of the given name exists.
+func isAvailableCollector(c string) bool {
+	_, isDefault := DefaultCollectors[c]
+	_, isOptIn := OptInCollectors[c]
+	return isDefault || isOptIn
+}
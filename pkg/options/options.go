@@ -20,7 +20,10 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
+	// glog registers its flags, which are exposed in AddFlags, on init.
+	_ "github.com/golang/glog"
 	"github.com/spf13/pflag"
 )
 
@@ -39,6 +42,18 @@ type Options struct {
 	Version                              bool
 	DisablePodNonGenericResourceMetrics  bool
 	DisableNodeNonGenericResourceMetrics bool
+	CustomResourceConfig                 string
+	Shard                                int32
+	TotalShards                          int
+	Pod                                  string
+	PodNamespace                         string
+	LabelsAllowList                      LabelsAllowList
+	EventRetention                       time.Duration
+	RestrictNamespacesCollector          bool
+	NamespaceSelector                    string
+	NamespaceDenylist                    NamespaceList
+	LabelSelectors                       LabelSelectors
+	FieldSelectors                       FieldSelectors
 
 	flags *pflag.FlagSet
 }
@@ -48,6 +63,10 @@ func NewOptions() *Options {
 		Collectors:      CollectorSet{},
 		MetricWhitelist: MetricSet{},
 		MetricBlacklist: MetricSet{},
+		LabelsAllowList: LabelsAllowList{},
+		LabelSelectors:  LabelSelectors{},
+		FieldSelectors:  FieldSelectors{},
+		EventRetention:  time.Hour,
 	}
 }
 
@@ -71,13 +90,25 @@ func (o *Options) AddFlags() {
 	o.flags.StringVar(&o.Host, "host", "0.0.0.0", `Host to expose metrics on.`)
 	o.flags.IntVar(&o.TelemetryPort, "telemetry-port", 81, `Port to expose kube-state-metrics self metrics on.`)
 	o.flags.StringVar(&o.TelemetryHost, "telemetry-host", "0.0.0.0", `Host to expose kube-state-metrics self metrics on.`)
-	o.flags.Var(&o.Collectors, "collectors", fmt.Sprintf("Comma-separated list of collectors to be enabled. Defaults to %q", &DefaultCollectors))
+	o.flags.Var(&o.Collectors, "collectors", fmt.Sprintf("Comma-separated list of collectors to be enabled. Defaults to %q. Opt-in collectors %q are only enabled if listed.", &DefaultCollectors, &OptInCollectors))
 	o.flags.Var(&o.Namespaces, "namespace", fmt.Sprintf("Comma-separated list of namespaces to be enabled. Defaults to %q", &DefaultNamespaces))
-	o.flags.Var(&o.MetricWhitelist, "metric-whitelist", "Comma-separated list of metrics to be exposed. The whitelist and blacklist are mutually exclusive.")
-	o.flags.Var(&o.MetricBlacklist, "metric-blacklist", "Comma-separated list of metrics not to be enabled. The whitelist and blacklist are mutually exclusive.")
+	o.flags.Var(&o.MetricWhitelist, "metric-whitelist", "Comma-separated list of metrics to be exposed. Entries are regular expressions matching the whole metric name. The whitelist and blacklist are mutually exclusive.")
+	o.flags.Var(&o.MetricBlacklist, "metric-blacklist", "Comma-separated list of metrics not to be enabled. Entries are regular expressions matching the whole metric name. The whitelist and blacklist are mutually exclusive.")
 	o.flags.BoolVarP(&o.Version, "version", "", false, "kube-state-metrics build version information")
 	o.flags.BoolVarP(&o.DisablePodNonGenericResourceMetrics, "disable-pod-non-generic-resource-metrics", "", false, "Disable pod non generic resource request and limit metrics")
 	o.flags.BoolVarP(&o.DisableNodeNonGenericResourceMetrics, "disable-node-non-generic-resource-metrics", "", false, "Disable node non generic resource request and limit metrics")
+	o.flags.StringVar(&o.CustomResourceConfig, "custom-resource-config", "", "Path to a YAML file declaring custom resources and the metrics to expose for them")
+	o.flags.Int32Var(&o.Shard, "shard", 0, "Zero indexed shard of this instance, out of --total-shards. Only objects whose UID hashes to the shard are exposed.")
+	o.flags.IntVar(&o.TotalShards, "total-shards", 1, "The total number of shards. Sharding is disabled when total shards is set to 1.")
+	o.flags.Var(&o.LabelsAllowList, "labels-allowlist", "Comma-separated list of resources and the Kubernetes label and annotation keys to expose for them, e.g. 'pods=[app,team],namespaces=[*]'. '*' exposes all keys. Resources not listed expose all labels and, except for namespaces, no annotations.")
+	o.flags.DurationVar(&o.EventRetention, "event-retention", time.Hour, "How long the events collector keeps exposing a series after the last increment of its counter.")
+	o.flags.StringVar(&o.NamespaceSelector, "namespace-selector", "", "Label selector of the namespaces to collect objects of namespaced kinds from, e.g. 'monitoring=enabled'. Namespaces are picked up and dropped as their labels change. Mutually exclusive with --namespace.")
+	o.flags.Var(&o.NamespaceDenylist, "namespace-denylist", "Comma-separated list of namespaces whose objects are not exposed, e.g. 'kube-system,ci-1'. Applies on top of --namespace and --namespace-selector.")
+	o.flags.BoolVar(&o.RestrictNamespacesCollector, "restrict-namespaces-collector", false, "Only expose the namespaces listed in --namespace, or matching --namespace-selector, and not listed in --namespace-denylist, in the namespaces collector, instead of all namespaces of the cluster.")
+	o.flags.Var(&o.LabelSelectors, "label-selectors", "Comma-separated list of resources and the label selector to list and watch their objects with, e.g. 'pods=[tier=frontend],deployments=[app in (web,api)]'. Objects not matching the selector are never fetched from the API server.")
+	o.flags.Var(&o.FieldSelectors, "field-selectors", "Comma-separated list of resources and the field selector to list and watch their objects with, e.g. 'secrets=[type=kubernetes.io/tls],pods=[status.phase!=Succeeded]'. The API server only supports a few fields per resource.")
+	o.flags.StringVar(&o.Pod, "pod", "", "Name of the kube-state-metrics pod. If run as part of a StatefulSet, setting --pod and --pod-namespace derives the shard from the pod ordinal and the total shards from the StatefulSet replicas, instead of --shard and --total-shards.")
+	o.flags.StringVar(&o.PodNamespace, "pod-namespace", "", "Namespace of the kube-state-metrics pod, see --pod.")
 }
 
 func (o *Options) Parse() error {
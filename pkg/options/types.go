@@ -23,6 +23,8 @@ import (
 	"fmt"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 type MetricSet map[string]struct{}
@@ -77,8 +79,7 @@ func (c *CollectorSet) Set(value string) error {
 	for _, col := range cols {
 		col = strings.TrimSpace(col)
 		if len(col) != 0 {
-			_, ok := DefaultCollectors[col]
-			if !ok {
+			if !isAvailableCollector(col) {
 				return fmt.Errorf("collector \"%s\" does not exist", col)
 			}
 			s[col] = struct{}{}
@@ -127,3 +128,150 @@ func (n *NamespaceList) Set(value string) error {
 func (n *NamespaceList) Type() string {
 	return "string"
 }
+
+// LabelsAllowList maps resources to the Kubernetes label and annotation keys
+// exported for them, e.g. "pods=[app,team],namespaces=[*]".
+type LabelsAllowList map[string][]string
+
+func (l *LabelsAllowList) String() string {
+	s := *l
+	resources := make([]string, 0, len(s))
+	for resource := range s {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+
+	entries := make([]string, len(resources))
+	for i, resource := range resources {
+		entries[i] = fmt.Sprintf("%s=[%s]", resource, strings.Join(s[resource], ","))
+	}
+	return strings.Join(entries, ",")
+}
+
+func (l *LabelsAllowList) Set(value string) error {
+	s := *l
+	value = strings.TrimSpace(value)
+	for len(value) != 0 {
+		i := strings.Index(value, "=[")
+		j := strings.Index(value, "]")
+		if i < 0 || j < i {
+			return fmt.Errorf("invalid labels allowlist entry %q, expected <resource>=[<key>,...]", value)
+		}
+
+		resource := strings.TrimSpace(value[:i])
+		if !isAvailableCollector(resource) {
+			return fmt.Errorf("collector \"%s\" does not exist", resource)
+		}
+
+		keys := s[resource]
+		if keys == nil {
+			keys = []string{}
+		}
+		for _, key := range strings.Split(value[i+2:j], ",") {
+			key = strings.TrimSpace(key)
+			if len(key) != 0 {
+				keys = append(keys, key)
+			}
+		}
+		s[resource] = keys
+
+		value = strings.TrimSpace(value[j+1:])
+		if len(value) != 0 {
+			if value[0] != ',' {
+				return fmt.Errorf("invalid labels allowlist, expected ',' before %q", value)
+			}
+			value = strings.TrimSpace(value[1:])
+		}
+	}
+	return nil
+}
+
+func (l *LabelsAllowList) Type() string {
+	return "string"
+}
+
+// LabelSelectors maps resources to the label selector their objects are listed
+// and watched with, e.g. "pods=[tier=frontend],secrets=[app in (a,b)]".
+type LabelSelectors map[string]string
+
+func (l *LabelSelectors) String() string {
+	return resourceSelectorsString(*l)
+}
+
+func (l *LabelSelectors) Set(value string) error {
+	return setResourceSelectors(*l, value, "label", func(selector string) error {
+		_, err := labels.Parse(selector)
+		return err
+	})
+}
+
+func (l *LabelSelectors) Type() string {
+	return "string"
+}
+
+// FieldSelectors maps resources to the field selector their objects are listed
+// and watched with, e.g. "secrets=[type=kubernetes.io/tls]".
+type FieldSelectors map[string]string
+
+func (f *FieldSelectors) String() string {
+	return resourceSelectorsString(*f)
+}
+
+func (f *FieldSelectors) Set(value string) error {
+	return setResourceSelectors(*f, value, "field", func(selector string) error {
+		_, err := fields.ParseSelector(selector)
+		return err
+	})
+}
+
+func (f *FieldSelectors) Type() string {
+	return "string"
+}
+
+func resourceSelectorsString(s map[string]string) string {
+	resources := make([]string, 0, len(s))
+	for resource := range s {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+
+	entries := make([]string, len(resources))
+	for i, resource := range resources {
+		entries[i] = fmt.Sprintf("%s=[%s]", resource, s[resource])
+	}
+	return strings.Join(entries, ",")
+}
+
+// setResourceSelectors parses entries of the form <resource>=[<selector>]
+// into s, rejecting unknown resources and selectors the given parse function
+// fails on.
+func setResourceSelectors(s map[string]string, value, kind string, parse func(string) error) error {
+	value = strings.TrimSpace(value)
+	for len(value) != 0 {
+		i := strings.Index(value, "=[")
+		j := strings.Index(value, "]")
+		if i < 0 || j < i {
+			return fmt.Errorf("invalid %s selectors entry %q, expected <resource>=[<selector>]", kind, value)
+		}
+
+		resource := strings.TrimSpace(value[:i])
+		if !isAvailableCollector(resource) {
+			return fmt.Errorf("collector \"%s\" does not exist", resource)
+		}
+
+		selector := strings.TrimSpace(value[i+2 : j])
+		if err := parse(selector); err != nil {
+			return fmt.Errorf("invalid %s selector for %s: %v", kind, resource, err)
+		}
+		s[resource] = selector
+
+		value = strings.TrimSpace(value[j+1:])
+		if len(value) != 0 {
+			if value[0] != ',' {
+				return fmt.Errorf("invalid %s selectors, expected ',' before %q", kind, value)
+			}
+			value = strings.TrimSpace(value[1:])
+		}
+	}
+	return nil
+}
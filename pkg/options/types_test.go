@@ -45,6 +45,15 @@ func TestCollectorSetSet(t *testing.T) {
 			}),
 			WantedError: false,
 		},
+		{
+			Desc:  "opt-in collectors",
+			Value: "pods,ingresses",
+			Wanted: CollectorSet(map[string]struct{}{
+				"pods":      {},
+				"ingresses": {},
+			}),
+			WantedError: false,
+		},
 		{
 			Desc:        "none exist collectors",
 			Value:       "none-exists",
@@ -61,3 +70,150 @@ func TestCollectorSetSet(t *testing.T) {
 		}
 	}
 }
+
+func TestLabelsAllowListSet(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Value       string
+		Wanted      LabelsAllowList
+		WantedError bool
+	}{
+		{
+			Desc:   "empty allowlist",
+			Value:  "",
+			Wanted: LabelsAllowList{},
+		},
+		{
+			Desc:  "normal allowlist",
+			Value: "pods=[app,team], namespaces=[*]",
+			Wanted: LabelsAllowList{
+				"pods":       {"app", "team"},
+				"namespaces": {"*"},
+			},
+		},
+		{
+			Desc:  "no keys",
+			Value: "nodes=[]",
+			Wanted: LabelsAllowList{
+				"nodes": {},
+			},
+		},
+		{
+			Desc:        "none exist collector",
+			Value:       "none-exists=[app]",
+			Wanted:      LabelsAllowList{},
+			WantedError: true,
+		},
+		{
+			Desc:        "missing brackets",
+			Value:       "pods=app",
+			Wanted:      LabelsAllowList{},
+			WantedError: true,
+		},
+		{
+			Desc:  "missing separator",
+			Value: "pods=[app]nodes=[*]",
+			Wanted: LabelsAllowList{
+				"pods": {"app"},
+			},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		l := &LabelsAllowList{}
+		gotError := l.Set(test.Value)
+		if !(((gotError == nil && !test.WantedError) || (gotError != nil && test.WantedError)) && reflect.DeepEqual(*l, test.Wanted)) {
+			t.Errorf("Test error for Desc: %s. Want: %+v. Got: %+v. Wanted Error: %v, Got Error: %v", test.Desc, test.Wanted, *l, test.WantedError, gotError)
+		}
+	}
+}
+
+func TestLabelSelectorsSet(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Value       string
+		Wanted      LabelSelectors
+		WantedError bool
+	}{
+		{
+			Desc:   "empty selectors",
+			Value:  "",
+			Wanted: LabelSelectors{},
+		},
+		{
+			Desc:  "normal selectors",
+			Value: "pods=[tier=frontend,app!=db], deployments=[app in (web,api)]",
+			Wanted: LabelSelectors{
+				"pods":        "tier=frontend,app!=db",
+				"deployments": "app in (web,api)",
+			},
+		},
+		{
+			Desc:        "invalid selector",
+			Value:       "pods=[tier in (frontend]",
+			Wanted:      LabelSelectors{},
+			WantedError: true,
+		},
+		{
+			Desc:        "none exist collector",
+			Value:       "none-exists=[tier=frontend]",
+			Wanted:      LabelSelectors{},
+			WantedError: true,
+		},
+		{
+			Desc:        "missing brackets",
+			Value:       "pods=tier",
+			Wanted:      LabelSelectors{},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		l := &LabelSelectors{}
+		gotError := l.Set(test.Value)
+		if !(((gotError == nil && !test.WantedError) || (gotError != nil && test.WantedError)) && reflect.DeepEqual(*l, test.Wanted)) {
+			t.Errorf("Test error for Desc: %s. Want: %+v. Got: %+v. Wanted Error: %v, Got Error: %v", test.Desc, test.Wanted, *l, test.WantedError, gotError)
+		}
+	}
+}
+
+func TestFieldSelectorsSet(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Value       string
+		Wanted      FieldSelectors
+		WantedError bool
+	}{
+		{
+			Desc:  "normal selectors",
+			Value: "secrets=[type=kubernetes.io/tls],pods=[status.phase!=Succeeded,spec.nodeName=node-1]",
+			Wanted: FieldSelectors{
+				"secrets": "type=kubernetes.io/tls",
+				"pods":    "status.phase!=Succeeded,spec.nodeName=node-1",
+			},
+		},
+		{
+			Desc:        "invalid selector",
+			Value:       "pods=[status.phase]",
+			Wanted:      FieldSelectors{},
+			WantedError: true,
+		},
+		{
+			Desc:  "missing separator",
+			Value: "pods=[status.phase=Running]secrets=[type=Opaque]",
+			Wanted: FieldSelectors{
+				"pods": "status.phase=Running",
+			},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		f := &FieldSelectors{}
+		gotError := f.Set(test.Value)
+		if !(((gotError == nil && !test.WantedError) || (gotError != nil && test.WantedError)) && reflect.DeepEqual(*f, test.Wanted)) {
+			t.Errorf("Test error for Desc: %s. Want: %+v. Got: %+v. Wanted Error: %v, Got Error: %v", test.Desc, test.Wanted, *f, test.WantedError, gotError)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sharding determines the shard of a kube-state-metrics instance
+// automatically.
+package sharding
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+	"golang.org/x/net/context"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// StatefulSetSharding derives the shard of a kube-state-metrics pod run as
+// part of a StatefulSet. The shard is the ordinal of the pod and the total
+// number of shards the number of replicas of the StatefulSet.
+type StatefulSetSharding struct {
+	kubeClient  clientset.Interface
+	namespace   string
+	statefulSet string
+	ordinal     int32
+}
+
+// NewStatefulSetSharding returns the sharding of the given pod, whose name
+// has to follow the StatefulSet naming scheme of <statefulset>-<ordinal>.
+func NewStatefulSetSharding(kubeClient clientset.Interface, namespace, pod string) (*StatefulSetSharding, error) {
+	i := strings.LastIndex(pod, "-")
+	if i <= 0 {
+		return nil, fmt.Errorf("pod name %q is not of the form <statefulset>-<ordinal>", pod)
+	}
+
+	ordinal, err := strconv.ParseInt(pod[i+1:], 10, 32)
+	if err != nil || ordinal < 0 {
+		return nil, fmt.Errorf("pod name %q does not end with a StatefulSet ordinal", pod)
+	}
+
+	return &StatefulSetSharding{
+		kubeClient:  kubeClient,
+		namespace:   namespace,
+		statefulSet: pod[:i],
+		ordinal:     int32(ordinal),
+	}, nil
+}
+
+// Shard returns the shard of the pod, which is its ordinal.
+func (s *StatefulSetSharding) Shard() int32 {
+	return s.ordinal
+}
+
+// TotalShards returns the current number of replicas of the StatefulSet.
+func (s *StatefulSetSharding) TotalShards() (int, error) {
+	statefulSet, err := s.kubeClient.AppsV1().StatefulSets(s.namespace).Get(s.statefulSet, metav1.GetOptions{})
+	if err != nil {
+		return 0, err
+	}
+
+	return s.totalShards(statefulSet)
+}
+
+// Watch watches the StatefulSet until the given context is done. Whenever its
+// number of replicas differs from totalShards, onChange is called with the
+// shard of the pod and the new number of replicas.
+func (s *StatefulSetSharding) Watch(ctx context.Context, totalShards int, onChange func(shard int32, totalShards int)) {
+	update := func(obj interface{}) {
+		statefulSet, ok := obj.(*appsv1.StatefulSet)
+		if !ok || statefulSet.Name != s.statefulSet {
+			return
+		}
+
+		newTotalShards, err := s.totalShards(statefulSet)
+		if err != nil {
+			glog.Warningf("Keeping %d total shards: %v", totalShards, err)
+			return
+		}
+		if newTotalShards == totalShards {
+			return
+		}
+
+		glog.Infof("StatefulSet %s/%s scaled from %d to %d replicas, resharding", s.namespace, s.statefulSet, totalShards, newTotalShards)
+		totalShards = newTotalShards
+		onChange(s.ordinal, totalShards)
+	}
+
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", s.statefulSet).String()
+	lw := &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			opts.FieldSelector = fieldSelector
+			return s.kubeClient.AppsV1().StatefulSets(s.namespace).List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			opts.FieldSelector = fieldSelector
+			return s.kubeClient.AppsV1().StatefulSets(s.namespace).Watch(opts)
+		},
+	}
+
+	_, controller := cache.NewInformer(lw, &appsv1.StatefulSet{}, 0, cache.ResourceEventHandlerFuncs{
+		AddFunc:    update,
+		UpdateFunc: func(_, obj interface{}) { update(obj) },
+	})
+	controller.Run(ctx.Done())
+}
+
+// totalShards returns the number of replicas of the given StatefulSet. A pod
+// whose ordinal is not below the number of replicas is about to be removed, in
+// which case there is no valid sharding.
+func (s *StatefulSetSharding) totalShards(statefulSet *appsv1.StatefulSet) (int, error) {
+	replicas := 1
+	if statefulSet.Spec.Replicas != nil {
+		replicas = int(*statefulSet.Spec.Replicas)
+	}
+
+	if int(s.ordinal) >= replicas {
+		return 0, fmt.Errorf("ordinal %d of pod is not below the %d replicas of StatefulSet %s/%s", s.ordinal, replicas, s.namespace, s.statefulSet)
+	}
+
+	return replicas, nil
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNewStatefulSetSharding(t *testing.T) {
+	cases := []struct {
+		pod         string
+		statefulSet string
+		shard       int32
+		wantErr     bool
+	}{
+		{pod: "kube-state-metrics-0", statefulSet: "kube-state-metrics", shard: 0},
+		{pod: "kube-state-metrics-12", statefulSet: "kube-state-metrics", shard: 12},
+		{pod: "ksm-3", statefulSet: "ksm", shard: 3},
+		{pod: "kube-state-metrics", wantErr: true},
+		{pod: "kube-state-metrics-abc", wantErr: true},
+		{pod: "-1", wantErr: true},
+		{pod: "0", wantErr: true},
+	}
+
+	for _, c := range cases {
+		s, err := NewStatefulSetSharding(fake.NewSimpleClientset(), "default", c.pod)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected error", c.pod)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.pod, err)
+			continue
+		}
+
+		if s.statefulSet != c.statefulSet {
+			t.Errorf("%s: expected StatefulSet %q, got %q", c.pod, c.statefulSet, s.statefulSet)
+		}
+		if s.Shard() != c.shard {
+			t.Errorf("%s: expected shard %d, got %d", c.pod, c.shard, s.Shard())
+		}
+	}
+}
+
+func TestTotalShards(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset(newStatefulSet("ksm", 2))
+
+	for _, c := range []struct {
+		pod     string
+		want    int
+		wantErr bool
+	}{
+		{pod: "ksm-0", want: 2},
+		{pod: "ksm-1", want: 2},
+		{pod: "ksm-2", wantErr: true},
+		{pod: "other-0", wantErr: true},
+	} {
+		s, err := NewStatefulSetSharding(kubeClient, "monitoring", c.pod)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := s.TotalShards()
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected error", c.pod)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.pod, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("%s: expected %d total shards, got %d", c.pod, c.want, got)
+		}
+	}
+}
+
+func TestWatchScale(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset(newStatefulSet("ksm", 2), newStatefulSet("other", 5))
+
+	s, err := NewStatefulSetSharding(kubeClient, "monitoring", "ksm-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type sharding struct {
+		shard       int32
+		totalShards int
+	}
+	changes := make(chan sharding, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Watch(ctx, 2, func(shard int32, totalShards int) {
+		changes <- sharding{shard, totalShards}
+	})
+
+	// Neither an unrelated StatefulSet nor scaling below the ordinal of the
+	// pod reshards.
+	for _, ss := range []*appsv1.StatefulSet{newStatefulSet("other", 3), newStatefulSet("ksm", 1), newStatefulSet("ksm", 3)} {
+		if _, err := kubeClient.AppsV1().StatefulSets("monitoring").Update(ss); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	select {
+	case got := <-changes:
+		if want := (sharding{1, 3}); got != want {
+			t.Fatalf("expected sharding %v, got %v", want, got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for resharding")
+	}
+
+	select {
+	case got := <-changes:
+		t.Fatalf("expected a single resharding, got another one to %v", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func newStatefulSet(name string, replicas int32) *appsv1.StatefulSet {
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "monitoring",
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+		},
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package whiteblacklist
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// WhiteBlackList encapsulates the logic needed to filter metric families
+// based on a whitelist or a blacklist. Whitelist and blacklist are mutually
+// exclusive. Every entry is treated as a regular expression which has to
+// match the whole metric family name.
+type WhiteBlackList struct {
+	list        []*regexp.Regexp
+	rawList     []string
+	isWhiteList bool
+}
+
+// New constructs a new WhiteBlackList based on a white- and a blacklist. Only
+// one of them can be not empty. If both are empty, nothing is filtered.
+func New(w, b map[string]struct{}) (*WhiteBlackList, error) {
+	if len(w) != 0 && len(b) != 0 {
+		return nil, errors.New(
+			"whitelist and blacklist are both set, they are mutually exclusive, only one of them can be set",
+		)
+	}
+
+	var (
+		entries     map[string]struct{}
+		isWhiteList bool
+	)
+
+	// Default to blacklisting, so an empty list filters nothing.
+	if len(w) != 0 {
+		entries = w
+		isWhiteList = true
+	} else {
+		entries = b
+	}
+
+	l := &WhiteBlackList{isWhiteList: isWhiteList}
+
+	for entry := range entries {
+		r, err := regexp.Compile("^(?:" + entry + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse metric %q as regular expression: %v", entry, err)
+		}
+		l.list = append(l.list, r)
+		l.rawList = append(l.rawList, entry)
+	}
+
+	sort.Strings(l.rawList)
+
+	return l, nil
+}
+
+// IsIncluded returns if the given metric family name is included, meaning
+// the metric family should be generated and exposed.
+func (l *WhiteBlackList) IsIncluded(item string) bool {
+	matched := l.matches(item)
+
+	if l.isWhiteList {
+		return matched
+	}
+
+	return !matched
+}
+
+// IsExcluded returns if the given metric family name is excluded, meaning
+// the metric family should neither be generated nor exposed.
+func (l *WhiteBlackList) IsExcluded(item string) bool {
+	return !l.IsIncluded(item)
+}
+
+// Status returns a human readable description of the configured filtering.
+func (l *WhiteBlackList) Status() string {
+	items := strings.Join(l.rawList, ", ")
+
+	if l.isWhiteList {
+		return "whitelisting the following items: " + items
+	}
+
+	return "blacklisting the following items: " + items
+}
+
+func (l *WhiteBlackList) matches(item string) bool {
+	for _, r := range l.list {
+		if r.MatchString(item) {
+			return true
+		}
+	}
+
+	return false
+}
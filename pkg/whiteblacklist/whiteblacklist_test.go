@@ -0,0 +1,116 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package whiteblacklist
+
+import (
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("fails with both whitelist and blacklist set", func(t *testing.T) {
+		_, err := New(map[string]struct{}{"metric_a": {}}, map[string]struct{}{"metric_b": {}})
+		if err == nil {
+			t.Fatal("expected New() to fail with whitelist and blacklist set")
+		}
+	})
+
+	t.Run("fails with invalid regular expression", func(t *testing.T) {
+		_, err := New(map[string]struct{}{"metric_(a": {}}, map[string]struct{}{})
+		if err == nil {
+			t.Fatal("expected New() to fail with invalid regular expression")
+		}
+	})
+
+	t.Run("defaults to blacklisting nothing", func(t *testing.T) {
+		l, err := New(map[string]struct{}{}, map[string]struct{}{})
+		if err != nil {
+			t.Fatal("expected New() to not fail")
+		}
+
+		if l.IsExcluded("metric_a") {
+			t.Fatal("expected empty list to not exclude anything")
+		}
+	})
+}
+
+func TestIsIncluded(t *testing.T) {
+	tests := []struct {
+		Desc      string
+		Whitelist map[string]struct{}
+		Blacklist map[string]struct{}
+		Item      string
+		Included  bool
+	}{
+		{
+			Desc:      "whitelisted item",
+			Whitelist: map[string]struct{}{"kube_pod_info": {}},
+			Item:      "kube_pod_info",
+			Included:  true,
+		},
+		{
+			Desc:      "item not on whitelist",
+			Whitelist: map[string]struct{}{"kube_pod_info": {}},
+			Item:      "kube_pod_created",
+			Included:  false,
+		},
+		{
+			Desc:      "whitelist entry only matches whole name",
+			Whitelist: map[string]struct{}{"kube_pod_info": {}},
+			Item:      "kube_pod_info_extra",
+			Included:  false,
+		},
+		{
+			Desc:      "item matching whitelist regex",
+			Whitelist: map[string]struct{}{"kube_pod_container_.*": {}},
+			Item:      "kube_pod_container_info",
+			Included:  true,
+		},
+		{
+			Desc:      "blacklisted item",
+			Blacklist: map[string]struct{}{"kube_pod_info": {}},
+			Item:      "kube_pod_info",
+			Included:  false,
+		},
+		{
+			Desc:      "item not on blacklist",
+			Blacklist: map[string]struct{}{"kube_pod_info": {}},
+			Item:      "kube_pod_created",
+			Included:  true,
+		},
+		{
+			Desc:      "item matching blacklist regex",
+			Blacklist: map[string]struct{}{"kube_.*_labels": {}},
+			Item:      "kube_deployment_labels",
+			Included:  false,
+		},
+	}
+
+	for _, test := range tests {
+		l, err := New(test.Whitelist, test.Blacklist)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.Desc, err)
+		}
+
+		if got := l.IsIncluded(test.Item); got != test.Included {
+			t.Errorf("%s: expected IsIncluded(%q) to be %v but got %v", test.Desc, test.Item, test.Included, got)
+		}
+
+		if got := l.IsExcluded(test.Item); got == test.Included {
+			t.Errorf("%s: expected IsExcluded(%q) to be %v but got %v", test.Desc, test.Item, !test.Included, got)
+		}
+	}
+}